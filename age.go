@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"time"
+)
+
+// define a custom structure for a document excluded from the validation because of its age
+type ExcludedDocument struct {
+	Path     string
+	Modified time.Time
+}
+
+// check if the document was last modified before the cutoff date of the options
+func (document *Document) isTooOld() bool {
+
+	if options.ModifiedSince.IsZero() {
+		return false
+	}
+
+	return document.Modified.Before(options.ModifiedSince)
+
+}
+
+// update the modification date of the document with the date of its core properties,
+// as the date of the file is changed by copying it (i.e. when moving to a new share)
+func (document *Document) readModificationDate() {
+
+	documentContainer, err := openDocumentContainer(document.Path)
+	if err != nil {
+		return
+	}
+	defer documentContainer.Close()
+
+	for _, file := range documentContainer.File {
+
+		if file.Name != "docProps/core.xml" {
+			continue
+		}
+
+		reader, err := openPart(file)
+		if err != nil {
+			return
+		}
+		defer reader.Close()
+
+		modified, err := decodeModificationDate(reader)
+		if err == nil && modified.IsZero() == false {
+			document.Modified = modified
+		}
+
+		return
+
+	}
+
+}
+
+// decode the dcterms:modified element of the core properties
+func decodeModificationDate(reader io.Reader) (time.Time, error) {
+
+	decoder := newTolerantDecoder(reader)
+
+	// only the text of the modified element is of interest
+	isModified := false
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return time.Time{}, nil
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+			isModified = element.Name.Local == "modified"
+
+		case xml.EndElement:
+			isModified = false
+
+		case xml.CharData:
+			if isModified {
+				return time.Parse(time.RFC3339, strings.TrimSpace(string(element)))
+			}
+
+		}
+
+	}
+
+}
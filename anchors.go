@@ -0,0 +1,234 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// hyperlinks to bookmarks of the same document (word) or to other sheets and
+// named ranges of the same workbook (excel) do not have a target
+const categoryAnchor = "anchor"
+
+// define the reason for anchors that cannot be found in the document
+const reasonMissingAnchor = "the bookmark does not exist in the document"
+
+// word always accepts the top of the document as anchor, even without bookmark
+const anchorTop = "_top"
+
+// extract all hyperlinks of the given part jumping to an anchor within the same
+// document, i.e. <w:hyperlink w:anchor="Summary"> in word and <hyperlink ref="A1"
+// location="Sheet2!A1"> in excel (links with a relationship are read from there)
+func readAnchorHyperlinks(file *zip.File) []Hyperlink {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the anchors of " + file.Name)
+		return []Hyperlink{}
+	}
+	defer fileContentReader.Close()
+
+	links, err := decodeAnchorHyperlinks(fileContentReader)
+	if err != nil {
+		log.Println("ERROR: could not read the anchors of " + file.Name)
+	}
+
+	return links
+
+}
+
+// decode the anchor hyperlinks of a part together with the text of the link
+func decodeAnchorHyperlinks(reader io.Reader) ([]Hyperlink, error) {
+
+	links := []Hyperlink{}
+
+	// the index of the link whose text is currently read (-1 if none)
+	current := -1
+	isText := false
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return links, nil
+		}
+		if err != nil {
+			return links, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+
+			if element.Name.Local == "t" {
+				isText = true
+			}
+
+			if element.Name.Local != "hyperlink" || relationshipIdAttribute(element) != "" {
+				continue
+			}
+
+			// word stores the bookmark as anchor, excel the location as reference
+			anchor := attributeValue(element, "anchor")
+			if anchor == "" {
+				anchor = attributeValue(element, "location")
+			}
+
+			if anchor == "" {
+				continue
+			}
+
+			links = append(links, Hyperlink{
+				Url:      "#" + anchor,
+				Category: categoryAnchor,
+				Text:     attributeValue(element, "display"),
+				Tooltip:  attributeValue(element, "tooltip"),
+			})
+
+			current = len(links) - 1
+
+		case xml.CharData:
+			if isText && current >= 0 && len(links[current].Text) < maxTextLength {
+				links[current].Text += string(element)
+			}
+
+		case xml.EndElement:
+
+			switch element.Name.Local {
+			case "t":
+				isText = false
+			case "hyperlink":
+				current = -1
+			}
+
+		}
+
+	}
+
+}
+
+// check the anchor hyperlinks against the bookmarks, sheets and named ranges of
+// the document (the package is only read again if there are any anchors)
+func resolveAnchors(links []Hyperlink, files []*zip.File, contentParts map[string]string) {
+
+	hasAnchors := false
+
+	for _, link := range links {
+		if link.Category == categoryAnchor {
+			hasAnchors = true
+			break
+		}
+	}
+
+	if hasAnchors == false {
+		return
+	}
+
+	targets := map[string]bool{}
+	isWorkbook := false
+
+	for _, file := range files {
+
+		name := strings.ToLower(file.Name)
+
+		if path.Base(name) == "workbook.xml" {
+			isWorkbook = true
+		}
+
+		if contentParts[name] == partText || path.Base(name) == "workbook.xml" {
+			readAnchorTargets(file, targets)
+		}
+
+	}
+
+	for index := range links {
+		if links[index].Category == categoryAnchor {
+			links[index].isPartPresent = anchorExists(strings.TrimPrefix(links[index].Url, "#"), targets, isWorkbook)
+		}
+	}
+
+}
+
+// read the names of the bookmarks (word) or of the sheets and named ranges
+// (excel) of the given part (the names are case insensitive)
+func readAnchorTargets(file *zip.File, targets map[string]bool) {
+
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the bookmarks of " + file.Name)
+		return
+	}
+	defer fileContentReader.Close()
+
+	decoder := newTolerantDecoder(fileContentReader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Println("ERROR: could not read the bookmarks of " + file.Name)
+			return
+		}
+
+		element, ok := token.(xml.StartElement)
+		if ok == false {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "bookmarkStart", "sheet", "definedName":
+			if name := attributeValue(element, "name"); name != "" {
+				targets[strings.ToLower(name)] = true
+			}
+		}
+
+	}
+
+}
+
+// check if the anchor refers to a bookmark, a sheet (i.e. 'Sheet 2'!A1), a
+// named range or a cell of the same sheet
+func anchorExists(anchor string, targets map[string]bool, isWorkbook bool) bool {
+
+	anchor = strings.ToLower(anchor)
+
+	if anchor == anchorTop || targets[anchor] {
+		return true
+	}
+
+	if isWorkbook == false {
+		return false
+	}
+
+	if separator := strings.LastIndex(anchor, "!"); separator >= 0 {
+		sheet := strings.Trim(anchor[:separator], "'")
+		return targets[strings.Replace(sheet, "''", "'", -1)]
+	}
+
+	return matchers["cellReference"].MatchString(anchor)
+
+}
+
+// count the hyperlinks jumping to an anchor within the document itself
+func (document *Document) AnchorLinks() int {
+
+	count := 0
+
+	for _, link := range document.Hyperlinks {
+		if link.Category == categoryAnchor {
+			count++
+		}
+	}
+
+	return count
+
+}
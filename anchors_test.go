@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+// check the anchors of word documents and workbooks against their targets
+func TestAnchorExists(t *testing.T) {
+
+	targets := map[string]bool{"summary": true, "sheet 2": true, "totals": true}
+
+	tests := []struct {
+		anchor     string
+		isWorkbook bool
+		exists     bool
+	}{
+		{"Summary", false, true},
+		{"_top", false, true},
+		{"Introduction", false, false},
+		{"B12", false, false},
+		{"B12", true, true},
+		{"$A$1:$C$10", true, true},
+		{"'Sheet 2'!A1", true, true},
+		{"'Sheet 3'!A1", true, false},
+		{"Totals", true, true},
+		{"ABCD1", true, false},
+	}
+
+	for _, test := range tests {
+		if exists := anchorExists(test.anchor, targets, test.isWorkbook); exists != test.exists {
+			t.Errorf("anchorExists(%q, workbook %t) = %t", test.anchor, test.isWorkbook, exists)
+		}
+	}
+
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// define the save api of the wayback machine (the url to archive is appended)
+const (
+	archiveSaveApi  = "https://web.archive.org/save/"
+	archiveSnapshot = "https://web.archive.org"
+)
+
+// the save api only accepts a limited number of anonymous requests per minute,
+// therefore all submissions are sent one after the other
+const archiveInterval = 4 * time.Second
+
+// define a custom structure serializing the submissions to the archive
+type Archiver struct {
+	mutex         sync.Mutex
+	lastSubmitted time.Time
+}
+
+// the archiver used to submit all working links
+var archiver = &Archiver{}
+
+// submit the target of the working external link to the wayback machine and
+// remember the url of the created snapshot
+func (archiver *Archiver) submit(link *Hyperlink) {
+
+	// only working web pages can be archived
+	if link.IsWorking == false || link.IsExternal == false {
+		return
+	}
+
+	lowerUrl := strings.ToLower(link.RequestUrl)
+	if strings.HasPrefix(lowerUrl, "http://") == false && strings.HasPrefix(lowerUrl, "https://") == false {
+		return
+	}
+
+	archiver.mutex.Lock()
+	defer archiver.mutex.Unlock()
+
+	// wait until we are allowed to send the next submission
+	if wait := archiveInterval - time.Since(archiver.lastSubmitted); wait > 0 {
+		time.Sleep(wait)
+	}
+	archiver.lastSubmitted = time.Now()
+
+	response, err := checker.Check(archiveSaveApi + link.RequestUrl)
+	if err != nil || response.StatusCode >= 400 {
+		log.Println("ERROR: could not archive " + redactUrl(link.RequestUrl))
+		return
+	}
+
+	// the location of the snapshot is returned as relative path
+	location := response.Header.Get("Content-Location")
+	if location == "" {
+		return
+	}
+
+	if strings.HasPrefix(location, "/") {
+		location = archiveSnapshot + location
+	}
+
+	link.ArchiveUrl = location
+
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// linkedin and some publishers answer automated requests with this status code
+const statusBotBlocked = 999
+
+// define the treatments of responses blocking automated requests (i.e. challenge
+// pages of bot protections)
+const (
+	// the link is neither reported as working nor as broken
+	blockedUnverifiable = "unverifiable"
+	// the target is known to work in the browser
+	blockedOk = "ok"
+	// the target is strict about it, the link is reported as broken
+	blockedBroken = "broken"
+)
+
+// define the reasons for links whose target blocked our request
+const (
+	reasonBlockedByTarget = "unverifiable (blocked by target)"
+	reasonBlockedOk       = "blocked by target (working according to the configuration)"
+	reasonBlockedBroken   = "blocked by target (broken according to the configuration)"
+)
+
+// define a custom structure for the treatment of blocked requests to a domain
+// (including its subdomains), i.e. publishers answering with 403 to automated
+// requests to their articles
+type BlockingRule struct {
+	Domain string `json:"domain"`
+	Status []int  `json:"status"`
+	Result string `json:"result"`
+}
+
+// check the treatment given in the configuration
+func (rule *BlockingRule) prepare() error {
+
+	rule.Domain = strings.ToLower(rule.Domain)
+
+	switch rule.Result {
+	case "":
+		rule.Result = blockedUnverifiable
+	case blockedUnverifiable, blockedOk, blockedBroken:
+	default:
+		return errors.New("invalid result " + rule.Result + " for the blocked responses of " + rule.Domain + " (use unverifiable, ok or broken)")
+	}
+
+	return nil
+
+}
+
+// get the rule of the most specific domain of the host (nil if there is none)
+func findBlockingRule(host string) *BlockingRule {
+
+	var found *BlockingRule
+
+	for index := range config.BlockedResponses {
+
+		rule := &config.BlockedResponses[index]
+
+		if host != rule.Domain && strings.HasSuffix(host, "."+rule.Domain) == false {
+			continue
+		}
+
+		if found == nil || len(rule.Domain) > len(found.Domain) {
+			found = rule
+		}
+
+	}
+
+	return found
+
+}
+
+// check if the response is a challenge of a bot protection instead of the
+// content (status 999, challenges of cloudflare or the status codes configured
+// for the domain) and return the treatment of the blocked request
+func blockedResponse(url string, response *CheckResponse) (bool, string) {
+
+	if response == nil {
+		return false, ""
+	}
+
+	rule := findBlockingRule(urlDomain(url))
+
+	result := blockedUnverifiable
+	if rule != nil {
+		result = rule.Result
+	}
+
+	if response.StatusCode == statusBotBlocked {
+		return true, result
+	}
+
+	if response.Header != nil && strings.EqualFold(response.Header.Get("Cf-Mitigated"), "challenge") {
+		return true, result
+	}
+
+	if rule != nil {
+		for _, status := range rule.Status {
+			if response.StatusCode == status {
+				return true, result
+			}
+		}
+	}
+
+	return false, ""
+
+}
+
+// apply the configured treatment to the link whose request was blocked by its target
+func (link *Hyperlink) applyBlockedResponse(result string) {
+
+	link.IsBlocked = true
+
+	switch result {
+
+	case blockedOk:
+		link.IsWorking = true
+		link.Reason = reasonBlockedOk
+
+	case blockedBroken:
+		link.IsWorking = false
+		link.Reason = reasonBlockedBroken
+
+	default:
+		link.IsWorking = false
+		link.NotChecked = true
+		link.Reason = reasonBlockedByTarget
+
+		// a real browser may pass the challenge (if requested)
+		if options.BrowserFallback {
+			link.verifyInBrowser()
+		}
+
+	}
+
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// the fields whois servers use for the organization holding the domain
+var whoisOrganizationFields = []string{"registrant organization", "registrant organisation", "registrant", "org-name", "organization", "organisation", "org", "holder"}
+
+// the beginnings of the answers of whois servers for domains that are not registered
+var whoisNotFoundAnswers = []string{"no match", "not found", "no data found", "no entries found", "we do not have an entry", "status: free", "status: available"}
+
+// the suffixes of hosts that are only resolvable within our own network
+var intranetSuffixes = []string{".local", ".intranet", ".internal", ".lan", ".corp"}
+
+// define the ownership hints of the domains responsible for broken links
+const (
+	ownerIntranet     = "our intranet"
+	ownerUnregistered = "domain not registered anymore (defunct vendor or project?)"
+)
+
+// define a custom structure for a domain responsible for broken links (the
+// registered domain, combining all its hosts)
+type BrokenDomain struct {
+	Domain       string
+	Hosts        []string
+	Links        int
+	Broken       int
+	Organization string
+	Hint         string
+}
+
+// rank the registered domains by the number of broken links to their hosts, so
+// we know whose links break most (i.e. our intranet or a journal publisher)
+func rankBrokenDomains(summaries []DomainSummary) []BrokenDomain {
+
+	if options.TopBrokenDomains <= 0 {
+		return nil
+	}
+
+	domains := map[string]*BrokenDomain{}
+
+	for _, summary := range summaries {
+
+		if summary.Broken == 0 {
+			continue
+		}
+
+		host := strings.ToLower(summary.Domain)
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+
+		domain := host
+		if net.ParseIP(host) == nil {
+			domain = registeredDomain(host)
+		}
+
+		broken, exists := domains[domain]
+		if exists == false {
+			broken = &BrokenDomain{Domain: domain}
+			domains[domain] = broken
+		}
+
+		broken.Hosts = append(broken.Hosts, summary.Domain)
+		broken.Links += summary.Links
+		broken.Broken += summary.Broken
+
+	}
+
+	ranking := []BrokenDomain{}
+	for _, domain := range domains {
+		sort.Strings(domain.Hosts)
+		ranking = append(ranking, *domain)
+	}
+
+	sort.Slice(ranking, func(i, j int) bool {
+
+		if ranking[i].Broken != ranking[j].Broken {
+			return ranking[i].Broken > ranking[j].Broken
+		}
+
+		return ranking[i].Domain < ranking[j].Domain
+
+	})
+
+	if len(ranking) > options.TopBrokenDomains {
+		ranking = ranking[:options.TopBrokenDomains]
+	}
+
+	for index := range ranking {
+		ranking[index].identifyOwner()
+	}
+
+	return ranking
+
+}
+
+// hint at the owner of the domain, the owners given in the configuration take
+// precedence over our intranet and the organizations registered in whois
+func (domain *BrokenDomain) identifyOwner() {
+
+	if owner, exists := config.DomainOwners[domain.Domain]; exists {
+		domain.Hint = owner
+		return
+	}
+
+	if isIntranetHost(domain.Domain) {
+		domain.Hint = ownerIntranet
+		return
+	}
+
+	// the whois servers are only queried if requested (the lookups are slow)
+	if options.Whois == false {
+		return
+	}
+
+	server := whoisServer(domain.Domain)
+	if server == "" {
+		return
+	}
+
+	response := queryWhois(server, domain.Domain)
+
+	if isUnregisteredAnswer(response) {
+		domain.Hint = ownerUnregistered
+		return
+	}
+
+	domain.Organization = whoisField(response, whoisOrganizationFields)
+
+}
+
+// check if the host is part of our own network (i.e. without domain, with an
+// internal suffix or a private address)
+func isIntranetHost(host string) bool {
+
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast()
+	}
+
+	if strings.Contains(host, ".") == false {
+		return true
+	}
+
+	for _, suffix := range intranetSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+
+	for _, suffix := range config.IntranetDomains {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// check if the whois server answered that the domain is not registered
+func isUnregisteredAnswer(response string) bool {
+
+	for _, line := range strings.Split(strings.ToLower(response), "\n") {
+
+		line = strings.TrimSpace(line)
+
+		for _, answer := range whoisNotFoundAnswers {
+			if strings.HasPrefix(line, answer) {
+				return true
+			}
+		}
+
+	}
+
+	return false
+
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// the time the challenge of a bot protection may take to pass in the browser
+const browserTimeout = 45 * time.Second
+
+// the virtual time chrome waits for the scripts of the challenge (in milliseconds)
+const browserScriptBudget = "15000"
+
+// browsers need much more memory than requests, only a few are started at once
+var browserSlots = make(chan bool, 2)
+
+// define the reasons for links checked with headless chrome
+const (
+	reasonBrowserVerified = "blocked by target, but working in the browser"
+	reasonBrowserNotFound = "blocked by target, the browser shows a page not found"
+)
+
+// the markers of challenge pages still shown after the scripts of the page ran
+var challengeMarkers = []string{
+	"challenge-platform",
+	"cf-chl-",
+	"cf-browser-verification",
+	"just a moment...",
+	"checking your browser",
+	"verify you are human",
+	"g-recaptcha",
+	"h-captcha",
+}
+
+// the title of pages that are gone (as the browser gives no status code)
+var notFoundTitle = regexp.MustCompile(`(?is)<title[^>]*>[^<]*(404|not found|page not available|seite nicht gefunden)[^<]*</title>`)
+
+// check the target of a link blocked by a bot protection with headless chrome,
+// which runs the scripts of the challenge (the link stays unverifiable if the
+// challenge is not passed or chrome is not available)
+func (link *Hyperlink) verifyInBrowser() {
+
+	chrome := findChrome()
+	if chrome == "" {
+		return
+	}
+
+	browserSlots <- true
+	defer func() { <-browserSlots }()
+
+	host := urlDomain(link.RequestUrl)
+	hostConnections.acquire(host)
+	defer hostConnections.release(host)
+
+	ctx, cancel := context.WithTimeout(context.Background(), browserTimeout)
+	defer cancel()
+
+	dom, err := exec.CommandContext(ctx, chrome, "--headless", "--disable-gpu", "--window-size=1280,800",
+		"--virtual-time-budget="+browserScriptBudget, "--dump-dom", link.RequestUrl).Output()
+
+	if err != nil || len(bytes.TrimSpace(dom)) == 0 || isChallengePage(dom) {
+		return
+	}
+
+	link.NotChecked = false
+
+	if notFoundTitle.Match(dom) {
+		link.IsWorking = false
+		link.Reason = reasonBrowserNotFound
+		return
+	}
+
+	link.IsWorking = true
+	link.Reason = reasonBrowserVerified
+
+}
+
+// check if the rendered page is still the challenge of a bot protection
+func isChallengePage(dom []byte) bool {
+
+	content := strings.ToLower(string(dom))
+
+	for _, marker := range challengeMarkers {
+		if strings.Contains(content, marker) {
+			return true
+		}
+	}
+
+	return false
+
+}
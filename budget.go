@@ -0,0 +1,78 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// define the reason given for links that were not checked because of the budget
+const reasonNotChecked = "not checked, the budget of the run was exhausted"
+
+// define a custom structure limiting the requests and the duration of a run, so
+// that runs (i.e. in continuous integration) cannot hang for hours
+type Budget struct {
+	mutex       sync.Mutex
+	requests    int
+	started     time.Time
+	isExhausted bool
+}
+
+// the budget of the current run
+var budget = &Budget{}
+
+// start the budget of a new run
+func (budget *Budget) start() {
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	budget.requests = 0
+	budget.started = time.Now()
+	budget.isExhausted = false
+
+}
+
+// take a request from the budget, returns false once the budget is exhausted
+// (all remaining links are then reported as not checked)
+func (budget *Budget) take() bool {
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	if options.MaxRequests > 0 && budget.requests >= options.MaxRequests {
+		budget.isExhausted = true
+	}
+
+	if options.MaxDuration > 0 && time.Since(budget.started) >= options.MaxDuration {
+		budget.isExhausted = true
+	}
+
+	if budget.isExhausted {
+		return false
+	}
+
+	budget.requests++
+
+	return true
+
+}
+
+// exhaust the budget, so that none of the remaining links is checked
+func (budget *Budget) exhaust() {
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	budget.isExhausted = true
+
+}
+
+// check if the run was truncated because the budget was exhausted
+func (budget *Budget) exhausted() bool {
+
+	budget.mutex.Lock()
+	defer budget.mutex.Unlock()
+
+	return budget.isExhausted
+
+}
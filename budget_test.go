@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// check that the budget runs out after the maximum number of requests and after
+// the maximum duration of the run
+func TestBudget(t *testing.T) {
+
+	previousOptions := options
+	defer func() {
+		options = previousOptions
+		budget.start()
+	}()
+
+	options.MaxRequests = 2
+	options.MaxDuration = 0
+	budget.start()
+
+	if budget.take() == false || budget.take() == false {
+		t.Errorf("the budget was exhausted before the maximum number of requests")
+	}
+
+	if budget.take() || budget.exhausted() == false {
+		t.Errorf("the budget was not exhausted after the maximum number of requests")
+	}
+
+	// a new run starts with the whole budget
+	budget.start()
+
+	if budget.take() == false || budget.exhausted() {
+		t.Errorf("the budget of the previous run was kept")
+	}
+
+	options.MaxRequests = 0
+	options.MaxDuration = time.Millisecond
+	budget.start()
+
+	time.Sleep(2 * time.Millisecond)
+
+	if budget.take() || budget.exhausted() == false {
+		t.Errorf("the budget was not exhausted after the maximum duration")
+	}
+
+}
+
+// check that the links beyond the budget are reported as not checked and that
+// the report is marked as truncated
+func TestBudgetOfRun(t *testing.T) {
+
+	directory := writePipelineDocuments(t, 1, 10)
+
+	first := &countingChecker{calls: map[string]int{}}
+	usePipelineCheckers(t, first, nil)
+	t.Cleanup(budget.start)
+
+	options.MaxRequests = 3
+	budget.start()
+
+	report := validateDirectories([]string{directory})
+
+	notChecked := 0
+	for _, link := range report.Documents[0].Hyperlinks {
+		if link.NotChecked && link.Reason == reasonNotChecked {
+			notChecked++
+		}
+	}
+
+	if len(first.calls) != 3 || notChecked != 7 {
+		t.Errorf("%d links were checked and %d not checked (expected 3 and 7)", len(first.calls), notChecked)
+	}
+
+	if report.IsTruncated == false {
+		t.Errorf("the report is not marked as truncated")
+	}
+
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// the build information of the tool, set at build time with i.e.
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// define a custom structure with the build information recorded in every report
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	Date      string
+	GoVersion string
+}
+
+// get the build information of the running executable, falling back to the
+// information recorded by the go toolchain if it was not set at build time
+func currentBuildInfo() BuildInfo {
+
+	info := BuildInfo{Version: version, Commit: commit, Date: buildDate, GoVersion: runtime.Version()}
+
+	embedded, ok := debug.ReadBuildInfo()
+	if ok == false {
+		return info
+	}
+
+	if info.Version == "dev" && embedded.Main.Version != "" && embedded.Main.Version != "(devel)" {
+		info.Version = embedded.Main.Version
+	}
+
+	modified := false
+
+	for _, setting := range embedded.Settings {
+
+		switch setting.Key {
+		case "vcs.revision":
+			if commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if buildDate == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+
+	}
+
+	// builds from a working copy with local changes are marked as such
+	if commit == "" && modified && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+
+	return info
+
+}
+
+// describe the build in a single line (as shown in the footer of the report)
+func (info BuildInfo) String() string {
+
+	description := info.Version
+
+	if info.Commit != "" {
+		commit := info.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		description += ", commit " + commit
+	}
+
+	if info.Date != "" {
+		description += ", built " + info.Date
+	}
+
+	return description + ", " + info.GoVersion
+
+}
+
+// print the version, commit, build date and go version of the tool
+func runVersion(arguments []string) {
+
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+	flags.Parse(arguments)
+
+	info := currentBuildInfo()
+
+	fmt.Printf("validate-links %s\n", info.Version)
+
+	if info.Commit != "" {
+		fmt.Printf("commit:     %s\n", info.Commit)
+	}
+
+	if info.Date != "" {
+		fmt.Printf("build date: %s\n", info.Date)
+	}
+
+	fmt.Printf("go version: %s\n", info.GoVersion)
+
+}
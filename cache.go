@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// define a custom structure for the validators of a response remembered from a
+// previous run
+type CacheEntry struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Checked      time.Time `json:"checked"`
+}
+
+// define a custom structure for the cache of the responses of previous runs, used
+// to make conditional requests (the servers only respond with the content if it
+// was modified since)
+type LinkCache struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]CacheEntry
+}
+
+// the cache of the current run (nil if no cache should be used)
+var linkCache *LinkCache
+
+// load the cache from the given file (a missing file is an empty cache)
+func loadLinkCache(path string) *LinkCache {
+
+	cache := &LinkCache{path: path, entries: make(map[string]CacheEntry)}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache
+	}
+
+	if err != nil {
+		log.Println("ERROR: could not read the cache file, the cache is not used")
+		return cache
+	}
+
+	err = json.Unmarshal(data, &cache.entries)
+	if err != nil {
+		log.Println("ERROR: invalid cache file, the cache is rebuilt")
+		cache.entries = make(map[string]CacheEntry)
+	}
+
+	return cache
+
+}
+
+// get the conditions of the cached response for the request of the given url
+func (cache *LinkCache) conditions(url string) http.Header {
+
+	conditions := http.Header{}
+
+	// the cache is optional
+	if cache == nil {
+		return conditions
+	}
+
+	cache.mutex.Lock()
+	entry, exists := cache.entries[redactUrl(url)]
+	cache.mutex.Unlock()
+
+	if exists == false {
+		return conditions
+	}
+
+	if entry.ETag != "" {
+		conditions.Set("If-None-Match", entry.ETag)
+	}
+
+	if entry.LastModified != "" {
+		conditions.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	return conditions
+
+}
+
+// remember the validators of the response of the given url
+func (cache *LinkCache) update(url string, response *CheckResponse) {
+
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// the cache file must not contain the secrets of the urls either (the links
+	// differing in redacted parameters only share their validators)
+	url = redactUrl(url)
+
+	// an unmodified resource keeps the validators of its previous response
+	if response.StatusCode == http.StatusNotModified {
+
+		entry := cache.entries[url]
+		entry.Checked = clock()
+		cache.entries[url] = entry
+
+		return
+
+	}
+
+	// only the validators of successful responses can be used to ask whether the
+	// content changed, a client or server error must not turn into a 304 later
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		delete(cache.entries, url)
+		return
+	}
+
+	entry := CacheEntry{
+		ETag:         response.Header.Get("ETag"),
+		LastModified: response.Header.Get("Last-Modified"),
+		Checked:      clock(),
+	}
+
+	// responses without validators cannot be requested conditionally
+	if entry.ETag == "" && entry.LastModified == "" {
+		delete(cache.entries, url)
+		return
+	}
+
+	cache.entries[url] = entry
+
+}
+
+// write the cache back to its file
+func (cache *LinkCache) save() {
+
+	if cache == nil {
+		return
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	data, err := json.MarshalIndent(cache.entries, "", "  ")
+	if err != nil {
+		log.Println("ERROR: could not serialize the cache")
+		return
+	}
+
+	err = ioutil.WriteFile(cache.path, data, 0644)
+	if err != nil {
+		log.Println("ERROR: could not write the cache file")
+	}
+
+}
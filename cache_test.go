@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+// check that only the validators of successful responses are remembered
+func TestLinkCacheUpdate(t *testing.T) {
+
+	const url = "http://" + fakeHost + "/resource"
+
+	validators := http.Header{}
+	validators.Set("ETag", `"v1"`)
+	validators.Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+
+	tests := []struct {
+		name       string
+		previous   bool
+		statusCode int
+		header     http.Header
+		remembered bool
+	}{
+		{"successful response", false, http.StatusOK, validators, true},
+		{"successful response without validators", true, http.StatusOK, http.Header{}, false},
+		{"not modified", true, http.StatusNotModified, http.Header{}, true},
+		{"not found", false, http.StatusNotFound, validators, false},
+		{"forbidden after success", true, http.StatusForbidden, validators, false},
+		{"server error after success", true, http.StatusInternalServerError, validators, false},
+	}
+
+	for _, test := range tests {
+
+		cache := loadLinkCache(filepath.Join(t.TempDir(), "cache.json"))
+
+		if test.previous {
+			cache.update(url, &CheckResponse{StatusCode: http.StatusOK, Header: validators})
+		}
+
+		cache.update(url, &CheckResponse{StatusCode: test.statusCode, Header: test.header})
+
+		conditions := cache.conditions(url)
+		remembered := conditions.Get("If-None-Match") == `"v1"` && conditions.Get("If-Modified-Since") != ""
+
+		if remembered != test.remembered {
+			t.Errorf("%s: conditions %v, expected validators %t", test.name, conditions, test.remembered)
+		}
+
+	}
+
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"log"
+)
+
+// define how failing canary urls are handled
+const (
+	// the links whose request failed are reported as not checked (the default)
+	canaryFailureUnknown = "unknown"
+	// the run is aborted if the canaries fail before it
+	canaryFailureAbort = "abort"
+)
+
+// define the reason given for links that were not checked because of bad connectivity
+const reasonConnectivityBad = "not checked, the connectivity was bad (canary urls failed)"
+
+// define a custom structure for the result of a canary url known to work
+type CanaryResult struct {
+	Url       string
+	IsWorking bool
+}
+
+// define a custom structure for the connectivity of a run, verified with the canary
+// urls of the configuration before and after the links are checked
+type Connectivity struct {
+	Before []CanaryResult
+	After  []CanaryResult
+}
+
+// check all canary urls of the configuration
+func checkCanaries() []CanaryResult {
+
+	results := []CanaryResult{}
+
+	for _, url := range config.Canaries {
+
+		_, err := checker.Check(url)
+		results = append(results, CanaryResult{Url: url, IsWorking: err == nil})
+
+		if err != nil {
+			log.Println("WARNING: canary url " + url + " could not be reached")
+		}
+
+	}
+
+	return results
+
+}
+
+// count the canaries of the given results that failed
+func countFailedCanaries(results []CanaryResult) int {
+
+	failed := 0
+
+	for _, result := range results {
+		if result.IsWorking == false {
+			failed++
+		}
+	}
+
+	return failed
+
+}
+
+// check if any canary failed before or after the run
+func (connectivity *Connectivity) IsBad() bool {
+	return countFailedCanaries(connectivity.Before) > 0 || countFailedCanaries(connectivity.After) > 0
+}
+
+// get the number of canaries failing before the run
+func (connectivity *Connectivity) FailedBefore() int {
+	return countFailedCanaries(connectivity.Before)
+}
+
+// get the number of canaries failing after the run
+func (connectivity *Connectivity) FailedAfter() int {
+	return countFailedCanaries(connectivity.After)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// define a custom structure for a client certificate presented to the servers of
+// all links matching the pattern (i.e. internal services requiring mutual tls)
+type ClientCertificate struct {
+	Pattern     string `json:"pattern"`
+	Certificate string `json:"certificate"`
+	Key         string `json:"key"`
+	// the certificate authority of the servers (if they are not publicly trusted)
+	CertificateAuthority string `json:"certificateAuthority"`
+
+	matcher   *regexp.Regexp
+	tlsConfig *tls.Config
+
+	// the transports presenting the certificate by the proxy they use (the
+	// checkers of a run may use different proxies)
+	transports     map[string]*http.Transport
+	transportsLock *sync.Mutex
+}
+
+// load the client certificate given in the configuration
+func (clientCertificate *ClientCertificate) prepare() error {
+
+	matcher, err := regexp.Compile(clientCertificate.Pattern)
+	if err != nil {
+		return errors.New("invalid client certificate pattern " + clientCertificate.Pattern)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(clientCertificate.Certificate, clientCertificate.Key)
+	if err != nil {
+		return errors.New("could not load the client certificate " + clientCertificate.Certificate + ": " + err.Error())
+	}
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{certificate}}
+
+	if clientCertificate.CertificateAuthority != "" {
+
+		data, err := ioutil.ReadFile(clientCertificate.CertificateAuthority)
+		if err != nil {
+			return errors.New("could not read the certificate authority " + clientCertificate.CertificateAuthority)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+
+		if tlsConfig.RootCAs.AppendCertsFromPEM(data) == false {
+			return errors.New("invalid certificate authority " + clientCertificate.CertificateAuthority)
+		}
+
+	}
+
+	clientCertificate.matcher = matcher
+	clientCertificate.tlsConfig = tlsConfig
+	clientCertificate.transports = map[string]*http.Transport{}
+	clientCertificate.transportsLock = &sync.Mutex{}
+
+	return nil
+
+}
+
+// get the transport presenting the certificate through the given proxy (the
+// proxy of the environment is used if none is given)
+func (clientCertificate *ClientCertificate) transport(proxy string) (*http.Transport, error) {
+
+	clientCertificate.transportsLock.Lock()
+	defer clientCertificate.transportsLock.Unlock()
+
+	if transport, exists := clientCertificate.transports[proxy]; exists {
+		return transport, nil
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: clientCertificate.tlsConfig,
+	}
+
+	if proxy != "" {
+
+		proxyUrl, err := url.Parse(proxy)
+		if err != nil {
+			return nil, errors.New("invalid proxy " + proxy)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyUrl)
+
+	}
+
+	clientCertificate.transports[proxy] = transport
+
+	return transport, nil
+
+}
+
+// get the client certificate of the first pattern matching the url (nil if there is none)
+func findClientCertificate(url string) *ClientCertificate {
+
+	for index := range config.ClientCertificates {
+		if config.ClientCertificates[index].matcher.MatchString(url) {
+			return &config.ClientCertificates[index]
+		}
+	}
+
+	return nil
+
+}
+
+// send a single request to the url presenting the client certificate (through
+// the proxy of the checker, if it has one), the redirects are followed by the
+// checker like the ones of all other requests
+func (clientCertificate *ClientCertificate) send(url string, method string, timeout time.Duration, proxy string, isConditional bool) (*http.Response, error) {
+
+	transport, err := clientCertificate.transport(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendRequest(http.Client{Transport: transport}, url, method, timeout, isConditional)
+
+}
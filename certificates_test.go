@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// write a self signed client certificate and its key to the directory
+func writeClientCertificate(t *testing.T, directory string) (string, string, *x509.Certificate) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "validate-links"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	data, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, _ := x509.ParseCertificate(data)
+	keyData, _ := x509.MarshalECPrivateKey(key)
+
+	certificatePath := filepath.Join(directory, "client.pem")
+	keyPath := filepath.Join(directory, "client.key")
+
+	os.WriteFile(certificatePath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: data}), 0600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyData}), 0600)
+
+	return certificatePath, keyPath, certificate
+
+}
+
+// check that the requests presenting a client certificate follow the redirects
+// the same way as all other requests
+func TestClientCertificateRedirects(t *testing.T) {
+
+	directory := t.TempDir()
+	certificatePath, keyPath, certificate := writeClientCertificate(t, directory)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/article", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.Write([]byte("article"))
+		}
+	}))
+
+	clientAuthorities := x509.NewCertPool()
+	clientAuthorities.AddCert(certificate)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientAuthorities}
+	server.StartTLS()
+	defer server.Close()
+
+	authorityPath := filepath.Join(directory, "ca.pem")
+	os.WriteFile(authorityPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+
+	previousConfig, previousOptions := config, options
+	defer func() { config, options = previousConfig, previousOptions }()
+
+	config = Config{ClientCertificates: []ClientCertificate{{
+		Pattern:              "^" + server.URL,
+		Certificate:          certificatePath,
+		Key:                  keyPath,
+		CertificateAuthority: authorityPath,
+	}}}
+
+	err := config.prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options.MaxRedirects = 3
+	checker := &httpChecker{Timeout: 5 * time.Second}
+
+	response, err := checker.Check(server.URL + "/moved")
+	if err != nil {
+		t.Fatalf("the redirect was not followed: %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK || response.FinalUrl != server.URL+"/article" {
+		t.Errorf("unexpected response %d from %s", response.StatusCode, response.FinalUrl)
+	}
+
+	_, err = checker.Check(server.URL + "/loop")
+	if redirectErr, ok := err.(*RedirectError); ok == false || strings.HasPrefix(redirectErr.Reason, reasonRedirectLoop) == false {
+		t.Errorf("the redirect loop was not recognized: %v", err)
+	}
+
+}
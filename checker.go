@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/franela/goreq"
+)
+
+// define the interface used to access the targets of hyperlinks, so that it can
+// be replaced (i.e. by fakes in tests, recorded responses or offline validators)
+type Checker interface {
+	Check(url string) (*CheckResponse, error)
+}
+
+// define a custom structure for the response of a checked url
+type CheckResponse struct {
+	StatusCode int
+	Header     http.Header
+	// the url of the response after all redirects
+	FinalUrl string
+	// the beginning of the content (only kept if evidence is captured or parked
+	// domains are detected)
+	Body []byte
+}
+
+// define the default checker issuing http requests
+type httpChecker struct {
+	Timeout time.Duration
+	// the proxy requests are sent through (i.e. an egress in another region)
+	Proxy string
+	// the client sending the requests instead of goreq (i.e. the client of a
+	// fake server in tests), its redirects are followed by the checker as well
+	Client *http.Client
+}
+
+// issue a GET (or HEAD) request to the specified url and wait for response
+func (checker *httpChecker) Check(url string) (*CheckResponse, error) {
+
+	// targets behind a login portal need a session first
+	ensureSession(url)
+
+	// fragile servers only receive a limited number of requests at the same time
+	host := urlDomain(url)
+	hostConnections.acquire(host)
+	defer hostConnections.release(host)
+
+	// the content is not needed unless it is kept as evidence or checked for parking
+	method := "GET"
+	if options.Head && contentBytes() == 0 {
+		method = "HEAD"
+	}
+
+	response, err := checker.request(url, method, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// follow the redirects ourselves, so loops are recognized right away instead
+	// of hanging until the timeout
+	chain := []string{url}
+
+	for isRedirect(response) {
+
+		target, err := resolveRedirect(chain[len(chain)-1], response.Header.Get("Location"))
+
+		discardResponse(response)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if containsString(chain, target) {
+			return nil, &RedirectError{Reason: reasonRedirectLoop, Chain: append(chain, target)}
+		}
+
+		if len(chain) > options.MaxRedirects {
+			return nil, &RedirectError{Reason: fmt.Sprintf("%s (more than %d)", reasonTooManyRedirects, options.MaxRedirects), Chain: append(chain, target)}
+		}
+
+		chain = append(chain, target)
+
+		response, err = checker.request(target, method, false)
+		if err != nil {
+			return nil, err
+		}
+
+	}
+
+	checkResponse := newCheckResponse(response, response.Body)
+
+	// the validators of redirected responses belong to another url
+	if len(chain) == 1 {
+		linkCache.update(url, checkResponse)
+	}
+
+	discardResponse(response)
+
+	return checkResponse, nil
+
+}
+
+// issue a single request to the given url (without following redirects), asking
+// for the content only if it was modified since the last run if requested
+func (checker *httpChecker) request(url string, method string, isConditional bool) (*http.Response, error) {
+
+	response, err := checker.send(url, method, isConditional)
+
+	// some servers do not implement head requests
+	if err == nil && method == "HEAD" && (response.StatusCode == http.StatusMethodNotAllowed || response.StatusCode == http.StatusNotImplemented) {
+		response.Body.Close()
+		response, err = checker.send(url, "GET", isConditional)
+	}
+
+	return response, err
+
+}
+
+// send a single request with the given method, presenting the client certificate
+// of the url if the service requires one
+func (checker *httpChecker) send(url string, method string, isConditional bool) (*http.Response, error) {
+
+	// some internal services require a client certificate
+	if clientCertificate := findClientCertificate(url); clientCertificate != nil {
+		return clientCertificate.send(url, method, checker.Timeout, checker.Proxy, isConditional)
+	}
+
+	if checker.Client != nil {
+		return sendRequest(*checker.Client, url, method, checker.Timeout, isConditional)
+	}
+
+	request := goreq.Request{
+		Method:    method,
+		Uri:       url,
+		Timeout:   checker.Timeout,
+		CookieJar: cookieJar,
+		Proxy:     checker.Proxy,
+	}
+
+	// a response with status 304 not modified is a valid response of a working link
+	if isConditional {
+		for name, values := range linkCache.conditions(url) {
+			request.AddHeader(name, values[0])
+		}
+	}
+
+	response, err := request.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	// the content is read through goreq (which takes care of compressed contents)
+	response.Response.Body = response.Body
+
+	return response.Response, nil
+
+}
+
+// send a single request with the given client (a copy), the redirects are not
+// followed by the client
+func sendRequest(client http.Client, url string, method string, timeout time.Duration, isConditional bool) (*http.Response, error) {
+
+	client.Timeout = timeout
+
+	client.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// the cookie jar is only created after the configuration is loaded
+	if client.Jar == nil && cookieJar != nil {
+		client.Jar = cookieJar
+	}
+
+	request, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if isConditional {
+		for name, values := range linkCache.conditions(url) {
+			request.Header[name] = values
+		}
+	}
+
+	return client.Do(request)
+
+}
+
+// close the given response, the connection is only kept alive for the next request
+// if the response was read completely (larger contents are not worth it)
+func discardResponse(response *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(response.Body, keepAliveBytes))
+	response.Body.Close()
+}
+
+// create the response of a check, we are not interested in the content unless the
+// beginning of it should be kept as evidence or is needed to detect parked domains
+func newCheckResponse(response *http.Response, body io.Reader) *CheckResponse {
+
+	checkResponse := &CheckResponse{StatusCode: response.StatusCode, Header: response.Header}
+
+	if response.Request != nil && response.Request.URL != nil {
+		checkResponse.FinalUrl = response.Request.URL.String()
+	}
+
+	if bytes := contentBytes(); bytes > 0 {
+		checkResponse.Body, _ = ioutil.ReadAll(io.LimitReader(body, int64(bytes)))
+	}
+
+	return checkResponse
+
+}
+
+// get the number of bytes of the content kept with the response of a check
+func contentBytes() int {
+
+	bytes := 0
+
+	if options.EvidenceDirectory != "" {
+		bytes = options.EvidenceBytes
+	}
+
+	if options.DetectParking && bytes < parkingContentBytes {
+		bytes = parkingContentBytes
+	}
+
+	return bytes
+
+}
+
+// fetch the given url and decode its json content into the value given
+// (returns the status code of the response)
+var fetchJson = func(url string, value interface{}) (int, error) {
+
+	response, err := goreq.Request{
+		Uri:     url,
+		Accept:  "application/json",
+		Timeout: 15000 * time.Millisecond,
+	}.Do()
+
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	return response.StatusCode, json.NewDecoder(response.Body).Decode(value)
+
+}
+
+// the checker used to validate all hyperlinks
+// set a timeout of 15 seconds if there is no response
+var checker Checker = &httpChecker{Timeout: 15000 * time.Millisecond}
+
+// the checker used to validate the hyperlinks failing in the first pass again with
+// a longer timeout (only used if a retry timeout is given)
+var retryChecker Checker = &httpChecker{Timeout: 60000 * time.Millisecond}
+
+// the checker used to validate working hyperlinks again from a secondary location
+// (only used if a secondary proxy is given)
+var secondaryChecker Checker = &httpChecker{Timeout: 15000 * time.Millisecond}
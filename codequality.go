@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// define the severities of the gitlab code quality format
+const (
+	codeQualityInfo     = "info"
+	codeQualityMinor    = "minor"
+	codeQualityMajor    = "major"
+	codeQualityCritical = "critical"
+)
+
+// define a custom structure for an issue of the gitlab code quality report, which
+// shows the issues in the merge request widget
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    CodeQualityLocation `json:"location"`
+}
+
+// define a custom structure for the location of an issue (documents have no
+// meaningful lines, all issues are reported on the first line)
+type CodeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// collect the issues of all documents of the reports in the code quality format
+func codeQualityIssues(reports []Report) []CodeQualityIssue {
+
+	issues := []CodeQualityIssue{}
+
+	for _, report := range reports {
+		for _, document := range report.Documents {
+
+			documentPath := strings.TrimPrefix(filepath.ToSlash(document.Path), "./")
+
+			addIssue := func(checkName string, severity string, description string) {
+
+				issue := CodeQualityIssue{
+					Description: description,
+					CheckName:   checkName,
+					Severity:    severity,
+					Fingerprint: codeQualityFingerprint(documentPath, checkName, description),
+				}
+
+				issue.Location.Path = documentPath
+				issue.Location.Lines.Begin = 1
+
+				issues = append(issues, issue)
+
+			}
+
+			if document.IsLocked() {
+				addIssue("locked-document", codeQualityMajor, "password protected document could not be opened")
+			}
+
+			for _, warning := range document.QuotaWarnings {
+				addIssue("link-quota", codeQualityMinor, "the document contains "+warning)
+			}
+
+			for _, link := range document.Hyperlinks {
+
+				switch {
+				case link.HasUnknownScheme:
+					addIssue("unknown-scheme", codeQualityInfo, "link with unknown scheme: "+link.Url)
+				case link.NotChecked:
+					continue
+				case link.IsWorking == false:
+					description := "broken link: " + link.Url
+					if link.Reason != "" {
+						description += " (" + link.Reason + ")"
+					}
+					addIssue("broken-link", codeQualityMajor, description)
+				case link.IsRetracted:
+					addIssue("retracted-citation", codeQualityCritical, "link to a retracted publication: "+link.Url)
+				}
+
+				for _, warning := range link.Warnings {
+					addIssue("link-warning", codeQualityMinor, warning+": "+link.Url)
+				}
+
+			}
+
+		}
+	}
+
+	return issues
+
+}
+
+// get a stable fingerprint of the issue (gitlab uses it to track issues between runs)
+func codeQualityFingerprint(values ...string) string {
+
+	digest := sha1.Sum([]byte(strings.Join(values, "\x00")))
+
+	return hex.EncodeToString(digest[:])
+
+}
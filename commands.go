@@ -0,0 +1,14 @@
+package main
+
+// define the subcommands that can be given as first argument
+var commands = map[string]func(arguments []string){
+	"diff":         runDiff,
+	"test-filters": runTestFilters,
+	"install-hook": runInstallHook,
+	"version":      runVersion,
+	"config":       runConfig,
+	"gen-testdata": runGenerateTestdata,
+	"merge":        runMerge,
+	"worker":       runWorker,
+	"verify":       runVerify,
+}
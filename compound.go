@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// password protected office documents are not stored as zip archive but as ole
+// compound file (containing the encryption info and the encrypted zip archive)
+var compoundFileSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// define the special sector numbers of the compound file format
+const (
+	compoundEndOfChain = 0xFFFFFFFE
+	compoundFreeSector = 0xFFFFFFFF
+)
+
+// define a custom structure for a compound file read into memory
+type CompoundFile struct {
+	data             []byte
+	sectorSize       int
+	miniSectorSize   int
+	miniCutoff       uint64
+	fat              []uint32
+	miniFat          []uint32
+	miniStream       []byte
+	directoryEntries []compoundEntry
+}
+
+// define a custom structure for an entry of the directory of a compound file
+type compoundEntry struct {
+	name        string
+	entryType   byte
+	startSector uint32
+	size        uint64
+}
+
+// check if the given data starts with the signature of a compound file
+func isCompoundFile(data []byte) bool {
+	return len(data) >= len(compoundFileSignature) && bytes.Equal(data[:len(compoundFileSignature)], compoundFileSignature)
+}
+
+// parse the header, the allocation tables and the directory of the compound file
+func readCompoundFile(data []byte) (*CompoundFile, error) {
+
+	if len(data) < 512 || isCompoundFile(data) == false {
+		return nil, errors.New("not a compound file")
+	}
+
+	// the sectors have 512 bytes (version 3) or 4096 bytes (version 4), the mini
+	// sectors always have 64 bytes
+	sectorShift := binary.LittleEndian.Uint16(data[0x1E:])
+	miniSectorShift := binary.LittleEndian.Uint16(data[0x20:])
+
+	if sectorShift != 9 && sectorShift != 12 {
+		return nil, errors.New("invalid sector size")
+	}
+
+	if miniSectorShift != 6 {
+		return nil, errors.New("invalid mini sector size")
+	}
+
+	file := &CompoundFile{
+		data:           data,
+		sectorSize:     1 << sectorShift,
+		miniSectorSize: 1 << miniSectorShift,
+		miniCutoff:     uint64(binary.LittleEndian.Uint32(data[0x38:])),
+	}
+
+	if len(data) < file.sectorSize {
+		return nil, errors.New("not a compound file")
+	}
+
+	// the sectors of the allocation table are listed in the header and the
+	// (rarely needed) chain of additional list sectors
+	fatSectors := []uint32{}
+
+	for index := 0; index < 109; index++ {
+		fatSectors = append(fatSectors, binary.LittleEndian.Uint32(data[0x4C+index*4:]))
+	}
+
+	listSector := binary.LittleEndian.Uint32(data[0x44:])
+	entriesPerSector := file.sectorSize / 4
+
+	for visited := 0; listSector != compoundEndOfChain && listSector != compoundFreeSector; visited++ {
+
+		sector, err := file.sector(listSector)
+		if err != nil || visited > len(data)/file.sectorSize {
+			return nil, errors.New("invalid allocation table list")
+		}
+
+		for index := 0; index < entriesPerSector-1; index++ {
+			fatSectors = append(fatSectors, binary.LittleEndian.Uint32(sector[index*4:]))
+		}
+
+		listSector = binary.LittleEndian.Uint32(sector[(entriesPerSector-1)*4:])
+
+	}
+
+	for _, fatSector := range fatSectors {
+
+		if fatSector == compoundFreeSector || fatSector == compoundEndOfChain {
+			continue
+		}
+
+		sector, err := file.sector(fatSector)
+		if err != nil {
+			return nil, err
+		}
+
+		for index := 0; index < entriesPerSector; index++ {
+			file.fat = append(file.fat, binary.LittleEndian.Uint32(sector[index*4:]))
+		}
+
+	}
+
+	// read the directory with all storages and streams
+	directory, err := file.chain(binary.LittleEndian.Uint32(data[0x30:]), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for offset := 0; offset+128 <= len(directory); offset += 128 {
+		file.directoryEntries = append(file.directoryEntries, parseCompoundEntry(directory[offset:offset+128]))
+	}
+
+	if len(file.directoryEntries) == 0 {
+		return nil, errors.New("missing root entry")
+	}
+
+	// small streams are stored in the mini stream (the stream of the root entry)
+	root := file.directoryEntries[0]
+
+	file.miniStream, err = file.chain(root.startSector, root.size)
+	if err != nil {
+		return nil, err
+	}
+
+	miniFat, err := file.chain(binary.LittleEndian.Uint32(data[0x3C:]), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for offset := 0; offset+4 <= len(miniFat); offset += 4 {
+		file.miniFat = append(file.miniFat, binary.LittleEndian.Uint32(miniFat[offset:]))
+	}
+
+	return file, nil
+
+}
+
+// parse a single entry of the directory
+func parseCompoundEntry(data []byte) compoundEntry {
+
+	// the name is stored as zero terminated utf-16 string
+	nameLength := int(binary.LittleEndian.Uint16(data[64:])) / 2
+	if nameLength > 32 {
+		nameLength = 32
+	}
+
+	name := []uint16{}
+
+	for index := 0; index < nameLength; index++ {
+
+		character := binary.LittleEndian.Uint16(data[index*2:])
+		if character == 0 {
+			break
+		}
+
+		name = append(name, character)
+
+	}
+
+	return compoundEntry{
+		name:        string(utf16.Decode(name)),
+		entryType:   data[66],
+		startSector: binary.LittleEndian.Uint32(data[116:]),
+		size:        binary.LittleEndian.Uint64(data[120:]),
+	}
+
+}
+
+// get the content of the sector with the given number
+func (file *CompoundFile) sector(number uint32) ([]byte, error) {
+
+	offset := (int(number) + 1) * file.sectorSize
+
+	if number >= compoundEndOfChain-2 || offset+file.sectorSize > len(file.data) {
+		return nil, errors.New("invalid sector")
+	}
+
+	return file.data[offset : offset+file.sectorSize], nil
+
+}
+
+// read the chain of sectors starting with the given sector (limited to the given
+// size if it is not zero)
+func (file *CompoundFile) chain(start uint32, size uint64) ([]byte, error) {
+
+	content := []byte{}
+
+	// a chain cannot have more sectors than the file (cyclic chains of damaged
+	// files would never end otherwise)
+	maxSectors := len(file.data) / file.sectorSize
+
+	for sector, count := start, 0; sector != compoundEndOfChain && sector != compoundFreeSector; count++ {
+
+		if count >= maxSectors || int(sector) >= len(file.fat) {
+			return nil, errors.New("invalid sector chain")
+		}
+
+		data, err := file.sector(sector)
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+		sector = file.fat[sector]
+
+	}
+
+	// the stream of a damaged file may be larger than its chain
+	if size > uint64(len(content)) {
+		return nil, errors.New("invalid sector chain")
+	}
+
+	if size > 0 {
+		content = content[:size]
+	}
+
+	return content, nil
+
+}
+
+// read the chain of mini sectors starting with the given mini sector
+func (file *CompoundFile) miniChain(start uint32, size uint64) ([]byte, error) {
+
+	content := []byte{}
+
+	// a chain cannot have more mini sectors than the mini stream
+	maxSectors := len(file.miniStream) / file.miniSectorSize
+
+	for sector, count := start, 0; sector != compoundEndOfChain && sector != compoundFreeSector; count++ {
+
+		offset := int(sector) * file.miniSectorSize
+
+		if count >= maxSectors || int(sector) >= len(file.miniFat) || offset+file.miniSectorSize > len(file.miniStream) || uint64(len(content)) > size {
+			return nil, errors.New("invalid mini sector chain")
+		}
+
+		content = append(content, file.miniStream[offset:offset+file.miniSectorSize]...)
+		sector = file.miniFat[sector]
+
+	}
+
+	if size > uint64(len(content)) {
+		return nil, errors.New("invalid mini sector chain")
+	}
+
+	return content[:size], nil
+
+}
+
+// read the content of the stream with the given name
+func (file *CompoundFile) stream(name string) ([]byte, error) {
+
+	for _, entry := range file.directoryEntries {
+
+		// we are only interested in streams (and not in storages)
+		if entry.entryType != 2 || entry.name != name {
+			continue
+		}
+
+		if entry.size < file.miniCutoff {
+			return file.miniChain(entry.startSector, entry.size)
+		}
+
+		return file.chain(entry.startSector, entry.size)
+
+	}
+
+	return nil, errors.New("missing stream " + name)
+
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"time"
+)
+
+// define the structure of the configuration file (json)
+type Config struct {
+	ExpiryWarningDays  int                 `json:"expiryWarningDays"`
+	Expirations        []ExpirationPolicy  `json:"expirations"`
+	Roots              []RootConfig        `json:"roots"`
+	Filters            []FilterConfig      `json:"filters"`
+	Owners             []OwnerMapping      `json:"owners"`
+	Passwords          []string            `json:"passwords"`
+	PasswordFile       string              `json:"passwordFile"`
+	MaxExternalLinks   int                 `json:"maxExternalLinks"`
+	MaxDomains         int                 `json:"maxDomains"`
+	Schemes            map[string]bool     `json:"schemes"`
+	PhoneFormats       []string            `json:"phoneFormats"`
+	PhoneCountryCode   string              `json:"phoneCountryCode"`
+	PhoneDirectory     string              `json:"phoneDirectory"`
+	CustomSchemes      []CustomScheme      `json:"customSchemes"`
+	ValidationCommands []ValidationCommand `json:"validationCommands"`
+	Logins             []LoginConfig       `json:"logins"`
+	ClientCertificates []ClientCertificate `json:"clientCertificates"`
+	Canaries           []string            `json:"canaries"`
+	CanaryFailure      string              `json:"canaryFailure"`
+	Workers            []RemoteWorker      `json:"workers"`
+	Zones              []ZonePolicy        `json:"zones"`
+	Redactions         []RedactionRule     `json:"redactions"`
+	TrackingParameters []string            `json:"trackingParameters"`
+	SiteSearch         map[string]string   `json:"siteSearch"`
+	DomainOwners       map[string]string   `json:"domainOwners"`
+	IntranetDomains    []string            `json:"intranetDomains"`
+	Ignores            []IgnoreEntry       `json:"ignores"`
+	MailDomains        []string            `json:"mailDomains"`
+	ProtectedDomains   []string            `json:"protectedDomains"`
+	BlockedResponses   []BlockingRule      `json:"blockedResponses"`
+
+	phoneFormats []*regexp.Regexp
+}
+
+// define a custom structure for urls that are known to retire at a given date
+type ExpirationPolicy struct {
+	Pattern string `json:"pattern"`
+	Expires string `json:"expires"`
+	Reason  string `json:"reason"`
+
+	matcher *regexp.Regexp
+	expires time.Time
+}
+
+// the configuration of the current run
+var config = Config{ExpiryWarningDays: 30}
+
+// load the configuration from the given file
+func loadConfig(path string) error {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return err
+	}
+
+	return config.prepare()
+
+}
+
+// compile all patterns and parse all dates of the configuration
+func (config *Config) prepare() error {
+
+	switch config.CanaryFailure {
+	case "":
+		config.CanaryFailure = canaryFailureUnknown
+	case canaryFailureUnknown, canaryFailureAbort:
+	default:
+		return errors.New("invalid canary failure " + config.CanaryFailure + " (use unknown or abort)")
+	}
+
+	for index := range config.Expirations {
+
+		policy := &config.Expirations[index]
+
+		matcher, err := regexp.Compile(policy.Pattern)
+		if err != nil {
+			return errors.New("invalid expiration pattern " + policy.Pattern)
+		}
+
+		expires, err := time.Parse("2006-01-02", policy.Expires)
+		if err != nil {
+			return errors.New("invalid expiration date " + policy.Expires)
+		}
+
+		policy.matcher = matcher
+		policy.expires = expires
+
+	}
+
+	for index := range config.CustomSchemes {
+
+		err := config.CustomSchemes[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.ClientCertificates {
+
+		err := config.ClientCertificates[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.Workers {
+
+		err := config.Workers[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	err := prepareTrackingParameters(config.TrackingParameters)
+	if err != nil {
+		return err
+	}
+
+	for index := range config.Ignores {
+
+		err := config.Ignores[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.Redactions {
+
+		err := config.Redactions[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.BlockedResponses {
+
+		err := config.BlockedResponses[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.Zones {
+
+		err := config.Zones[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.Logins {
+
+		err := config.Logins[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for index := range config.ValidationCommands {
+
+		err := config.ValidationCommands[index].prepare()
+		if err != nil {
+			return err
+		}
+
+	}
+
+	for _, format := range config.PhoneFormats {
+
+		matcher, err := regexp.Compile(format)
+		if err != nil {
+			return errors.New("invalid phone format " + format)
+		}
+
+		config.phoneFormats = append(config.phoneFormats, matcher)
+
+	}
+
+	for index := range config.Owners {
+
+		mapping := &config.Owners[index]
+
+		matcher, err := regexp.Compile(mapping.Pattern)
+		if err != nil {
+			return errors.New("invalid owner pattern " + mapping.Pattern)
+		}
+
+		mapping.matcher = matcher
+
+	}
+
+	return nil
+
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// define the sources a setting can come from (in the order of their precedence)
+const (
+	sourceFlag        = "flag"
+	sourceEnvironment = "env"
+	sourceFile        = "file"
+	sourceDefault     = "default"
+)
+
+// the keys of the configuration whose values must not be printed
+var secretConfigKeys = map[string]bool{
+	"passwords": true,
+	"fields":    true,
+	"token":     true,
+}
+
+// run a subcommand concerning the configuration
+func runConfig(arguments []string) {
+
+	if len(arguments) == 0 || arguments[0] != "check" {
+		fmt.Fprintln(os.Stderr, "usage: validate-links config check [options] [url ...]")
+		fmt.Fprintln(os.Stderr, "  accepts all options of the validation, the urls given are explained")
+		os.Exit(2)
+	}
+
+	runConfigCheck(arguments[1:])
+
+}
+
+// validate the configuration, print the effective settings with their source
+// and explain how the given sample urls would be validated
+func runConfigCheck(arguments []string) {
+
+	parseOptions(arguments)
+
+	initializeMatchers()
+
+	valid := true
+
+	// the keys given in the configuration file (to tell them from the defaults)
+	fileKeys := map[string]json.RawMessage{}
+
+	if options.ConfigFile != "" {
+
+		err := loadConfig(options.ConfigFile)
+		if err != nil {
+			fmt.Printf("ERROR: invalid configuration file %s: %s\n", options.ConfigFile, err)
+			os.Exit(1)
+		}
+
+		data, _ := ioutil.ReadFile(options.ConfigFile)
+		json.Unmarshal(data, &fileKeys)
+
+		// keys that are not part of the configuration are usually typos
+		knownKeys := configKeys()
+
+		for key := range fileKeys {
+			if knownKeys[key] == false {
+				fmt.Printf("WARNING: unknown key %s in the configuration file\n", key)
+				valid = false
+			}
+		}
+
+	}
+
+	initializeValidators()
+
+	err := initializeFilters()
+	if err != nil {
+		fmt.Println("ERROR: invalid filters:", err)
+		os.Exit(1)
+	}
+
+	// print the options with the source of their value
+	fmt.Println("Options:")
+
+	setFlags := map[string]bool{}
+	flag.Visit(func(option *flag.Flag) {
+		setFlags[option.Name] = true
+	})
+
+	flag.VisitAll(func(option *flag.Flag) {
+
+		source := sourceDefault
+		if setFlags[option.Name] {
+			source = sourceFlag
+		} else if environmentOptions[option.Name] {
+			source = sourceEnvironment + " " + environmentName(option.Name)
+		}
+
+		fmt.Printf("  -%s = %q (%s)\n", option.Name, option.Value.String(), source)
+
+	})
+
+	// print the (merged) configuration with the source of each key
+	fmt.Println("\nConfiguration:")
+
+	effective := map[string]interface{}{}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		log.Fatalln("ERROR: could not serialize the configuration:", err)
+	}
+
+	json.Unmarshal(data, &effective)
+	redactSecrets(effective)
+
+	keys := []string{}
+	for key := range effective {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+
+		source := sourceDefault
+		if _, exists := fileKeys[key]; exists {
+			source = sourceFile
+		}
+
+		value, _ := json.Marshal(effective[key])
+		fmt.Printf("  %s = %s (%s)\n", key, value, source)
+
+	}
+
+	// explain the validation of the sample urls
+	for _, url := range options.Roots {
+		fmt.Println()
+		explainUrl(url)
+	}
+
+	if valid == false {
+		os.Exit(1)
+	}
+
+}
+
+// get the keys of the configuration file (the json names of the fields)
+func configKeys() map[string]bool {
+
+	keys := map[string]bool{}
+	configType := reflect.TypeOf(Config{})
+
+	for index := 0; index < configType.NumField(); index++ {
+
+		name := strings.Split(configType.Field(index).Tag.Get("json"), ",")[0]
+
+		if name != "" {
+			keys[name] = true
+		}
+
+	}
+
+	return keys
+
+}
+
+// replace the values of secret keys (recursively) so they are not printed
+func redactSecrets(value interface{}) {
+
+	switch value := value.(type) {
+
+	case map[string]interface{}:
+		for key, entry := range value {
+			if secretConfigKeys[key] && entry != nil {
+				value[key] = "(redacted)"
+				continue
+			}
+			redactSecrets(entry)
+		}
+
+	case []interface{}:
+		for _, entry := range value {
+			redactSecrets(entry)
+		}
+
+	}
+
+}
+
+// explain which filters, policies and validators apply to the given url
+func explainUrl(url string) {
+
+	fmt.Println(url)
+
+	link := Hyperlink{Url: url}
+	scheme := linkScheme(url)
+
+	fmt.Printf("  scheme: %s", scheme)
+	if isSchemeEnabled(scheme) == false {
+		fmt.Printf(" (disabled, the link is not checked)\n")
+		return
+	}
+	fmt.Println()
+
+	if isInScope(&link) == false {
+		fmt.Printf("  outside of the scope %s (the link is not checked)\n", options.Scope)
+		return
+	}
+
+	filter, reason := findSkippingFilter(&link)
+	if filter != nil {
+		fmt.Printf("  skipped by filter %s: %s\n", filter.Name(), reason)
+		return
+	}
+
+	fmt.Printf("  passes all %d filters\n", len(filterChain))
+
+	for _, policy := range config.Expirations {
+		if policy.matcher.MatchString(url) {
+			fmt.Printf("  expiration policy: expires %s\n", policy.Expires)
+		}
+	}
+
+	if login := findLogin(url); login != nil {
+		fmt.Printf("  login: %s (%d steps)\n", login.Host, len(login.Steps))
+	}
+
+	if certificate := findClientCertificate(url); certificate != nil {
+		fmt.Printf("  client certificate: %s\n", certificate.Certificate)
+	}
+
+	if policy := findZonePolicy(url); policy != nil {
+		fmt.Printf("  checked from zones: %s\n", strings.Join(policy.Zones, ", "))
+	}
+
+	validator := findValidator(&link)
+
+	switch {
+	case validator != nil:
+		fmt.Printf("  validated by: %s\n", validatorName(validator))
+	case isHttpScheme(scheme) && findRemoteWorker(url) != nil:
+		fmt.Printf("  validated by: worker %s\n", findRemoteWorker(url).Name)
+	case isHttpScheme(scheme):
+		fmt.Println("  validated by: request")
+	case isKnownScheme(scheme):
+		fmt.Println("  validated by: none (the scheme is not supported)")
+	default:
+		fmt.Println("  validated by: none (unknown scheme)")
+	}
+
+}
+
+// get a readable name of the given validator
+func validatorName(validator Validator) string {
+
+	if connection, ok := validator.(*connectionValidator); ok {
+		return connection.scheme + " connection"
+	}
+
+	name := reflect.TypeOf(validator).Elem().Name()
+	return strings.TrimSuffix(name, "Validator")
+
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+)
+
+// define a custom structure for a document with the same content as a document
+// that was already checked
+type documentCopy struct {
+	documentIndex int
+	path          string
+}
+
+// calculate the hash of the content of the document with the given path
+func hashDocument(path string) (string, error) {
+
+	file, err := fileSystem.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+
+	_, err = io.Copy(digest, file)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+
+}
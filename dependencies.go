@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// define a custom structure for a link from one document to another document
+type DocumentDependency struct {
+	Document string
+	Target   string
+	Exists   bool
+	// the target is one of the documents checked in this run
+	IsChecked bool
+	IsValid   bool
+}
+
+// define the reason for links to documents that did not pass the validation
+const reasonLinkedDocumentInvalid = "linked document contains broken links"
+
+// collect the links between documents (i.e. file links to other word documents)
+// and mark links to invalid documents as broken if requested
+func checkLinkedDocuments(documents []Document) []DocumentDependency {
+
+	// the validity of the targets is the one of their own links (and is not
+	// propagated further through chains of linked documents)
+	checked := make(map[string]bool)
+
+	for _, document := range documents {
+		checked[linkedDocumentKey(getAbsoluteFilePath(document.Path))] = document.IsValid
+	}
+
+	dependencies := []DocumentDependency{}
+
+	for documentIndex := range documents {
+
+		document := &documents[documentIndex]
+		wasChanged := false
+
+		for linkIndex := range document.Hyperlinks {
+
+			link := &document.Hyperlinks[linkIndex]
+
+			if link.ResolvedPath == "" || link.NotChecked {
+				continue
+			}
+
+			if _, isDocument := documentTypes[strings.ToLower(filepath.Ext(link.ResolvedPath))]; isDocument == false {
+				continue
+			}
+
+			isValid, isChecked := checked[linkedDocumentKey(link.ResolvedPath)]
+
+			dependencies = append(dependencies, DocumentDependency{
+				Document:  document.Path,
+				Target:    link.ResolvedPath,
+				Exists:    link.IsWorking,
+				IsChecked: isChecked,
+				IsValid:   isValid,
+			})
+
+			if options.RequireValidDocuments && link.IsWorking && isChecked && isValid == false {
+				link.IsWorking = false
+				link.Reason = reasonLinkedDocumentInvalid
+				wasChanged = true
+			}
+
+		}
+
+		if wasChanged {
+			document.updateValidity()
+		}
+
+	}
+
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].Document < dependencies[j].Document
+	})
+
+	return dependencies
+
+}
+
+// get the key to look up linked documents (paths are compared case insensitive,
+// as links are mostly created on windows)
+func linkedDocumentKey(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// define a custom structure for a link compared between two runs
+type LinkChange struct {
+	Document   string
+	Url        string
+	IsWorking  bool
+	NotChecked bool
+}
+
+// define a custom structure for the differences between two runs
+type ReportDelta struct {
+	NewlyBroken []LinkChange
+	NewlyFixed  []LinkChange
+	NewlyAdded  []LinkChange
+	Removed     []LinkChange
+}
+
+// compare two json reports and print the links that changed between them
+func runDiff(arguments []string) {
+
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJson := flags.Bool("json", false, "print the differences as json")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links diff [-json] old.json new.json")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	oldReport, err := readReport(flags.Arg(0))
+	if err != nil {
+		log.Fatalln("ERROR: could not read " + flags.Arg(0))
+	}
+
+	newReport, err := readReport(flags.Arg(1))
+	if err != nil {
+		log.Fatalln("ERROR: could not read " + flags.Arg(1))
+	}
+
+	delta := compareReports(oldReport, newReport)
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(delta)
+	} else {
+		delta.print(os.Stdout)
+	}
+
+	// signal newly broken links to calling scripts
+	if len(delta.NewlyBroken) > 0 {
+		os.Exit(1)
+	}
+
+}
+
+// compare the links of both reports (links are identified by document and url)
+func compareReports(oldReport Report, newReport Report) ReportDelta {
+
+	delta := ReportDelta{
+		NewlyBroken: []LinkChange{},
+		NewlyFixed:  []LinkChange{},
+		NewlyAdded:  []LinkChange{},
+		Removed:     []LinkChange{},
+	}
+
+	oldLinks := reportLinks(oldReport)
+	newLinks := reportLinks(newReport)
+
+	for _, link := range newLinks.ordered {
+
+		oldLink, existed := oldLinks.byKey[link.Document+"\n"+link.Url]
+
+		switch {
+		case existed == false:
+			delta.NewlyAdded = append(delta.NewlyAdded, link)
+		case oldLink.NotChecked || link.NotChecked:
+			// we cannot tell whether links that were not checked changed
+		case oldLink.IsWorking && link.IsWorking == false:
+			delta.NewlyBroken = append(delta.NewlyBroken, link)
+		case oldLink.IsWorking == false && link.IsWorking:
+			delta.NewlyFixed = append(delta.NewlyFixed, link)
+		}
+
+	}
+
+	for _, link := range oldLinks.ordered {
+		if _, exists := newLinks.byKey[link.Document+"\n"+link.Url]; exists == false {
+			delta.Removed = append(delta.Removed, link)
+		}
+	}
+
+	return delta
+
+}
+
+// define a custom structure holding all links of a report
+type linkIndex struct {
+	ordered []LinkChange
+	byKey   map[string]LinkChange
+}
+
+// collect all links of the report in their original order
+func reportLinks(report Report) linkIndex {
+
+	index := linkIndex{byKey: make(map[string]LinkChange)}
+
+	for _, document := range report.Documents {
+		for _, link := range document.Hyperlinks {
+
+			key := document.Path + "\n" + link.Url
+
+			// links occurring several times in a document are only compared once
+			if _, exists := index.byKey[key]; exists {
+				continue
+			}
+
+			change := LinkChange{Document: document.Path, Url: link.Url, IsWorking: link.IsWorking, NotChecked: link.NotChecked}
+
+			index.ordered = append(index.ordered, change)
+			index.byKey[key] = change
+
+		}
+	}
+
+	return index
+
+}
+
+// print the differences in a human readable form
+func (delta ReportDelta) print(output io.Writer) {
+
+	sections := []struct {
+		title string
+		links []LinkChange
+	}{
+		{"Newly broken links", delta.NewlyBroken},
+		{"Newly fixed links", delta.NewlyFixed},
+		{"Newly added links", delta.NewlyAdded},
+		{"Removed links", delta.Removed},
+	}
+
+	for _, section := range sections {
+
+		fmt.Fprintf(output, "%s (%d)\n", section.title, len(section.links))
+
+		for _, link := range section.links {
+
+			status := "working"
+			if link.NotChecked {
+				status = "not checked"
+			} else if link.IsWorking == false {
+				status = "broken"
+			}
+
+			fmt.Fprintf(output, "  %s\t%s\t%s\n", link.Document, link.Url, status)
+
+		}
+
+		fmt.Fprintln(output)
+
+	}
+
+}
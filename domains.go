@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// define a custom structure aggregating the results of all links to a domain
+type DomainSummary struct {
+	Domain         string
+	Links          int
+	Broken         int
+	AverageLatency time.Duration
+}
+
+// aggregate the results of all checked links by their target domain
+func summarizeDomains(documents []Document) []DomainSummary {
+
+	summaries := make(map[string]*DomainSummary)
+	totalLatency := make(map[string]time.Duration)
+
+	for _, document := range documents {
+		for _, link := range document.Hyperlinks {
+
+			// local files and internal targets do not have a domain
+			domain := urlDomain(link.RequestUrl)
+			if domain == "" || link.NotChecked {
+				continue
+			}
+
+			summary, exists := summaries[domain]
+			if exists == false {
+				summary = &DomainSummary{Domain: domain}
+				summaries[domain] = summary
+			}
+
+			summary.Links++
+			totalLatency[domain] += link.Duration
+
+			if link.IsWorking == false {
+				summary.Broken++
+			}
+
+		}
+	}
+
+	domains := []DomainSummary{}
+
+	for domain, summary := range summaries {
+
+		// round the latency to make it readable in the report
+		summary.AverageLatency = (totalLatency[domain] / time.Duration(summary.Links)).Round(time.Millisecond)
+
+		domains = append(domains, *summary)
+
+	}
+
+	// show the domains causing most problems first
+	sort.Slice(domains, func(i, j int) bool {
+
+		if domains[i].Broken != domains[j].Broken {
+			return domains[i].Broken > domains[j].Broken
+		}
+
+		if domains[i].Links != domains[j].Links {
+			return domains[i].Links > domains[j].Links
+		}
+
+		return domains[i].Domain < domains[j].Domain
+
+	})
+
+	return domains
+
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// define a custom structure for a url used several times in the same document
+type RepeatedLink struct {
+	Url   string
+	Count int
+}
+
+// define a custom structure for a display text used for different urls
+type ConflictingLink struct {
+	Text string
+	Urls []string
+}
+
+// find the urls used repeatedly in the document and the display texts pointing
+// to different urls, which are both frequently caused by copying and pasting
+func (document *Document) findDuplicateLinks(threshold int) {
+
+	document.RepeatedLinks = nil
+	document.ConflictingLinks = nil
+
+	counts := make(map[string]int)
+	order := []string{}
+
+	// texts are compared ignoring case and whitespace
+	texts := make(map[string][]string)
+	textOrder := []string{}
+	displayTexts := make(map[string]string)
+
+	for _, link := range document.Hyperlinks {
+
+		// embedded figures are often repeated on purpose (i.e. logos on each slide)
+		if link.Category == categoryImage {
+			continue
+		}
+
+		if counts[link.Url] == 0 {
+			order = append(order, link.Url)
+		}
+		counts[link.Url]++
+
+		displayText := strings.Join(strings.Fields(link.Text), " ")
+		text := strings.ToLower(displayText)
+		if text == "" {
+			continue
+		}
+
+		if _, exists := texts[text]; exists == false {
+			textOrder = append(textOrder, text)
+			displayTexts[text] = displayText
+		}
+
+		if containsString(texts[text], link.Url) == false {
+			texts[text] = append(texts[text], link.Url)
+		}
+
+	}
+
+	if threshold > 0 {
+		for _, url := range order {
+			if counts[url] >= threshold {
+				document.RepeatedLinks = append(document.RepeatedLinks, RepeatedLink{Url: url, Count: counts[url]})
+			}
+		}
+	}
+
+	for _, text := range textOrder {
+
+		if len(texts[text]) < 2 {
+			continue
+		}
+
+		urls := texts[text]
+		sort.Strings(urls)
+
+		document.ConflictingLinks = append(document.ConflictingLinks, ConflictingLink{Text: displayTexts[text], Urls: urls})
+
+	}
+
+}
+
+// check if the list contains the given string
+func containsString(list []string, value string) bool {
+
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+
+}
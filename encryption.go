@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"hash"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+)
+
+// define the errors reported for password protected documents
+var (
+	errDocumentLocked        = errors.New("document is password protected and none of the passwords given is correct")
+	errUnsupportedEncryption = errors.New("document is encrypted with an unsupported method")
+)
+
+// define the block keys used to derive the keys of the agile encryption (see ms-offcrypto)
+var (
+	blockKeyVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	blockKeyVerifierHash  = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	blockKeyEncryptedKey  = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+// the encrypted package is encrypted in segments of 4096 bytes
+const encryptedSegmentSize = 4096
+
+// the maximum number of hash iterations of the key derivation (the limit of
+// office), a document cannot keep the run busy with more
+const maxSpinCount = 10000000
+
+// the error reported for encryption infos with invalid sizes
+var errInvalidEncryptionInfo = errors.New("invalid encryption info")
+
+// define the structure of the xml encryption info of the agile encryption
+type agileEncryptionInfo struct {
+	KeyData struct {
+		BlockSize       int    `xml:"blockSize,attr"`
+		KeyBits         int    `xml:"keyBits,attr"`
+		HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+		CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+		CipherChaining  string `xml:"cipherChaining,attr"`
+		SaltValue       string `xml:"saltValue,attr"`
+	} `xml:"keyData"`
+	KeyEncryptors []agileKeyEncryptor `xml:"keyEncryptors>keyEncryptor"`
+}
+
+// define the structure of a key encryptor (i.e. the password) of the agile encryption
+type agileKeyEncryptor struct {
+	Uri          string `xml:"uri,attr"`
+	EncryptedKey struct {
+		SpinCount                  int    `xml:"spinCount,attr"`
+		BlockSize                  int    `xml:"blockSize,attr"`
+		KeyBits                    int    `xml:"keyBits,attr"`
+		HashSize                   int    `xml:"hashSize,attr"`
+		HashAlgorithm              string `xml:"hashAlgorithm,attr"`
+		SaltValue                  string `xml:"saltValue,attr"`
+		EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+		EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+		EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+	} `xml:"encryptedKey"`
+}
+
+// decrypt the zip archive of a password protected document with the passwords of
+// the configuration
+func decryptDocument(data []byte) ([]byte, error) {
+
+	compoundFile, err := readCompoundFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	infoStream, err := compoundFile.stream("EncryptionInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	packageStream, err := compoundFile.stream("EncryptedPackage")
+	if err != nil {
+		return nil, err
+	}
+
+	// only the agile encryption (version 4.4) of office 2010 and later is supported
+	if len(infoStream) < 8 || binary.LittleEndian.Uint16(infoStream[0:]) != 4 || binary.LittleEndian.Uint16(infoStream[2:]) != 4 {
+		return nil, errUnsupportedEncryption
+	}
+
+	var info agileEncryptionInfo
+
+	err = xml.Unmarshal(infoStream[8:], &info)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(info.KeyData.CipherAlgorithm, "AES") == false || strings.EqualFold(info.KeyData.CipherChaining, "ChainingModeCBC") == false {
+		return nil, errUnsupportedEncryption
+	}
+
+	err = info.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, password := range documentPasswords() {
+
+		key, err := info.intermediateKey(password)
+		if err == errDocumentLocked {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return info.decryptPackage(key, packageStream)
+
+	}
+
+	return nil, errDocumentLocked
+
+}
+
+// check the sizes and the number of spins given by the document, as they are used
+// to slice the keys and to derive the key from the password
+func (info *agileEncryptionInfo) validate() error {
+
+	if isAesKey(info.KeyData.BlockSize, info.KeyData.KeyBits) == false {
+		return errInvalidEncryptionInfo
+	}
+
+	for _, keyEncryptor := range info.KeyEncryptors {
+
+		encryptedKey := keyEncryptor.EncryptedKey
+
+		if strings.HasSuffix(keyEncryptor.Uri, "/password") == false {
+			continue
+		}
+
+		if isAesKey(encryptedKey.BlockSize, encryptedKey.KeyBits) == false || encryptedKey.HashSize <= 0 {
+			return errInvalidEncryptionInfo
+		}
+
+		if encryptedKey.SpinCount < 0 || encryptedKey.SpinCount > maxSpinCount {
+			return errInvalidEncryptionInfo
+		}
+
+	}
+
+	return nil
+
+}
+
+// check if the block size (in bytes) and the key size (in bits) are valid for aes
+func isAesKey(blockSize int, keyBits int) bool {
+	return blockSize == aes.BlockSize && (keyBits == 128 || keyBits == 192 || keyBits == 256)
+}
+
+// get the passwords to try (from the configuration and the password file)
+func documentPasswords() []string {
+
+	passwords := append([]string{}, config.Passwords...)
+
+	if config.PasswordFile == "" {
+		return passwords
+	}
+
+	data, err := ioutil.ReadFile(config.PasswordFile)
+	if err != nil {
+		return passwords
+	}
+
+	// the password file contains one password per line
+	for _, line := range strings.Split(string(data), "\n") {
+
+		line = strings.TrimRight(line, "\r")
+		if line != "" {
+			passwords = append(passwords, line)
+		}
+
+	}
+
+	return passwords
+
+}
+
+// derive the key for the given password and use it to decrypt the intermediate
+// key used for the package (errDocumentLocked is returned for wrong passwords)
+func (info *agileEncryptionInfo) intermediateKey(password string) ([]byte, error) {
+
+	for _, keyEncryptor := range info.KeyEncryptors {
+
+		if strings.HasSuffix(keyEncryptor.Uri, "/password") == false {
+			continue
+		}
+
+		encryptedKey := keyEncryptor.EncryptedKey
+
+		newHash, err := hashFunction(encryptedKey.HashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		salt, err := base64.StdEncoding.DecodeString(encryptedKey.SaltValue)
+		if err != nil {
+			return nil, err
+		}
+
+		// the password is hashed with the salt and rehashed for the number of spins
+		digest := hashOf(newHash, salt, utf16LittleEndian(password))
+
+		iterator := make([]byte, 4)
+
+		for spin := 0; spin < encryptedKey.SpinCount; spin++ {
+			binary.LittleEndian.PutUint32(iterator, uint32(spin))
+			digest = hashOf(newHash, iterator, digest)
+		}
+
+		decrypt := func(blockKey []byte, value string) ([]byte, error) {
+
+			encrypted, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
+
+			key := fitLength(hashOf(newHash, digest, blockKey), encryptedKey.KeyBits/8, 0x36)
+
+			return decryptCbc(key, fitLength(salt, encryptedKey.BlockSize, 0x36), encrypted)
+
+		}
+
+		verifierInput, err := decrypt(blockKeyVerifierInput, encryptedKey.EncryptedVerifierHashInput)
+		if err != nil {
+			return nil, err
+		}
+
+		verifierHash, err := decrypt(blockKeyVerifierHash, encryptedKey.EncryptedVerifierHashValue)
+		if err != nil {
+			return nil, err
+		}
+
+		// the hash of the verifier only matches if the password is correct
+		expectedHash := hashOf(newHash, fitLength(verifierInput, len(salt), 0))
+
+		if len(verifierHash) < encryptedKey.HashSize || bytes.Equal(expectedHash, verifierHash[:encryptedKey.HashSize]) == false {
+			return nil, errDocumentLocked
+		}
+
+		key, err := decrypt(blockKeyEncryptedKey, encryptedKey.EncryptedKeyValue)
+		if err != nil {
+			return nil, err
+		}
+
+		return fitLength(key, encryptedKey.KeyBits/8, 0), nil
+
+	}
+
+	return nil, errUnsupportedEncryption
+
+}
+
+// decrypt the encrypted package (the size of the package followed by the segments)
+func (info *agileEncryptionInfo) decryptPackage(key []byte, packageStream []byte) ([]byte, error) {
+
+	if len(packageStream) < 8 {
+		return nil, errors.New("invalid encrypted package")
+	}
+
+	size := binary.LittleEndian.Uint64(packageStream)
+	encrypted := packageStream[8:]
+
+	newHash, err := hashFunction(info.KeyData.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(info.KeyData.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := []byte{}
+	segmentIndex := make([]byte, 4)
+
+	for segment := 0; segment*encryptedSegmentSize < len(encrypted); segment++ {
+
+		end := (segment + 1) * encryptedSegmentSize
+		if end > len(encrypted) {
+			end = len(encrypted)
+		}
+
+		// every segment is encrypted with its own initialization vector
+		binary.LittleEndian.PutUint32(segmentIndex, uint32(segment))
+		iv := fitLength(hashOf(newHash, salt, segmentIndex), info.KeyData.BlockSize, 0x36)
+
+		plain, err := decryptCbc(key, iv, encrypted[segment*encryptedSegmentSize:end])
+		if err != nil {
+			return nil, err
+		}
+
+		decrypted = append(decrypted, plain...)
+
+	}
+
+	if uint64(len(decrypted)) < size {
+		return nil, errors.New("invalid encrypted package")
+	}
+
+	return decrypted[:size], nil
+
+}
+
+// get the hash function with the given name of the encryption info
+func hashFunction(name string) (func() hash.Hash, error) {
+
+	switch strings.ToUpper(name) {
+	case "SHA1", "SHA-1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+
+	return nil, errUnsupportedEncryption
+
+}
+
+// hash the concatenation of the given values
+func hashOf(newHash func() hash.Hash, values ...[]byte) []byte {
+
+	digest := newHash()
+
+	for _, value := range values {
+		digest.Write(value)
+	}
+
+	return digest.Sum(nil)
+
+}
+
+// truncate the value or pad it with the given byte to the given length
+func fitLength(value []byte, length int, padding byte) []byte {
+
+	if length <= 0 {
+		return []byte{}
+	}
+
+	if len(value) >= length {
+		return value[:length]
+	}
+
+	return append(append([]byte{}, value...), bytes.Repeat([]byte{padding}, length-len(value))...)
+
+}
+
+// decrypt the given data with aes in cbc mode
+func decryptCbc(key []byte, iv []byte, data []byte) ([]byte, error) {
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data)%block.BlockSize() != 0 || len(iv) != block.BlockSize() {
+		return nil, errors.New("invalid encrypted data")
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	return plain, nil
+
+}
+
+// encode the password as utf-16 (little endian) as required for the key derivation
+func utf16LittleEndian(text string) []byte {
+
+	encoded := []byte{}
+
+	for _, character := range utf16.Encode([]rune(text)) {
+		encoded = append(encoded, byte(character), byte(character>>8))
+	}
+
+	return encoded
+
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// the password of the encrypted fixture document
+const fixturePassword = "validate-links"
+
+// the encrypted fixture document (a word document with a single hyperlink)
+var encryptedFixture = filepath.Join("testdata", "encrypted", "protected.docx")
+
+// get fixed bytes for the salts and keys of the fixture (so the fixture does not
+// change whenever it is rewritten)
+func fixtureBytes(label string, length int) []byte {
+	digest := sha512.Sum512([]byte(label))
+	return digest[:length]
+}
+
+// encrypt the data with aes in cbc mode (the data is padded to the block size)
+func encryptCbc(t testing.TB, key []byte, iv []byte, data []byte) []byte {
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if remainder := len(data) % aes.BlockSize; remainder != 0 {
+		data = append(append([]byte{}, data...), make([]byte, aes.BlockSize-remainder)...)
+	}
+
+	encrypted := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, data)
+
+	return encrypted
+
+}
+
+// encrypt the zip archive of a document with the agile encryption of office
+// (aes-256 and sha-512) and return the encryption info and the encrypted package
+func encryptPackage(t testing.TB, plain []byte, password string, spinCount int) ([]byte, []byte) {
+
+	keyDataSalt := fixtureBytes("keyData", 16)
+	passwordSalt := fixtureBytes("password", 16)
+	packageKey := fixtureBytes("packageKey", 32)
+	verifier := fixtureBytes("verifier", 16)
+
+	newHash := sha512.New
+
+	// the same derivation as for the decryption, see intermediateKey
+	digest := hashOf(newHash, passwordSalt, utf16LittleEndian(password))
+	iterator := make([]byte, 4)
+
+	for spin := 0; spin < spinCount; spin++ {
+		binary.LittleEndian.PutUint32(iterator, uint32(spin))
+		digest = hashOf(newHash, iterator, digest)
+	}
+
+	encrypt := func(blockKey []byte, value []byte) string {
+		key := fitLength(hashOf(newHash, digest, blockKey), 32, 0x36)
+		return base64.StdEncoding.EncodeToString(encryptCbc(t, key, fitLength(passwordSalt, 16, 0x36), value))
+	}
+
+	info := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\r\n"+
+		`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<keyData saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"/>`+
+		`<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<p:encryptedKey spinCount="%d" saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" `+
+		`saltValue="%s" encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/></keyEncryptor></keyEncryptors></encryption>`,
+		base64.StdEncoding.EncodeToString(keyDataSalt), spinCount, base64.StdEncoding.EncodeToString(passwordSalt),
+		encrypt(blockKeyVerifierInput, verifier), encrypt(blockKeyVerifierHash, hashOf(newHash, verifier)), encrypt(blockKeyEncryptedKey, packageKey))
+
+	// version 4.4 (agile encryption) followed by the flags
+	infoStream := []byte{4, 0, 4, 0, 0x40, 0, 0, 0}
+	infoStream = append(infoStream, info...)
+
+	packageStream := make([]byte, 8)
+	binary.LittleEndian.PutUint64(packageStream, uint64(len(plain)))
+
+	segmentIndex := make([]byte, 4)
+
+	for segment := 0; segment*encryptedSegmentSize < len(plain); segment++ {
+
+		end := (segment + 1) * encryptedSegmentSize
+		if end > len(plain) {
+			end = len(plain)
+		}
+
+		binary.LittleEndian.PutUint32(segmentIndex, uint32(segment))
+		iv := fitLength(hashOf(newHash, keyDataSalt, segmentIndex), 16, 0x36)
+
+		packageStream = append(packageStream, encryptCbc(t, packageKey, iv, plain[segment*encryptedSegmentSize:end])...)
+
+	}
+
+	return infoStream, packageStream
+
+}
+
+// write a compound file (version 3, with sectors of 512 bytes) with the given
+// streams, the streams must be larger than the mini stream cutoff (4096 bytes), as
+// the mini stream is not written
+func writeCompoundFile(streams []string, contents [][]byte) []byte {
+
+	const sectorSize = 512
+
+	// the first sector holds the allocation table, the second the directory
+	fat := []uint32{0xFFFFFFFD, compoundEndOfChain}
+	sectors := [][]byte{nil, nil}
+	starts := []uint32{}
+
+	for _, content := range contents {
+
+		starts = append(starts, uint32(len(sectors)))
+
+		for offset := 0; offset < len(content); offset += sectorSize {
+
+			sector := make([]byte, sectorSize)
+			copy(sector, content[offset:])
+			sectors = append(sectors, sector)
+
+			fat = append(fat, uint32(len(sectors)))
+
+		}
+
+		fat[len(fat)-1] = compoundEndOfChain
+
+	}
+
+	fatSector := bytes.Repeat([]byte{0xFF}, sectorSize)
+	for index, next := range fat {
+		binary.LittleEndian.PutUint32(fatSector[index*4:], next)
+	}
+	sectors[0] = fatSector
+
+	entry := func(name string, entryType byte, start uint32, size uint64) []byte {
+
+		data := make([]byte, 128)
+		encoded := utf16.Encode([]rune(name))
+
+		for index, character := range encoded {
+			binary.LittleEndian.PutUint16(data[index*2:], character)
+		}
+
+		binary.LittleEndian.PutUint16(data[64:], uint16((len(encoded)+1)*2))
+		data[66] = entryType
+		for _, offset := range []int{68, 72, 76} {
+			binary.LittleEndian.PutUint32(data[offset:], compoundFreeSector)
+		}
+		binary.LittleEndian.PutUint32(data[116:], start)
+		binary.LittleEndian.PutUint64(data[120:], size)
+
+		return data
+
+	}
+
+	directory := entry("Root Entry", 5, compoundEndOfChain, 0)
+	binary.LittleEndian.PutUint32(directory[76:], 1)
+
+	for index, name := range streams {
+
+		streamEntry := entry(name, 2, starts[index], uint64(len(contents[index])))
+
+		// the streams are linked as right siblings of each other
+		if index < len(streams)-1 {
+			binary.LittleEndian.PutUint32(streamEntry[72:], uint32(index+2))
+		}
+
+		directory = append(directory, streamEntry...)
+
+	}
+
+	sectors[1] = append(directory, make([]byte, sectorSize-len(directory))...)
+
+	header := make([]byte, sectorSize)
+	copy(header, compoundFileSignature)
+	binary.LittleEndian.PutUint16(header[0x18:], 0x3E)
+	binary.LittleEndian.PutUint16(header[0x1A:], 3)
+	binary.LittleEndian.PutUint16(header[0x1C:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[0x1E:], 9)
+	binary.LittleEndian.PutUint16(header[0x20:], 6)
+	binary.LittleEndian.PutUint32(header[0x2C:], 1)
+	binary.LittleEndian.PutUint32(header[0x30:], 1)
+	binary.LittleEndian.PutUint32(header[0x38:], 4096)
+	binary.LittleEndian.PutUint32(header[0x3C:], compoundEndOfChain)
+	binary.LittleEndian.PutUint32(header[0x44:], compoundEndOfChain)
+	for index := 0; index < 109; index++ {
+		binary.LittleEndian.PutUint32(header[0x4C+index*4:], compoundFreeSector)
+	}
+	binary.LittleEndian.PutUint32(header[0x4C:], 0)
+
+	return append(header, bytes.Join(sectors, nil)...)
+
+}
+
+// write the encrypted fixture document
+func writeEncryptedFixture(t testing.TB) {
+
+	plainPath := filepath.Join(t.TempDir(), "plain.docx")
+
+	err := writeWordDocument(plainPath, []string{"https://example.com/protected"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := os.ReadFile(plainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infoStream, packageStream := encryptPackage(t, plain, fixturePassword, 100000)
+
+	// both streams are stored in regular sectors (the xml may be followed by white
+	// space, the encrypted package by further blocks beyond its size)
+	if len(infoStream) < 4096 {
+		infoStream = append(infoStream, bytes.Repeat([]byte(" "), 4096-len(infoStream))...)
+	}
+
+	for len(packageStream) < 4096+8 {
+		packageStream = append(packageStream, make([]byte, aes.BlockSize)...)
+	}
+
+	err = os.MkdirAll(filepath.Dir(encryptedFixture), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(encryptedFixture, writeCompoundFile([]string{"EncryptionInfo", "EncryptedPackage"}, [][]byte{infoStream, packageStream}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+// use the given passwords for a single test
+func usePasswords(t *testing.T, passwords ...string) {
+
+	previousConfig := config
+	t.Cleanup(func() { config = previousConfig })
+
+	config.Passwords = passwords
+	config.PasswordFile = ""
+
+}
+
+// check that the hyperlinks of the encrypted fixture are found with the password
+// (and the document is reported as locked without it)
+func TestEncryptedDocument(t *testing.T) {
+
+	if *update {
+		writeEncryptedFixture(t)
+	}
+
+	usePasswords(t, "wrong", fixturePassword)
+
+	document := Document{Path: encryptedFixture, Type: ".docx"}
+	links, _ := extractHyperlinksFromDocument(&document)
+
+	if document.Protection != protectionDecrypted || len(links) != 1 || links[0].Url != "https://example.com/protected" {
+		t.Errorf("the encrypted document was not decrypted: %s %+v", document.Protection, links)
+	}
+
+	usePasswords(t, "wrong")
+
+	document = Document{Path: encryptedFixture, Type: ".docx"}
+	extractHyperlinksFromDocument(&document)
+
+	if document.Protection != protectionLocked {
+		t.Errorf("the document without its password is %q", document.Protection)
+	}
+
+}
+
+// check that damaged compound files are rejected instead of stopping (or stalling)
+// the run
+func TestCorruptCompoundFile(t *testing.T) {
+
+	data, err := os.ReadFile(encryptedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usePasswords(t, fixturePassword)
+
+	// change a copy of the fixture at the given offset
+	corrupt := func(offset int, value uint32, length int) []byte {
+
+		changed := append([]byte{}, data...)
+
+		if length == 2 {
+			binary.LittleEndian.PutUint16(changed[offset:], uint16(value))
+		} else {
+			binary.LittleEndian.PutUint32(changed[offset:], value)
+		}
+
+		return changed
+
+	}
+
+	// the allocation table is stored in the first sector after the header
+	fatOffset := 512
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"truncated header", data[:100]},
+		{"truncated sectors", data[:1500]},
+		{"truncated stream", data[:len(data)-600]},
+		{"sector shift", corrupt(0x1E, 10, 2)},
+		{"large sector shift", corrupt(0x1E, 64, 2)},
+		{"mini sector shift", corrupt(0x20, 64, 2)},
+		{"directory outside of the file", corrupt(0x30, 100000, 4)},
+		{"cyclic directory chain", corrupt(fatOffset+4, 1, 4)},
+		{"cyclic stream chain", corrupt(fatOffset+3*4, 2, 4)},
+		{"stream larger than the file", corrupt(512*2+128*2+120, 0x7FFFFFFF, 4)},
+		{"mini stream", corrupt(512*2+128*2+120, 100, 4)},
+	}
+
+	for _, test := range tests {
+
+		decrypted, err := decryptDocument(test.data)
+		if err == nil {
+			t.Errorf("%s: %d bytes were decrypted from a damaged file", test.name, len(decrypted))
+		}
+
+	}
+
+}
+
+// check that cyclic chains of mini sectors end
+func TestCyclicMiniChain(t *testing.T) {
+
+	file := &CompoundFile{miniSectorSize: 64, miniFat: []uint32{1, 0}, miniStream: make([]byte, 128)}
+
+	_, err := file.miniChain(0, 1<<40)
+	if err == nil {
+		t.Error("the cyclic mini sector chain was read")
+	}
+
+}
+
+// check that encryption infos with invalid sizes or too many spins are rejected
+func TestValidateEncryptionInfo(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		blockSize int
+		keyBits   int
+		hashSize  int
+		spinCount int
+		isValid   bool
+	}{
+		{"office", 16, 256, 64, 100000, true},
+		{"aes-128", 16, 128, 20, 0, true},
+		{"negative block size", -16, 256, 64, 100000, false},
+		{"zero key bits", 16, 0, 64, 100000, false},
+		{"negative key bits", 16, -256, 64, 100000, false},
+		{"zero hash size", 16, 256, 0, 100000, false},
+		{"negative spin count", 16, 256, 64, -1, false},
+		{"too many spins", 16, 256, 64, maxSpinCount + 1, false},
+	}
+
+	for _, test := range tests {
+
+		keyEncryptor := agileKeyEncryptor{Uri: "http://schemas.microsoft.com/office/2006/keyEncryptor/password"}
+		keyEncryptor.EncryptedKey.BlockSize = test.blockSize
+		keyEncryptor.EncryptedKey.KeyBits = test.keyBits
+		keyEncryptor.EncryptedKey.HashSize = test.hashSize
+		keyEncryptor.EncryptedKey.SpinCount = test.spinCount
+
+		info := agileEncryptionInfo{KeyEncryptors: []agileKeyEncryptor{keyEncryptor}}
+		info.KeyData.BlockSize = 16
+		info.KeyData.KeyBits = 256
+
+		if err := info.validate(); (err == nil) != test.isValid {
+			t.Errorf("%s: validate = %v", test.name, err)
+		}
+
+	}
+
+}
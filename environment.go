@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// define the file system access needed by the validation, so that documents can
+// also be read from other sources than the local disk (i.e. in-memory fixtures)
+type FileSystem interface {
+	Walk(root string, walkFunc filepath.WalkFunc) error
+	Open(path string) (File, error)
+	Stat(path string) (os.FileInfo, error)
+}
+
+// define the operations needed on a document file (satisfied by *os.File)
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+	Stat() (os.FileInfo, error)
+}
+
+// define the default file system accessing the local disk
+type osFileSystem struct{}
+
+// walk the directory with the extended length prefix (on windows), so documents
+// deep within a share are found too, but report the paths as given
+func (osFileSystem) Walk(root string, walkFunc filepath.WalkFunc) error {
+
+	root = normalizeRoot(root)
+	extendedRoot := extendedLengthPath(root)
+
+	if extendedRoot == root {
+		return filepath.Walk(root, walkFunc)
+	}
+
+	return filepath.Walk(extendedRoot, func(path string, fileInfo os.FileInfo, err error) error {
+		return walkFunc(filepath.Join(root, strings.TrimPrefix(path, extendedRoot)), fileInfo, err)
+	})
+
+}
+
+func (osFileSystem) Open(path string) (File, error) {
+	return os.Open(longPath(path))
+}
+
+func (osFileSystem) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(longPath(path))
+}
+
+// the file system used to find and read documents
+var fileSystem FileSystem = osFileSystem{}
+
+// the clock used for all timestamps in the report
+var clock func() time.Time = time.Now
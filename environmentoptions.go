@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"strings"
+)
+
+// the prefix of the environment variables setting options (i.e. VALIDATE_LINKS_CONCURRENCY)
+const environmentPrefix = "VALIDATE_LINKS_"
+
+// the options set through environment variables (by name of the flag)
+var environmentOptions = map[string]bool{}
+
+// get the name of the environment variable setting the given option
+func environmentName(option string) string {
+	return environmentPrefix + strings.ToUpper(strings.Replace(option, "-", "_", -1))
+}
+
+// set the options given as environment variables (flags given on the command
+// line are parsed afterwards and take precedence)
+func applyEnvironmentOptions(flags *flag.FlagSet) error {
+
+	var err error
+
+	flags.VisitAll(func(option *flag.Flag) {
+
+		value, exists := os.LookupEnv(environmentName(option.Name))
+		if exists == false || err != nil {
+			return
+		}
+
+		// setting the value directly keeps the flag unset (to tell it from the command line)
+		err = option.Value.Set(value)
+		if err != nil {
+			err = errors.New("invalid value for " + environmentName(option.Name) + ": " + err.Error())
+			return
+		}
+
+		environmentOptions[option.Name] = true
+
+	})
+
+	return err
+
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// write the evidence of a broken link (the time of the check, the error or the
+// status, the headers and the beginning of the content of the response) to a file
+// in the evidence directory, so there is proof of what we saw
+func (link *Hyperlink) captureEvidence(documentPath string, response *CheckResponse, checkError error) {
+
+	err := os.MkdirAll(options.EvidenceDirectory, 0755)
+	if err != nil {
+		log.Println("ERROR: could not create the evidence directory")
+		return
+	}
+
+	// every link of every document has its own evidence file
+	digest := sha1.Sum([]byte(documentPath + "\x00" + link.Url))
+	path := filepath.Join(options.EvidenceDirectory, hex.EncodeToString(digest[:8])+".txt")
+
+	var evidence strings.Builder
+
+	fmt.Fprintf(&evidence, "Document: %s\n", documentPath)
+	fmt.Fprintf(&evidence, "Url: %s\n", redactUrl(link.Url))
+	fmt.Fprintf(&evidence, "Requested: %s\n", redactUrl(link.RequestUrl))
+	fmt.Fprintf(&evidence, "Checked: %s\n", clock().Format("2006-01-02T15:04:05Z07:00"))
+	fmt.Fprintf(&evidence, "Duration: %s\n", link.Duration)
+
+	if checkError != nil {
+		fmt.Fprintf(&evidence, "Error: %s\n", redactText(checkError.Error()))
+	}
+
+	if response != nil {
+
+		fmt.Fprintf(&evidence, "Status: %d\n\n", response.StatusCode)
+
+		names := []string{}
+		for name := range response.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			for _, value := range response.Header[name] {
+				fmt.Fprintf(&evidence, "%s: %s\n", name, redactText(value))
+			}
+		}
+
+		fmt.Fprintf(&evidence, "\n%s\n", response.Body)
+
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Println("ERROR: could not write the evidence of " + redactUrl(link.Url))
+		return
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(evidence.String())
+	if err != nil {
+		log.Println("ERROR: could not write the evidence of " + redactUrl(link.Url))
+		return
+	}
+
+	link.EvidencePath = getAbsoluteFilePath(path)
+
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// check the link against all expiration policies and add a warning if it
+// references a resource past (or near) its policy expiry
+func (link *Hyperlink) checkExpiration() {
+
+	now := clock()
+	horizon := now.AddDate(0, 0, config.ExpiryWarningDays)
+
+	for _, policy := range config.Expirations {
+
+		if policy.matcher.MatchString(link.Url) == false {
+			continue
+		}
+
+		warning := ""
+
+		if now.After(policy.expires) {
+			warning = "past policy expiry (" + policy.Expires + ")"
+		} else if horizon.After(policy.expires) {
+			days := int(policy.expires.Sub(now) / (24 * time.Hour))
+			warning = fmt.Sprintf("policy expiry in %d days (%s)", days, policy.Expires)
+		} else {
+			continue
+		}
+
+		if policy.Reason != "" {
+			warning = warning + ": " + policy.Reason
+		}
+
+		link.Warnings = append(link.Warnings, warning)
+
+	}
+
+}
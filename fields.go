@@ -0,0 +1,238 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"log"
+	"strings"
+)
+
+// extract the targets of all HYPERLINK fields found in the given document part
+func readFieldHyperlinks(file *zip.File) []Hyperlink {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the fields of " + file.Name)
+		return []Hyperlink{}
+	}
+	defer fileContentReader.Close()
+
+	instructions, err := decodeFieldInstructions(fileContentReader)
+	if err != nil {
+		log.Println("ERROR: could not read the fields of " + file.Name)
+	}
+
+	links := []Hyperlink{}
+
+	for _, instruction := range instructions {
+
+		target, tooltip, isHyperlink := parseHyperlinkInstruction(instruction)
+		if isHyperlink == false {
+			continue
+		}
+
+		// fields jumping to a bookmark of the document are checked against its bookmarks
+		if strings.HasPrefix(target, "#") {
+			links = append(links, Hyperlink{Url: target, Category: categoryAnchor, Tooltip: tooltip})
+			continue
+		}
+
+		links = append(links, Hyperlink{Url: target, Category: categoryFieldHyperlink, IsExternal: true, Tooltip: tooltip})
+
+	}
+
+	return links
+
+}
+
+// decode the instructions of all fields in the document body. complex fields are
+// spread over several runs, i.e. <w:fldChar w:fldCharType="begin"/> followed by
+// any number of <w:instrText> elements until the separate or end character, and
+// may contain nested fields
+func decodeFieldInstructions(reader io.Reader) ([]string, error) {
+
+	instructions := []string{}
+
+	// the instructions of the currently open (nested) fields
+	openFields := []*strings.Builder{}
+
+	// only the text of instruction elements is part of the field code
+	isInstructionText := false
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return instructions, nil
+		}
+		if err != nil {
+			return instructions, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+
+			switch element.Name.Local {
+
+			case "fldSimple":
+				// simple fields carry the whole instruction as attribute
+				instructions = append(instructions, attributeValue(element, "instr"))
+
+			case "fldChar":
+
+				switch attributeValue(element, "fldCharType") {
+
+				case "begin":
+					openFields = append(openFields, &strings.Builder{})
+
+				case "separate", "end":
+
+					if len(openFields) == 0 {
+						continue
+					}
+
+					// the instruction is complete once the result or the end of the field starts
+					current := openFields[len(openFields)-1]
+					if current != nil {
+						instructions = append(instructions, current.String())
+						openFields[len(openFields)-1] = nil
+					}
+
+					if attributeValue(element, "fldCharType") == "end" {
+						openFields = openFields[:len(openFields)-1]
+					}
+
+				}
+
+			case "instrText":
+				isInstructionText = true
+
+			}
+
+		case xml.EndElement:
+
+			if element.Name.Local == "instrText" {
+				isInstructionText = false
+			}
+
+		case xml.CharData:
+
+			if isInstructionText && len(openFields) > 0 && openFields[len(openFields)-1] != nil && openFields[len(openFields)-1].Len() < maxTextLength {
+				openFields[len(openFields)-1].Write(element)
+			}
+
+		}
+
+	}
+
+}
+
+// get the value of the attribute with the given local name
+func attributeValue(element xml.StartElement, name string) string {
+
+	for _, attribute := range element.Attr {
+		if attribute.Name.Local == name {
+			return attribute.Value
+		}
+	}
+
+	return ""
+
+}
+
+// parse a field instruction like HYPERLINK "http://example.com" \o "tooltip" and
+// return the target and the tooltip of the hyperlink (the target of hyperlinks
+// to a bookmark of the same document is the bookmark prefixed with #)
+func parseHyperlinkInstruction(instruction string) (string, string, bool) {
+
+	arguments := splitFieldArguments(instruction)
+
+	if len(arguments) < 2 || strings.EqualFold(arguments[0], "HYPERLINK") == false {
+		return "", "", false
+	}
+
+	target := ""
+	tooltip := ""
+	anchor := ""
+
+	for index := 1; index < len(arguments); index++ {
+
+		// skip all switches and their arguments (i.e. \l "bookmark"), except for
+		// the tooltip given with \o "tooltip"
+		if strings.HasPrefix(arguments[index], `\`) {
+			switch strings.ToLower(arguments[index]) {
+			case `\o`:
+				if index+1 < len(arguments) {
+					tooltip = arguments[index+1]
+				}
+				index++
+			case `\l`:
+				if index+1 < len(arguments) {
+					anchor = arguments[index+1]
+				}
+				index++
+			case `\t`:
+				index++
+			}
+			continue
+		}
+
+		if target == "" {
+			target = arguments[index]
+		}
+
+	}
+
+	// hyperlinks to bookmarks in the same document do not have a target
+	if target == "" && anchor != "" {
+		target = "#" + anchor
+	}
+
+	return target, tooltip, target != ""
+
+}
+
+// split the instruction of a field into its arguments, respecting quoted arguments
+func splitFieldArguments(instruction string) []string {
+
+	arguments := []string{}
+
+	var current strings.Builder
+	isQuoted := false
+	hasArgument := false
+
+	for _, character := range instruction {
+
+		switch {
+
+		case character == '"':
+			isQuoted = !isQuoted
+			hasArgument = true
+
+		case (character == ' ' || character == '\t') && isQuoted == false:
+			if hasArgument {
+				arguments = append(arguments, current.String())
+				current.Reset()
+				hasArgument = false
+			}
+
+		default:
+			current.WriteRune(character)
+			hasArgument = true
+
+		}
+
+	}
+
+	if hasArgument {
+		arguments = append(arguments, current.String())
+	}
+
+	return arguments
+
+}
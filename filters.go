@@ -0,0 +1,333 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// define the interface of filters excluding hyperlinks from the validation
+type Filter interface {
+	Name() string
+	// return if the link should be skipped and the reason for it
+	Skips(link *Hyperlink) (bool, string)
+}
+
+// define the structure of a filter in the configuration file
+type FilterConfig struct {
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+}
+
+// define the types of filters that can be used in the configuration
+var filterTypes = map[string]func(values []string) (Filter, error){
+	"mailto":    newMailtoFilter,
+	"microsoft": newMicrosoftFilter,
+	"schemes":   newSchemeFilter,
+	"domains":   newDomainFilter,
+	"regex":     newRegexFilter,
+}
+
+// the filters used if the configuration does not define any
+var defaultFilters = []FilterConfig{{Type: "microsoft"}, {Type: "mailto"}}
+
+// the filters that are applied (in order) to all hyperlinks found
+var filterChain []Filter
+
+// initialize the filter chain from the configuration
+func initializeFilters() error {
+
+	filterConfigs := config.Filters
+	if filterConfigs == nil {
+		filterConfigs = defaultFilters
+	}
+
+	chain, err := newFilterChain(filterConfigs)
+	if err != nil {
+		return err
+	}
+
+	filterChain = chain
+
+	return nil
+
+}
+
+// create the chain of filters given (i.e. in the configuration or a policy file)
+func newFilterChain(filterConfigs []FilterConfig) ([]Filter, error) {
+
+	chain := []Filter{}
+
+	for _, filterConfig := range filterConfigs {
+
+		newFilter, exists := filterTypes[filterConfig.Type]
+		if exists == false {
+			return nil, errors.New("unknown filter type " + filterConfig.Type)
+		}
+
+		filter, err := newFilter(filterConfig.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, filter)
+
+	}
+
+	return chain, nil
+
+}
+
+// find the first filter of the chain skipping the given link (nil if the link is checked)
+func findSkippingFilter(link *Hyperlink) (Filter, string) {
+	return findSkippingFilterOf(filterChain, link)
+}
+
+// find the first filter of the given chain skipping the link
+func findSkippingFilterOf(chain []Filter, link *Hyperlink) (Filter, string) {
+
+	for _, filter := range chain {
+		if skips, reason := filter.Skips(link); skips {
+			return filter, reason
+		}
+	}
+
+	return nil, ""
+
+}
+
+// remove all empty links, links annotated to be ignored and links skipped by
+// the given filter chain
+func filterHyperlinks(hyperlinks []Hyperlink, chain []Filter) []Hyperlink {
+
+	// initialize an empty slice of strings
+	var filteredLinks = []Hyperlink{}
+
+	// check all links
+	for index := range hyperlinks {
+
+		// there is nothing to check for empty links
+		if hyperlinks[index].Url == "" {
+			continue
+		}
+
+		// the author may exclude a single link with an annotation in its tooltip
+		if hyperlinks[index].isIgnored() {
+			continue
+		}
+
+		// the links outside of the scope of the options are checked in other runs
+		if isInScope(&hyperlinks[index]) == false {
+			continue
+		}
+
+		if filter, _ := findSkippingFilterOf(chain, &hyperlinks[index]); filter == nil {
+			filteredLinks = append(filteredLinks, hyperlinks[index])
+		}
+
+	}
+
+	return filteredLinks
+
+}
+
+// define a filter skipping all mailto links
+type mailtoFilter struct{}
+
+func newMailtoFilter(values []string) (Filter, error) {
+	return &mailtoFilter{}, nil
+}
+
+func (filter *mailtoFilter) Name() string {
+	return "mailto"
+}
+
+func (filter *mailtoFilter) Skips(link *Hyperlink) (bool, string) {
+
+	// the addresses are checked against the approved mail domains (if configured)
+	if len(config.MailDomains) > 0 {
+		return false, ""
+	}
+
+	return matchers["mailto"].MatchString(link.Url), "mail addresses are not checked"
+
+}
+
+// define a filter skipping the links to the microsoft office website inserted by
+// the office applications themselves
+type microsoftFilter struct{}
+
+func newMicrosoftFilter(values []string) (Filter, error) {
+	return &microsoftFilter{}, nil
+}
+
+func (filter *microsoftFilter) Name() string {
+	return "microsoft"
+}
+
+func (filter *microsoftFilter) Skips(link *Hyperlink) (bool, string) {
+	return matchers["microsoft"].MatchString(link.Url), "links to the office website are inserted by office itself"
+}
+
+// define a filter skipping all links with the given schemes (i.e. javascript or tel)
+type schemeFilter struct {
+	schemes map[string]bool
+}
+
+func newSchemeFilter(values []string) (Filter, error) {
+
+	filter := &schemeFilter{schemes: make(map[string]bool)}
+
+	for _, scheme := range values {
+		filter.schemes[strings.ToLower(strings.TrimSuffix(scheme, ":"))] = true
+	}
+
+	return filter, nil
+
+}
+
+func (filter *schemeFilter) Name() string {
+	return "schemes"
+}
+
+func (filter *schemeFilter) Skips(link *Hyperlink) (bool, string) {
+
+	separator := strings.Index(link.Url, ":")
+	if separator < 1 {
+		return false, ""
+	}
+
+	scheme := strings.ToLower(link.Url[:separator])
+
+	return filter.schemes[scheme], "the scheme " + scheme + " is excluded"
+
+}
+
+// define a filter skipping all links to the given domains (including their subdomains)
+type domainFilter struct {
+	domains []string
+}
+
+func newDomainFilter(values []string) (Filter, error) {
+
+	filter := &domainFilter{}
+
+	for _, domain := range values {
+		filter.domains = append(filter.domains, strings.ToLower(domain))
+	}
+
+	return filter, nil
+
+}
+
+func (filter *domainFilter) Name() string {
+	return "domains"
+}
+
+func (filter *domainFilter) Skips(link *Hyperlink) (bool, string) {
+
+	domain := urlDomain(link.Url)
+	if domain == "" {
+		return false, ""
+	}
+
+	for _, excluded := range filter.domains {
+		if domain == excluded || strings.HasSuffix(domain, "."+excluded) {
+			return true, "the domain " + excluded + " is excluded"
+		}
+	}
+
+	return false, ""
+
+}
+
+// define a filter skipping all links matching one of the given regular expressions
+type regexFilter struct {
+	patterns []*regexp.Regexp
+}
+
+func newRegexFilter(values []string) (Filter, error) {
+
+	filter := &regexFilter{}
+
+	for _, pattern := range values {
+
+		matcher, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.New("invalid filter pattern " + pattern)
+		}
+
+		filter.patterns = append(filter.patterns, matcher)
+
+	}
+
+	return filter, nil
+
+}
+
+func (filter *regexFilter) Name() string {
+	return "regex"
+}
+
+func (filter *regexFilter) Skips(link *Hyperlink) (bool, string) {
+
+	for _, pattern := range filter.patterns {
+		if pattern.MatchString(link.Url) {
+			return true, "the link matches " + pattern.String()
+		}
+	}
+
+	return false, ""
+
+}
+
+// explain for each url given whether it would be checked or which filter skips it
+func runTestFilters(arguments []string) {
+
+	flags := flag.NewFlagSet("test-filters", flag.ExitOnError)
+	configFile := flags.String("config", "", "configuration file (json)")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links test-filters [-config file] url [url ...]")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	initializeMatchers()
+
+	if *configFile != "" {
+		err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalln("ERROR: could not load the configuration:", err)
+		}
+	}
+
+	err := initializeFilters()
+	if err != nil {
+		log.Fatalln("ERROR: could not initialize the filters:", err)
+	}
+
+	for _, url := range flags.Args() {
+
+		link := Hyperlink{Url: url}
+		filter, reason := findSkippingFilter(&link)
+
+		if filter == nil {
+			fmt.Printf("%s\n  checked (passed %d filters)\n", url, len(filterChain))
+		} else {
+			fmt.Printf("%s\n  skipped by filter %s: %s\n", url, filter.Name(), reason)
+		}
+
+	}
+
+}
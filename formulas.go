@@ -0,0 +1,95 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"log"
+	"strings"
+)
+
+// extract the targets of all HYPERLINK() formulas found in the given worksheet
+func readFormulaHyperlinks(file *zip.File) []Hyperlink {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the formulas of " + file.Name)
+		return []Hyperlink{}
+	}
+	defer fileContentReader.Close()
+
+	formulas, err := decodeFormulas(fileContentReader)
+	if err != nil {
+		log.Println("ERROR: could not read the formulas of " + file.Name)
+	}
+
+	links := []Hyperlink{}
+
+	for _, formula := range formulas {
+
+		// a formula might contain several hyperlinks (i.e. in an IF statement)
+		for _, match := range matchers["hyperlinkFormula"].FindAllStringSubmatch(formula, -1) {
+
+			// quotes are escaped by doubling them within string literals
+			target := strings.Replace(match[1], `""`, `"`, -1)
+
+			links = append(links, Hyperlink{Url: target, Category: categoryFormulaHyperlink, IsExternal: true})
+
+		}
+
+	}
+
+	return links
+
+}
+
+// decode the formulas of all cells in the worksheet. only formulas with a literal
+// string as target can be checked, targets built from other cells are ignored
+func decodeFormulas(reader io.Reader) ([]string, error) {
+
+	formulas := []string{}
+
+	var current *strings.Builder
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return formulas, nil
+		}
+		if err != nil {
+			return formulas, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+			if element.Name.Local == "f" {
+				current = &strings.Builder{}
+			}
+
+		case xml.CharData:
+			if current != nil && current.Len() < maxTextLength {
+				current.Write(element)
+			}
+
+		case xml.EndElement:
+			if element.Name.Local == "f" && current != nil {
+
+				// cells sharing the formula of another cell have an empty element
+				if strings.Contains(strings.ToUpper(current.String()), "HYPERLINK") {
+					formulas = append(formulas, current.String())
+				}
+
+				current = nil
+
+			}
+
+		}
+
+	}
+
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// the marker identifying hooks written by install-hook (which may be overwritten)
+const hookMarker = "# installed by validate-links install-hook"
+
+// define the pre-commit hook validating the staged documents with fast settings, the
+// documents are validated as found in the working tree and the json reports are
+// written to a temporary directory (which is kept if there are broken links)
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `
+
+top=$(git rev-parse --show-toplevel) || exit 1
+
+IFS='
+'
+set --
+for file in $(git diff --cached --name-only --diff-filter=ACMR -- ':(icase)*.docx' ':(icase)*.dotx' ':(icase)*.pptx' ':(icase)*.potx' ':(icase)*.xlsx' ':(icase)*.xltx'); do
+	set -- "$@" "$top/$file"
+done
+unset IFS
+
+[ $# -eq 0 ] && exit 0
+
+reports=$(mktemp -d) || exit 1
+
+cd "$reports" && %s -format json -fail-on %s -check-retractions=false -concurrency 40 -max-duration %s "$@" > /dev/null
+status=$?
+
+if [ $status -ne 0 ]; then
+	echo "validate-links: the staged documents contain broken links (see the reports in $reports)" >&2
+else
+	rm -rf "$reports"
+fi
+
+exit $status
+`
+
+// write a git pre-commit hook validating the staged documents
+func runInstallHook(arguments []string) {
+
+	flags := flag.NewFlagSet("install-hook", flag.ExitOnError)
+	force := flags.Bool("force", false, "overwrite an existing pre-commit hook")
+	failOn := flags.String("fail-on", severityBroken, "reject the commit for broken links (broken) or broken links and warnings (warning)")
+	maxDuration := flags.String("max-duration", "2m", "maximum duration of the validation, the remaining links are not checked")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links install-hook [-force] [-fail-on severity] [-max-duration duration]")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	err := validateSeverity(*failOn)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	// the hooks directory might be configured with core.hooksPath
+	output, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		log.Fatalln("ERROR: not within a git repository")
+	}
+
+	hooksDirectory := strings.TrimSpace(string(output))
+	hookPath := filepath.Join(hooksDirectory, "pre-commit")
+
+	existing, err := ioutil.ReadFile(hookPath)
+	if err == nil && strings.Contains(string(existing), hookMarker) == false && *force == false {
+		log.Fatalln("ERROR: " + hookPath + " already exists (use -force to overwrite it)")
+	}
+
+	// the hook calls this very executable
+	executable, err := os.Executable()
+	if err != nil {
+		log.Fatalln("ERROR: could not find the path of validate-links")
+	}
+
+	err = os.MkdirAll(hooksDirectory, 0755)
+	if err != nil {
+		log.Fatalln("ERROR: could not create " + hooksDirectory)
+	}
+
+	hook := fmt.Sprintf(preCommitHook, shellQuote(executable), *failOn, shellQuote(*maxDuration))
+
+	err = ioutil.WriteFile(hookPath, []byte(hook), 0755)
+	if err != nil {
+		log.Fatalln("ERROR: could not write " + hookPath)
+	}
+
+	fmt.Println("installed the pre-commit hook " + hookPath)
+
+}
+
+// quote the value for the shell
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// define the types of the nodes of the link graph
+const (
+	nodeDocument = "document"
+	nodeDomain   = "domain"
+)
+
+// define a custom structure for the graph of documents and the domains and
+// other documents they link to
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// define a custom structure for a document or domain of the graph
+type GraphNode struct {
+	Id      string `json:"id"`
+	Label   string `json:"label"`
+	Type    string `json:"type"`
+	IsValid bool   `json:"valid"`
+}
+
+// define a custom structure for the links from a document to a node of the graph
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Links  int    `json:"links"`
+	Broken int    `json:"broken"`
+}
+
+// define the file extensions of the supported graph formats
+var graphFormats = map[string]string{
+	".dot":     "dot",
+	".gv":      "dot",
+	".graphml": "graphml",
+	".json":    "json",
+}
+
+// check the graph file given on the command line
+func validateGraphFile(path string) error {
+
+	if _, exists := graphFormats[strings.ToLower(filepath.Ext(path))]; exists == false {
+		return errors.New("unknown graph format of " + path + " (use .dot, .graphml or .json)")
+	}
+
+	return nil
+
+}
+
+// build the graph of all documents of the reports
+func buildGraph(reports []Report) Graph {
+
+	nodes := make(map[string]*GraphNode)
+	edges := make(map[[2]string]*GraphEdge)
+
+	addNode := func(id string, label string, nodeType string) *GraphNode {
+
+		node, exists := nodes[id]
+		if exists == false {
+			node = &GraphNode{Id: id, Label: label, Type: nodeType, IsValid: true}
+			nodes[id] = node
+		}
+
+		return node
+
+	}
+
+	for _, report := range reports {
+		for _, document := range report.Documents {
+
+			documentPath := getAbsoluteFilePath(document.Path)
+			addNode(documentPath, documentPath, nodeDocument).IsValid = document.IsValid
+
+			for _, link := range document.Hyperlinks {
+
+				target := ""
+
+				switch {
+				case link.ResolvedPath != "":
+					// only other documents are part of the graph (and not any local files)
+					if _, isDocument := documentTypes[strings.ToLower(filepath.Ext(link.ResolvedPath))]; isDocument {
+						target = addNode(link.ResolvedPath, link.ResolvedPath, nodeDocument).Id
+					}
+				case link.IsExternal:
+					if domain := urlDomain(link.Url); domain != "" {
+						target = addNode(nodeDomain+":"+domain, domain, nodeDomain).Id
+					}
+				}
+
+				if target == "" {
+					continue
+				}
+
+				key := [2]string{documentPath, target}
+
+				edge, exists := edges[key]
+				if exists == false {
+					edge = &GraphEdge{Source: documentPath, Target: target}
+					edges[key] = edge
+				}
+
+				edge.Links++
+
+				if link.IsWorking == false && link.NotChecked == false {
+					edge.Broken++
+					nodes[target].IsValid = false
+				}
+
+			}
+
+		}
+	}
+
+	graph := Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, *edge)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Id < graph.Nodes[j].Id
+	})
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Source != graph.Edges[j].Source {
+			return graph.Edges[i].Source < graph.Edges[j].Source
+		}
+		return graph.Edges[i].Target < graph.Edges[j].Target
+	})
+
+	return graph
+
+}
+
+// write the graph of the reports to the given file (in the format given by the
+// extension of the file)
+func writeGraph(reports []Report, path string) bool {
+
+	graph := buildGraph(reports)
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Println("ERROR: could not create the graph file")
+		return false
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	switch graphFormats[strings.ToLower(filepath.Ext(path))] {
+	case "dot":
+		err = graph.writeDot(writer)
+	case "graphml":
+		err = graph.writeGraphml(writer)
+	default:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(graph)
+	}
+
+	if err == nil {
+		err = writer.Flush()
+	}
+
+	if err != nil {
+		log.Println("ERROR: could not write the graph file")
+		return false
+	}
+
+	return true
+
+}
+
+// write the graph in the dot language of graphviz (documents are drawn as boxes,
+// domains as ellipses and nodes with broken links in red)
+func (graph *Graph) writeDot(output io.Writer) error {
+
+	quote := func(value string) string {
+		return `"` + strings.Replace(strings.Replace(value, `\`, `\\`, -1), `"`, `\"`, -1) + `"`
+	}
+
+	fmt.Fprintln(output, "digraph links {")
+
+	for _, node := range graph.Nodes {
+
+		shape := "ellipse"
+		if node.Type == nodeDocument {
+			shape = "box"
+		}
+
+		color := "black"
+		if node.IsValid == false {
+			color = "red"
+		}
+
+		fmt.Fprintf(output, "  %s [label=%s, shape=%s, color=%s];\n", quote(node.Id), quote(node.Label), shape, color)
+
+	}
+
+	for _, edge := range graph.Edges {
+
+		color := "black"
+		if edge.Broken > 0 {
+			color = "red"
+		}
+
+		fmt.Fprintf(output, "  %s -> %s [label=\"%d\", color=%s];\n", quote(edge.Source), quote(edge.Target), edge.Links, color)
+
+	}
+
+	_, err := fmt.Fprintln(output, "}")
+
+	return err
+
+}
+
+// define the structures of the graphml format
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   struct {
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphmlNode `xml:"node"`
+		Edges       []graphmlEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphmlKey struct {
+	Id       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	Name     string `xml:"attr.name,attr"`
+	DataType string `xml:"attr.type,attr"`
+}
+
+type graphmlNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// write the graph in the graphml format (i.e. for gephi or yed)
+func (graph *Graph) writeGraphml(output io.Writer) error {
+
+	document := graphmlDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{Id: "label", For: "node", Name: "label", DataType: "string"},
+			{Id: "type", For: "node", Name: "type", DataType: "string"},
+			{Id: "valid", For: "node", Name: "valid", DataType: "boolean"},
+			{Id: "links", For: "edge", Name: "links", DataType: "int"},
+			{Id: "broken", For: "edge", Name: "broken", DataType: "int"},
+		},
+	}
+
+	document.Graph.EdgeDefault = "directed"
+
+	for _, node := range graph.Nodes {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphmlNode{Id: node.Id, Data: []graphmlData{
+			{Key: "label", Value: node.Label},
+			{Key: "type", Value: node.Type},
+			{Key: "valid", Value: fmt.Sprint(node.IsValid)},
+		}})
+	}
+
+	for _, edge := range graph.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphmlEdge{Source: edge.Source, Target: edge.Target, Data: []graphmlData{
+			{Key: "links", Value: fmt.Sprint(edge.Links)},
+			{Key: "broken", Value: fmt.Sprint(edge.Broken)},
+		}})
+	}
+
+	_, err := io.WriteString(output, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(output)
+	encoder.Indent("", "  ")
+
+	return encoder.Encode(document)
+
+}
@@ -0,0 +1,568 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// define a custom gui structure holding the state of the web interface
+type Gui struct {
+	mutex       sync.Mutex
+	isRunning   bool
+	report      *Report
+	subscribers map[chan ProgressEvent]bool
+	// the token of the session, only known to the page served by us (other web
+	// sites opened in the browser cannot use the api without it)
+	token string
+	// the address the web interface is listening on
+	address string
+}
+
+// serve a small local web interface to select a directory and run the validation
+func serveGui() {
+
+	token := make([]byte, 16)
+	rand.Read(token)
+
+	gui := &Gui{subscribers: make(map[chan ProgressEvent]bool), token: hex.EncodeToString(token)}
+
+	// hand all progress events to the connected browsers
+	if progress == nil {
+		progress = &Progress{}
+	}
+	progress.listener = gui.broadcast
+
+	// expose the health of the documentation to the monitoring
+	metrics = newMetrics()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", gui.handleIndex)
+	mux.HandleFunc("/api/directories", gui.requireSession(gui.handleDirectories))
+	mux.HandleFunc("/api/run", gui.requireSession(gui.handleRun))
+	mux.HandleFunc("/api/events", gui.requireSession(gui.handleEvents))
+	mux.HandleFunc("/api/results", gui.requireSession(gui.handleResults))
+	mux.HandleFunc("/metrics", handleMetrics)
+
+	if options.Pprof {
+		registerProfiling(mux)
+	}
+
+	// listen on the local interface only (the ui is not meant to be shared)
+	listener, err := net.Listen("tcp", options.GuiAddress)
+	if err != nil {
+		log.Println("ERROR: could not start the web interface:", err)
+		return
+	}
+
+	gui.address = listener.Addr().String()
+	address := "http://" + gui.address + "/"
+
+	fmt.Println("Web interface is available at " + address)
+
+	// open the interface in the browser (unless we are running on a server)
+	if options.Open {
+		err = openInBrowser(address)
+		if err != nil {
+			log.Println("Could not open web interface")
+		}
+	}
+
+	err = http.Serve(listener, mux)
+	if err != nil {
+		log.Println("ERROR: web interface stopped:", err)
+	}
+
+}
+
+// send the progress event to all connected browsers
+func (gui *Gui) broadcast(event ProgressEvent) {
+
+	gui.mutex.Lock()
+	defer gui.mutex.Unlock()
+
+	for subscriber := range gui.subscribers {
+
+		// never block the validation because of a slow browser
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+
+}
+
+// serve the page of the web interface
+func (gui *Gui) handleIndex(w http.ResponseWriter, r *http.Request) {
+
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	tmpl, err := template.New("gui").Parse(guiTemplate)
+	if err != nil {
+		http.Error(w, "Could not load template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl.Execute(w, map[string]string{"Token": gui.token})
+
+}
+
+// define a custom directory listing structure used by the directory picker
+type DirectoryListing struct {
+	Path        string
+	Parent      string
+	Directories []string
+}
+
+// list the sub directories of the given path
+func (gui *Gui) handleDirectories(w http.ResponseWriter, r *http.Request) {
+
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		path = "."
+	}
+
+	path = getAbsoluteFilePath(path)
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		http.Error(w, "Could not read directory", http.StatusBadRequest)
+		return
+	}
+
+	listing := DirectoryListing{Path: path, Parent: filepath.Dir(path), Directories: []string{}}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			listing.Directories = append(listing.Directories, entry.Name())
+		}
+	}
+
+	sort.Strings(listing.Directories)
+
+	writeJson(w, listing)
+
+}
+
+// start the validation of the given directory in the background
+func (gui *Gui) handleRun(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	directory := r.FormValue("directory")
+	if directory == "" {
+		http.Error(w, "No directory specified", http.StatusBadRequest)
+		return
+	}
+
+	// only one validation may run at a time
+	gui.mutex.Lock()
+	if gui.isRunning {
+		gui.mutex.Unlock()
+		http.Error(w, "Validation is already running", http.StatusConflict)
+		return
+	}
+	gui.isRunning = true
+	gui.report = nil
+	gui.mutex.Unlock()
+
+	go func() {
+
+		budget.start()
+		network.start()
+		report := validateDirectories([]string{directory})
+		metrics.runFinished(&report)
+		linkCache.save()
+
+		gui.mutex.Lock()
+		gui.report = &report
+		gui.isRunning = false
+		gui.mutex.Unlock()
+
+	}()
+
+	w.WriteHeader(http.StatusAccepted)
+
+}
+
+// only pass the requests sent by the page of the web interface to the handler
+func (gui *Gui) requireSession(handler http.HandlerFunc) http.HandlerFunc {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if gui.isTrustedRequest(r) == false {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+
+	}
+
+}
+
+// check that the request was sent by the page of the web interface and not by
+// another web site opened in the same browser (cross-site requests)
+func (gui *Gui) isTrustedRequest(r *http.Request) bool {
+
+	// the host is checked as well, otherwise a web site could point its own
+	// domain to our address (dns rebinding) and thereby become the same origin
+	if gui.isOwnHost(r.Host) == false {
+		return false
+	}
+
+	// browsers send the origin with all cross-origin and post requests
+	if origin := r.Header.Get("Origin"); origin != "" {
+
+		originUrl, err := url.Parse(origin)
+		if err != nil || originUrl.Host != r.Host {
+			return false
+		}
+
+	}
+
+	// event sources cannot send headers, they pass the token in the query
+	token := r.Header.Get("X-Session-Token")
+	if token == "" && r.Method == "GET" {
+		token = r.URL.Query().Get("token")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(gui.token)) == 1
+
+}
+
+// check if the given host of a request is the address we are listening on (by
+// its loopback ip or as localhost)
+func (gui *Gui) isOwnHost(host string) bool {
+
+	if host == gui.address {
+		return true
+	}
+
+	_, port, err := net.SplitHostPort(gui.address)
+	if err != nil {
+		return false
+	}
+
+	_, hostPort, err := net.SplitHostPort(host)
+	if err != nil || hostPort != port {
+		return false
+	}
+
+	return isLoopbackAddress(host)
+
+}
+
+// stream the progress events to the browser (as server-sent events)
+func (gui *Gui) handleEvents(w http.ResponseWriter, r *http.Request) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	subscriber := make(chan ProgressEvent, 256)
+
+	gui.mutex.Lock()
+	gui.subscribers[subscriber] = true
+	gui.mutex.Unlock()
+
+	defer func() {
+		gui.mutex.Lock()
+		delete(gui.subscribers, subscriber)
+		gui.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case event := <-subscriber:
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+}
+
+// return the results of the last validation
+func (gui *Gui) handleResults(w http.ResponseWriter, r *http.Request) {
+
+	gui.mutex.Lock()
+	report := gui.report
+	isRunning := gui.isRunning
+	gui.mutex.Unlock()
+
+	if report == nil {
+		status := "none"
+		if isRunning {
+			status = "running"
+		}
+		writeJson(w, map[string]string{"Status": status})
+		return
+	}
+
+	writeJson(w, report)
+
+}
+
+// write the given data as json response
+func writeJson(w http.ResponseWriter, data interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.Println("ERROR: could not write json response")
+	}
+
+}
+
+const guiTemplate = `<html>
+<head>
+<title>Check hyperlinks in docx, pptx and xlsx files</title>
+<meta charset="utf-8">
+
+<style type="text/css">
+
+* {
+font-family: "Helvetica Neue", "Helvetica", "Calibri", "Arial", sans-serif;
+font-weight: normal;
+font-size: 14px;
+}
+
+body {
+background-color: #eaeaea;
+}
+
+.container {
+min-width: 600px;
+margin: 40px 20px 20px 20px;
+padding: 20px;
+border: 1px solid #ccc;
+background-color: #fff;
+box-shadow: 0px 1px 1px rgba(74, 69, 69, 0.6), 0px -1px 1px rgba(50, 50, 50, 0.05);
+}
+
+h1 {
+margin: 0px;
+margin-bottom: 15px;
+font-size: 20px;
+border-bottom: 1px solid #ccc;
+font-weight: bold;
+padding-bottom: 10px;
+}
+
+section {
+margin-bottom: 30px;
+}
+
+input[type=text] {
+width: 60%;
+padding: 4px;
+}
+
+ul.directories {
+list-style-type: none;
+padding-left: 5px;
+max-height: 200px;
+overflow-y: auto;
+}
+
+ul.directories li {
+cursor: pointer;
+padding: 2px 0px;
+}
+
+ul.directories li:hover {
+text-decoration: underline;
+}
+
+table {
+width: 100%;
+border-collapse: collapse;
+}
+
+th, td {
+text-align: left;
+padding: 4px 8px;
+border-bottom: 1px solid #eee;
+font-size: 12px;
+}
+
+th {
+font-weight: bold;
+}
+
+.valid {
+color: #20d420;
+}
+
+.invalid {
+color: #db2d2d;
+}
+
+</style>
+</head>
+<body>
+<div class="container">
+
+<section>
+<h1>Directory</h1>
+<input type="text" id="directory" value=".">
+<button id="start">Start validation</button>
+<ul class="directories" id="directories"></ul>
+</section>
+
+<section>
+<h1>Progress</h1>
+<p id="progress">Not started</p>
+</section>
+
+<section>
+<h1>Results</h1>
+<p>
+<input type="text" id="filter" placeholder="Filter by document or link">
+<select id="status">
+<option value="all">All links</option>
+<option value="invalid">Broken links</option>
+<option value="valid">Working links</option>
+</select>
+</p>
+<table>
+<thead><tr><th>Document</th><th>Link</th><th>Status</th></tr></thead>
+<tbody id="results"></tbody>
+</table>
+</section>
+
+</div>
+
+<script>
+var token = {{.Token}};
+var rows = [];
+var documents = 0, links = 0, broken = 0;
+
+function listDirectories(path) {
+	fetch("/api/directories?path=" + encodeURIComponent(path), {headers: {"X-Session-Token": token}}).then(function(response) {
+		return response.json();
+	}).then(function(listing) {
+		document.getElementById("directory").value = listing.Path;
+		var list = document.getElementById("directories");
+		list.innerHTML = "";
+		var entries = [".."].concat(listing.Directories);
+		entries.forEach(function(name) {
+			var item = document.createElement("li");
+			item.textContent = name;
+			item.onclick = function() {
+				listDirectories(name == ".." ? listing.Parent : listing.Path + "/" + name);
+			};
+			list.appendChild(item);
+		});
+	});
+}
+
+function renderResults() {
+	document.getElementById("results").innerHTML = "";
+	rows.forEach(appendRow);
+}
+
+function appendRow(row) {
+	var filter = document.getElementById("filter").value.toLowerCase();
+	var status = document.getElementById("status").value;
+	if (status == "valid" && !row.isWorking) return;
+	if (status == "invalid" && (row.isWorking || row.notChecked)) return;
+	if (filter && (row.document + " " + row.url).toLowerCase().indexOf(filter) < 0) return;
+	var text = row.notChecked ? "not checked" : row.isWorking ? "working" : "broken";
+	if (row.reason) text += " (" + row.reason + ")";
+	var tr = document.createElement("tr");
+	[row.document, row.url, text].forEach(function(text) {
+		var td = document.createElement("td");
+		td.textContent = text;
+		tr.appendChild(td);
+	});
+	tr.className = row.notChecked ? "" : row.isWorking ? "valid" : "invalid";
+	document.getElementById("results").appendChild(tr);
+}
+
+function updateProgress(text) {
+	document.getElementById("progress").textContent = text + " (" + documents + " documents, " + links + " links checked, " + broken + " broken)";
+}
+
+var events = new EventSource("/api/events?token=" + encodeURIComponent(token));
+events.onmessage = function(message) {
+	var event = JSON.parse(message.data);
+	switch (event.event) {
+	case "run_started":
+		rows = []; documents = 0; links = 0; broken = 0;
+		renderResults();
+		updateProgress("Running");
+		break;
+	case "document_started":
+		updateProgress("Checking " + event.document);
+		break;
+	case "link_checked":
+		links++;
+		if (!event.isWorking && !event.notChecked) broken++;
+		var row = {document: event.document, url: event.url, isWorking: event.isWorking, notChecked: event.notChecked, reason: event.reason};
+		rows.push(row);
+		appendRow(row);
+		break;
+	case "document_finished":
+		documents++;
+		break;
+	case "run_finished":
+		updateProgress(event.isValid ? "Finished, all links are valid" : "Finished, there are some invalid links");
+		document.getElementById("start").disabled = false;
+		break;
+	}
+};
+
+document.getElementById("start").onclick = function() {
+	var data = new FormData();
+	data.append("directory", document.getElementById("directory").value);
+	document.getElementById("start").disabled = true;
+	fetch("/api/run", {method: "POST", body: data, headers: {"X-Session-Token": token}}).then(function(response) {
+		if (!response.ok) {
+			document.getElementById("start").disabled = false;
+			response.text().then(updateProgress);
+		}
+	});
+};
+
+document.getElementById("filter").oninput = renderResults;
+document.getElementById("status").onchange = renderResults;
+
+listDirectories(".");
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// check that only the page of the web interface can start a validation
+func TestGuiRunRequiresSession(t *testing.T) {
+
+	gui := &Gui{subscribers: make(map[chan ProgressEvent]bool), token: "session", address: "127.0.0.1:8080"}
+
+	tests := []struct {
+		name   string
+		host   string
+		origin string
+		token  string
+		status int
+	}{
+		{"page of the web interface", "127.0.0.1:8080", "http://127.0.0.1:8080", "session", http.StatusBadRequest},
+		{"page of the web interface on localhost", "localhost:8080", "http://localhost:8080", "session", http.StatusBadRequest},
+		{"without origin", "127.0.0.1:8080", "", "session", http.StatusBadRequest},
+		{"without token", "127.0.0.1:8080", "http://127.0.0.1:8080", "", http.StatusForbidden},
+		{"other token", "127.0.0.1:8080", "http://127.0.0.1:8080", "other", http.StatusForbidden},
+		{"other web site", "127.0.0.1:8080", "https://attacker.example.com", "session", http.StatusForbidden},
+		{"dns rebinding", "attacker.example.com:8080", "http://attacker.example.com:8080", "session", http.StatusForbidden},
+		{"other port", "127.0.0.1:9090", "http://127.0.0.1:9090", "session", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+
+		// without a directory, the accepted requests fail before a validation is started
+		request := httptest.NewRequest("POST", "http://"+test.host+"/api/run", strings.NewReader(url.Values{"directory": {""}}.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+
+		if test.token != "" {
+			request.Header.Set("X-Session-Token", test.token)
+		}
+
+		recorder := httptest.NewRecorder()
+		gui.requireSession(gui.handleRun)(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: status %d instead of %d", test.name, recorder.Code, test.status)
+		}
+
+	}
+
+}
+
+// check that the directories and the results are only handed to the page of the
+// web interface as well
+func TestGuiApiRequiresSession(t *testing.T) {
+
+	gui := &Gui{subscribers: make(map[chan ProgressEvent]bool), token: "session", address: "127.0.0.1:8080"}
+
+	tests := []struct {
+		name    string
+		target  string
+		handler http.HandlerFunc
+		header  string
+		status  int
+	}{
+		{"directories", "/api/directories?path=.", gui.handleDirectories, "session", http.StatusOK},
+		{"directories without token", "/api/directories?path=.", gui.handleDirectories, "", http.StatusForbidden},
+		{"results", "/api/results", gui.handleResults, "session", http.StatusOK},
+		{"results without token", "/api/results", gui.handleResults, "", http.StatusForbidden},
+		{"results with token in the query", "/api/results?token=session", gui.handleResults, "", http.StatusOK},
+		{"results with other token in the query", "/api/results?token=other", gui.handleResults, "", http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+
+		request := httptest.NewRequest("GET", "http://127.0.0.1:8080"+test.target, nil)
+
+		if test.header != "" {
+			request.Header.Set("X-Session-Token", test.header)
+		}
+
+		recorder := httptest.NewRecorder()
+		gui.requireSession(test.handler)(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: status %d instead of %d", test.name, recorder.Code, test.status)
+		}
+
+	}
+
+}
+
+// check that the progress events tell why a link was not checked
+func TestLinkCheckedEvent(t *testing.T) {
+
+	events := []ProgressEvent{}
+	stream := &Progress{listener: func(event ProgressEvent) { events = append(events, event) }}
+
+	document := &Document{Path: "handbook.docx"}
+	stream.linkChecked(document, &Hyperlink{Url: "https://example.com/", NotChecked: true, Reason: reasonNotChecked})
+
+	if len(events) != 1 || events[0].NotChecked == false || events[0].Reason != reasonNotChecked {
+		t.Errorf("unexpected progress events %+v", events)
+	}
+
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// the stream informative messages are printed to (standard error if the report
+// itself is written to standard output)
+var console io.Writer = os.Stdout
+
+// check that the report can be written to standard output with the given options
+func validateStdout() error {
+
+	if options.SplitAssets {
+		return errors.New("-stdout cannot be combined with -split-assets")
+	}
+
+	if options.OwnerReports {
+		return errors.New("-stdout cannot be combined with -owner-reports")
+	}
+
+	if signingKey != nil {
+		return errors.New("-stdout cannot be combined with -signing-key, the signature is written next to the results")
+	}
+
+	return nil
+
+}
+
+// write the report to standard output (as single html page or json)
+func (report *Report) writeStdout() bool {
+
+	if options.Format == "json" {
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		err := encoder.Encode(report)
+		if err != nil {
+			log.Println("Could not write the report data")
+			return false
+		}
+
+		return true
+
+	}
+
+	if options.Format == formatUrls {
+
+		err := report.renderUrls(os.Stdout)
+		if err != nil {
+			log.Println("Could not write the urls")
+			return false
+		}
+
+		return true
+
+	}
+
+	err := report.render(os.Stdout, false)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	return true
+
+}
+
+// stop checking further links on the first interrupt or termination signal, so
+// that the report of the links checked so far is still written (i.e. when a
+// container is stopped), and exit right away on the second signal. The signals
+// are handled explicitly, as the kernel ignores them for a process running as
+// pid 1 otherwise
+func handleSignals() {
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+
+		received := <-signals
+		log.Printf("Received %s, the remaining links are not checked (send again to exit immediately)\n", received)
+		budget.exhaust()
+
+		<-signals
+		os.Exit(130)
+
+	}()
+
+}
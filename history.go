@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"os"
+	"strings"
+)
+
+// define a custom structure with the summarized results of a directory
+type DirectoryStatistics struct {
+	Directory string
+	Documents int
+	Links     int
+	Anchors   int
+	Broken    int
+}
+
+// define a custom structure for the results of a directory in a previous run
+type HistoryEntry struct {
+	Date string
+	DirectoryStatistics
+}
+
+// define a custom structure with the results of the last runs of a directory
+type Trend struct {
+	Directory string
+	Entries   []HistoryEntry
+}
+
+// summarize the results of all documents found in the given directory
+func summarizeDirectory(directory string, documents []Document) DirectoryStatistics {
+
+	statistics := DirectoryStatistics{Directory: getAbsoluteFilePath(directory), Documents: len(documents)}
+
+	for _, document := range documents {
+		for _, link := range document.Hyperlinks {
+
+			statistics.Links++
+
+			if link.Category == categoryAnchor {
+				statistics.Anchors++
+			}
+
+			if link.IsWorking == false && link.NotChecked == false {
+				statistics.Broken++
+			}
+
+		}
+	}
+
+	return statistics
+
+}
+
+// append the results of this run to the history file and add the trend of the
+// last runs of every directory to the report
+func (report *Report) updateHistory(historyFile string, numberOfRuns int) {
+
+	history := readHistory(historyFile)
+
+	// open the history file for appending (one json object per line)
+	file, err := os.OpenFile(historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Println("ERROR: could not open the history file")
+		return
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+
+	for _, statistics := range report.Statistics {
+
+		entry := HistoryEntry{Date: report.Metadata.Started.Format(historyDateLayout), DirectoryStatistics: statistics}
+
+		err = encoder.Encode(entry)
+		if err != nil {
+			log.Println("ERROR: could not write to the history file")
+		}
+
+		history = append(history, entry)
+
+	}
+
+	// collect the last runs of every directory of this report
+	for _, statistics := range report.Statistics {
+
+		trend := Trend{Directory: statistics.Directory}
+
+		for _, entry := range history {
+			if entry.Directory == statistics.Directory {
+				trend.Entries = append(trend.Entries, entry)
+			}
+		}
+
+		if len(trend.Entries) > numberOfRuns {
+			trend.Entries = trend.Entries[len(trend.Entries)-numberOfRuns:]
+		}
+
+		report.Trends = append(report.Trends, trend)
+
+	}
+
+}
+
+// read all entries of the history file (a missing file is an empty history)
+func readHistory(historyFile string) []HistoryEntry {
+
+	history := []HistoryEntry{}
+
+	file, err := os.Open(historyFile)
+	if err != nil {
+		return history
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+
+		var entry HistoryEntry
+
+		err = json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			log.Println("ERROR: skipping invalid line in history file")
+			continue
+		}
+
+		history = append(history, entry)
+
+	}
+
+	return history
+
+}
+
+// draw a small svg bar chart of the broken links in the given trend
+func trendChart(trend Trend) template.HTML {
+
+	const barWidth = 30
+	const barGap = 10
+	const chartHeight = 100
+	const labelHeight = 30
+
+	maximum := 1
+
+	for _, entry := range trend.Entries {
+		if entry.Broken > maximum {
+			maximum = entry.Broken
+		}
+	}
+
+	width := len(trend.Entries) * (barWidth + barGap)
+
+	var svg strings.Builder
+
+	fmt.Fprintf(&svg, `<svg width="%d" height="%d" role="img" aria-label="Broken links in the last %d runs">`, width, chartHeight+labelHeight, len(trend.Entries))
+
+	for index, entry := range trend.Entries {
+
+		height := entry.Broken * (chartHeight - 15) / maximum
+		x := index * (barWidth + barGap)
+
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d"><title>%s: %d of %d links broken</title></rect>`,
+			x, chartHeight-height, barWidth, height, html.EscapeString(entry.Date), entry.Broken, entry.Links)
+
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle">%d</text>`, x+barWidth/2, chartHeight-height-3, entry.Broken)
+
+		// only show month and day (without year and time) below the bar
+		date := entry.Date
+		if len(date) >= 10 {
+			date = date[5:10]
+		}
+
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle">%s</text>`, x+barWidth/2, chartHeight+15, html.EscapeString(date))
+
+	}
+
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// define the warnings of hostnames that might impersonate other hosts
+const (
+	warningMixedScripts = "the hostname mixes scripts"
+	warningImpersonates = "the hostname looks like"
+)
+
+// the scripts whose letters can hardly be told from each other (i.e. the latin a
+// and the cyrillic а), hostnames mixing them are a common phishing technique
+var confusableScripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+	"Greek":    unicode.Greek,
+	"Armenian": unicode.Armenian,
+}
+
+// the letters of other scripts (and latin letters with diacritics) looking like
+// latin letters
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'һ': 'h', 'і': 'i', 'ї': 'i', 'ј': 'j', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'ь': 'b',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u',
+	'χ': 'x', 'ω': 'w', 'ս': 'u', 'օ': 'o', 'հ': 'h', 'ո': 'n', 'ց': 'g', 'ı': 'i', 'ł': 'l', 'ɡ': 'g', 'ɑ': 'a',
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a', 'ā': 'a', 'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'ē': 'e', 'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i', 'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o', 'ø': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u', 'ý': 'y', 'ÿ': 'y', 'ç': 'c', 'ñ': 'n', 'ś': 's', 'š': 's', 'ž': 'z',
+}
+
+// get the confusable scripts used by the letters of the label
+func labelScripts(label string) []string {
+
+	scripts := []string{}
+
+	for _, name := range []string{"Latin", "Cyrillic", "Greek", "Armenian"} {
+		for _, character := range label {
+			if unicode.Is(confusableScripts[name], character) {
+				scripts = append(scripts, name)
+				break
+			}
+		}
+	}
+
+	return scripts
+
+}
+
+// get the skeleton of the hostname (the latin letters it looks like)
+func hostnameSkeleton(hostname string) string {
+
+	return strings.Map(func(character rune) rune {
+		if replacement, exists := homoglyphs[character]; exists {
+			return replacement
+		}
+		return character
+	}, hostname)
+
+}
+
+// spell out the non-ascii characters of the hostname (i.e. ѕ is U+0455, Cyrillic)
+func spellOutHostname(hostname string) string {
+
+	characters := []string{}
+	seen := map[rune]bool{}
+
+	for _, character := range hostname {
+
+		if character <= unicode.MaxASCII || seen[character] {
+			continue
+		}
+
+		seen[character] = true
+
+		script := "other"
+		for name, table := range confusableScripts {
+			if unicode.Is(table, character) {
+				script = name
+			}
+		}
+
+		characters = append(characters, fmt.Sprintf("%c is U+%04X (%s)", character, character, script))
+
+	}
+
+	return strings.Join(characters, ", ")
+
+}
+
+// check if the hostname of the link mixes scripts or looks like one of the
+// protected domains of the configuration (i.e. the domain of our hospital)
+// without being it, the hostname is spelled out in the warning
+func (link *Hyperlink) checkHomoglyphs() {
+
+	encoded := urlDomain(link.RequestUrl)
+	if encoded == "" {
+		return
+	}
+
+	hostname := decodeHostname(encoded)
+
+	// only international domain names can contain letters of other scripts
+	if isAscii(hostname) {
+		return
+	}
+
+	spelledOut := fmt.Sprintf(" (%s is spelled %s: %s)", encoded, hostname, spellOutHostname(hostname))
+
+	for _, label := range strings.Split(hostname, ".") {
+		if scripts := labelScripts(label); len(scripts) > 1 {
+			link.Warnings = append(link.Warnings, warningMixedScripts+" "+strings.Join(scripts, " and ")+spelledOut)
+			break
+		}
+	}
+
+	skeleton := hostnameSkeleton(hostname)
+
+	for _, domain := range append(config.ProtectedDomains, config.IntranetDomains...) {
+
+		domain = strings.ToLower(domain)
+
+		if skeleton == domain || strings.HasSuffix(skeleton, "."+domain) {
+			link.Warnings = append(link.Warnings, warningImpersonates+" "+domain+spelledOut)
+			return
+		}
+
+	}
+
+}
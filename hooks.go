@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// define the results that can be configured for links with custom schemes
+const (
+	customSchemeOk   = "ok"
+	customSchemeWarn = "warn"
+)
+
+// define the reasons and warnings reported by the configured hooks
+const (
+	reasonCommandFailed = "validation command failed"
+	warningCustomScheme = "links with this scheme are not checked"
+)
+
+// the time an external validation command may take
+const commandTimeout = 30 * time.Second
+
+// define a custom structure for the handling of links with a custom scheme (i.e.
+// notes:// links to internal systems), links are either always accepted, always
+// reported with a warning or validated with an external command
+type CustomScheme struct {
+	Scheme  string `json:"scheme"`
+	Result  string `json:"result"`
+	Command string `json:"command"`
+}
+
+// check the handling of the custom scheme given in the configuration
+func (customScheme *CustomScheme) prepare() error {
+
+	customScheme.Scheme = strings.ToLower(strings.TrimSuffix(customScheme.Scheme, ":"))
+
+	if customScheme.Command != "" {
+		return nil
+	}
+
+	switch customScheme.Result {
+	case customSchemeOk, customSchemeWarn:
+		return nil
+	}
+
+	return errors.New("invalid result " + customScheme.Result + " for scheme " + customScheme.Scheme + " (use ok, warn or a command)")
+
+}
+
+// get the configured handling of the given scheme (nil if the scheme is not configured)
+func findCustomScheme(scheme string) *CustomScheme {
+
+	for index := range config.CustomSchemes {
+		if config.CustomSchemes[index].Scheme == scheme {
+			return &config.CustomSchemes[index]
+		}
+	}
+
+	return nil
+
+}
+
+// define a validator for all links with a custom scheme of the configuration
+type customSchemeValidator struct{}
+
+// the validator handles all links with a configured scheme
+func (validator *customSchemeValidator) Matches(link *Hyperlink) bool {
+	return findCustomScheme(linkScheme(link.Url)) != nil
+}
+
+// validate the link as configured for its scheme
+func (validator *customSchemeValidator) Validate(link *Hyperlink) {
+
+	customScheme := findCustomScheme(linkScheme(link.Url))
+
+	switch {
+	case customScheme.Command != "":
+		runValidationCommand(customScheme.Command, link)
+	case customScheme.Result == customSchemeWarn:
+		link.IsWorking = true
+		link.Warnings = append(link.Warnings, warningCustomScheme)
+	default:
+		link.IsWorking = true
+	}
+
+}
+
+// define a custom structure for an external command validating all links matching
+// the pattern (i.e. for systems that cannot be reached directly)
+type ValidationCommand struct {
+	Pattern string `json:"pattern"`
+	Command string `json:"command"`
+
+	matcher *regexp.Regexp
+}
+
+// compile the pattern of the validation command given in the configuration
+func (validationCommand *ValidationCommand) prepare() error {
+
+	matcher, err := regexp.Compile(validationCommand.Pattern)
+	if err != nil {
+		return errors.New("invalid validation command pattern " + validationCommand.Pattern)
+	}
+
+	if strings.TrimSpace(validationCommand.Command) == "" {
+		return errors.New("missing command for the pattern " + validationCommand.Pattern)
+	}
+
+	validationCommand.matcher = matcher
+
+	return nil
+
+}
+
+// get the first validation command with a pattern matching the url (nil if there is none)
+func findValidationCommand(url string) *ValidationCommand {
+
+	for index := range config.ValidationCommands {
+		if config.ValidationCommands[index].matcher.MatchString(url) {
+			return &config.ValidationCommands[index]
+		}
+	}
+
+	return nil
+
+}
+
+// define a validator running the configured validation commands
+type commandValidator struct{}
+
+// the validator handles all links matching the pattern of a validation command
+func (validator *commandValidator) Matches(link *Hyperlink) bool {
+	return findValidationCommand(link.Url) != nil
+}
+
+// run the validation command of the link
+func (validator *commandValidator) Validate(link *Hyperlink) {
+	runValidationCommand(findValidationCommand(link.Url).Command, link)
+}
+
+// run the given command to validate the link, the placeholder {url} in the
+// arguments is replaced with the url of the link and the link is working if the
+// command exits successfully (the first line of its output is used as reason otherwise)
+func runValidationCommand(command string, link *Hyperlink) {
+
+	arguments := strings.Fields(command)
+	if len(arguments) == 0 {
+		link.IsWorking = false
+		link.Reason = reasonCommandFailed
+		return
+	}
+
+	for index := range arguments {
+		arguments[index] = strings.Replace(arguments[index], "{url}", link.Url, -1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, arguments[0], arguments[1:]...).CombinedOutput()
+	if err == nil {
+		link.IsWorking = true
+		return
+	}
+
+	link.IsWorking = false
+	link.Reason = reasonCommandFailed
+
+	if line := strings.TrimSpace(string(bytes.SplitN(bytes.TrimSpace(output), []byte("\n"), 2)[0])); line != "" {
+		link.Reason = reasonCommandFailed + ": " + line
+	}
+
+}
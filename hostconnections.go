@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+)
+
+// the number of bytes of a response read to keep its connection alive
+const keepAliveBytes = 64 << 10
+
+// define a custom structure limiting the number of requests sent to the same host
+// at the same time. With a single connection per host, the links of the host are
+// checked one after another over the same kept-alive connection (the http client
+// of go does not pipeline requests)
+type HostConnections struct {
+	mutex sync.Mutex
+	slots map[string]chan bool
+}
+
+// the connections of the current run
+var hostConnections = &HostConnections{slots: make(map[string]chan bool)}
+
+// wait for a free connection to the given host
+func (connections *HostConnections) acquire(host string) {
+
+	// the number of connections is only limited if requested
+	if options.HostConnections < 1 {
+		return
+	}
+
+	connections.mutex.Lock()
+
+	slots, exists := connections.slots[host]
+	if exists == false {
+		slots = make(chan bool, options.HostConnections)
+		connections.slots[host] = slots
+	}
+
+	connections.mutex.Unlock()
+
+	slots <- true
+
+}
+
+// release the connection to the given host
+func (connections *HostConnections) release(host string) {
+
+	if options.HostConnections < 1 {
+		return
+	}
+
+	connections.mutex.Lock()
+	slots := connections.slots[host]
+	connections.mutex.Unlock()
+
+	<-slots
+
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// define a custom structure for the expiry dates of the certificate and the
+// domain registration of a linked host
+type HostExpiry struct {
+	Host                  string
+	CertificateExpires    time.Time
+	DomainExpires         time.Time
+	IsCertificateExpiring bool
+	IsDomainExpiring      bool
+}
+
+// the time to wait for the tls handshake and the whois servers
+const expiryTimeout = 10 * time.Second
+
+// the whois server referring to the whois servers of every top level domain
+const ianaWhoisServer = "whois.iana.org"
+
+// the fields whois servers use for the expiry date of the registration
+var whoisExpiryFields = []string{"registry expiry date", "registrar registration expiration date", "expiration date", "expiry date", "expires", "paid-till"}
+
+// the layouts of the expiry dates found in the responses of whois servers
+var whoisDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05Z", "2006-01-02 15:04:05", "2006-01-02", "02-Jan-2006", "2006.01.02", "02.01.2006"}
+
+// second level labels that belong to the registered domain (i.e. example.co.uk)
+var secondLevelLabels = map[string]bool{"co": true, "com": true, "ac": true, "org": true, "net": true, "gov": true, "edu": true}
+
+// look up the expiry dates of all hosts linked from the documents and add a
+// warning to the links of hosts expiring within the warning days of the configuration
+func checkHostExpiries(documents []Document) []HostExpiry {
+
+	hosts := []string{}
+	seen := make(map[string]bool)
+
+	for _, document := range documents {
+		for _, link := range document.Hyperlinks {
+
+			host := urlDomain(link.RequestUrl)
+
+			if link.IsExternal == false || link.NotChecked || isHttpScheme(linkScheme(link.Url)) == false || host == "" || seen[host] {
+				continue
+			}
+
+			seen[host] = true
+			hosts = append(hosts, host)
+
+		}
+	}
+
+	sort.Strings(hosts)
+
+	expiries := make([]HostExpiry, len(hosts))
+
+	// look up the hosts in parallel (with the concurrency of the link checks)
+	var workers sync.WaitGroup
+	slots := make(chan bool, options.Concurrency)
+
+	for index, host := range hosts {
+
+		workers.Add(1)
+		slots <- true
+
+		go func(index int, host string) {
+			expiries[index] = lookupHostExpiry(host)
+			<-slots
+			workers.Done()
+		}(index, host)
+
+	}
+
+	workers.Wait()
+
+	expiring := make(map[string]HostExpiry)
+
+	for _, expiry := range expiries {
+		if expiry.IsCertificateExpiring || expiry.IsDomainExpiring {
+			expiring[expiry.Host] = expiry
+		}
+	}
+
+	for documentIndex := range documents {
+		for linkIndex := range documents[documentIndex].Hyperlinks {
+
+			link := &documents[documentIndex].Hyperlinks[linkIndex]
+
+			expiry, exists := expiring[urlDomain(link.RequestUrl)]
+			if exists == false || link.NotChecked {
+				continue
+			}
+
+			if expiry.IsCertificateExpiring {
+				link.Warnings = append(link.Warnings, "certificate of "+expiry.Host+" expires on "+expiry.CertificateExpires.Format("2006-01-02"))
+			}
+
+			if expiry.IsDomainExpiring {
+				link.Warnings = append(link.Warnings, "registration of the domain of "+expiry.Host+" expires on "+expiry.DomainExpires.Format("2006-01-02"))
+			}
+
+		}
+	}
+
+	return expiries
+
+}
+
+// look up the expiry of the certificate and of the domain registration of the host
+func lookupHostExpiry(host string) HostExpiry {
+
+	horizon := clock().AddDate(0, 0, config.ExpiryWarningDays)
+
+	expiry := HostExpiry{Host: host}
+
+	expiry.CertificateExpires = certificateExpiry(host)
+	expiry.IsCertificateExpiring = expiry.CertificateExpires.IsZero() == false && expiry.CertificateExpires.Before(horizon)
+
+	expiry.DomainExpires = domainExpiry(registeredDomain(host))
+	expiry.IsDomainExpiring = expiry.DomainExpires.IsZero() == false && expiry.DomainExpires.Before(horizon)
+
+	return expiry
+
+}
+
+// get the expiry date of the certificate of the host (zero if there is none)
+func certificateExpiry(host string) time.Time {
+
+	dialer := &net.Dialer{Timeout: expiryTimeout}
+
+	// we are interested in the certificate even if it is not valid anymore
+	connection, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, "443"), &tls.Config{ServerName: host, InsecureSkipVerify: true})
+	if err != nil {
+		return time.Time{}
+	}
+	defer connection.Close()
+
+	certificates := connection.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return time.Time{}
+	}
+
+	return certificates[0].NotAfter
+
+}
+
+// get the expiry date of the registration of the domain from the whois server of
+// its top level domain (zero if the registry does not publish it)
+func domainExpiry(domain string) time.Time {
+
+	server := whoisServer(domain)
+	if server == "" {
+		return time.Time{}
+	}
+
+	value := whoisField(queryWhois(server, domain), whoisExpiryFields)
+
+	for _, layout := range whoisDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+
+}
+
+// get the whois server of the top level domain of the domain (empty if the
+// registry does not run one)
+func whoisServer(domain string) string {
+
+	labels := strings.Split(domain, ".")
+
+	referral := queryWhois(ianaWhoisServer, labels[len(labels)-1])
+
+	return whoisField(referral, []string{"refer", "whois"})
+
+}
+
+// send the query to the whois server and return its response
+func queryWhois(server string, query string) string {
+
+	connection, err := net.DialTimeout("tcp", net.JoinHostPort(server, "43"), expiryTimeout)
+	if err != nil {
+		return ""
+	}
+	defer connection.Close()
+
+	connection.SetDeadline(time.Now().Add(expiryTimeout))
+
+	_, err = fmt.Fprintf(connection, "%s\r\n", query)
+	if err != nil {
+		return ""
+	}
+
+	var response strings.Builder
+
+	scanner := bufio.NewScanner(connection)
+	for scanner.Scan() {
+		response.WriteString(scanner.Text() + "\n")
+	}
+
+	return response.String()
+
+}
+
+// get the value of the first of the given fields found in the whois response
+func whoisField(response string, fields []string) string {
+
+	for _, field := range fields {
+		for _, line := range strings.Split(response, "\n") {
+
+			separator := strings.Index(line, ":")
+			if separator < 0 {
+				continue
+			}
+
+			if strings.EqualFold(strings.TrimSpace(line[:separator]), field) {
+				return strings.TrimSpace(line[separator+1:])
+			}
+
+		}
+	}
+
+	return ""
+
+}
+
+// get the registered domain of the host (i.e. example.com for www.example.com)
+func registeredDomain(host string) string {
+
+	labels := strings.Split(host, ".")
+
+	if len(labels) <= 2 {
+		return host
+	}
+
+	if len(labels[len(labels)-1]) == 2 && secondLevelLabels[labels[len(labels)-2]] {
+		return strings.Join(labels[len(labels)-3:], ".")
+	}
+
+	return strings.Join(labels[len(labels)-2:], ".")
+
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// define the reasons and warnings reported for links with identifiers
+const (
+	reasonDoiNotRegistered   = "DOI is not registered"
+	reasonPmidNotFound       = "PubMed identifier does not exist"
+	reasonNctNotFound        = "study is not registered on ClinicalTrials.gov"
+	reasonResolverError      = "identifier resolver could not be reached"
+	warningRetractedPubMed   = "retracted publication (PubMed)"
+	warningRetractedCrossref = "retracted publication (Crossref)"
+	warningStudyWithdrawn    = "study record was withdrawn (ClinicalTrials.gov)"
+)
+
+// define the apis used to resolve the identifiers
+const (
+	doiHandleApi      = "https://doi.org/api/handles/"
+	pubmedSummaryApi  = "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/esummary.fcgi?db=pubmed&retmode=json&id="
+	crossrefWorksApi  = "https://api.crossref.org/works/"
+	clinicalTrialsApi = "https://clinicaltrials.gov/api/v2/studies/"
+)
+
+// define the response codes of the doi handle api
+const (
+	doiResponseCodeFound    = 1
+	doiResponseCodeNotFound = 100
+)
+
+// publications that were retracted carry a special publication type in pubmed
+const pubmedRetractedPubType = "Retracted Publication"
+
+// get the doi referenced by the given url (i.e. https://doi.org/10.1000/xyz or doi:10.1000/xyz)
+func extractDoi(rawUrl string) string {
+
+	if strings.HasPrefix(strings.ToLower(rawUrl), "doi:") {
+		return strings.TrimSpace(rawUrl[4:])
+	}
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(parsedUrl.Hostname()) {
+	case "doi.org", "dx.doi.org", "www.doi.org":
+	default:
+		return ""
+	}
+
+	doi := strings.TrimPrefix(parsedUrl.Path, "/")
+
+	// all dois start with the directory indicator 10.
+	if strings.HasPrefix(doi, "10.") == false {
+		return ""
+	}
+
+	return doi
+
+}
+
+// get the pubmed identifier referenced by the given url
+func extractPmid(rawUrl string) string {
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(parsedUrl.Hostname())
+
+	switch {
+	case host == "pubmed.ncbi.nlm.nih.gov":
+	case host == "www.ncbi.nlm.nih.gov" && strings.HasPrefix(parsedUrl.Path, "/pubmed/"):
+	default:
+		return ""
+	}
+
+	match := matchers["pmid"].FindStringSubmatch(parsedUrl.Path)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+
+}
+
+// define a validator checking the registration of dois with the doi.org handle api
+type doiValidator struct{}
+
+func (validator *doiValidator) Matches(link *Hyperlink) bool {
+	return extractDoi(link.Url) != ""
+}
+
+func (validator *doiValidator) Validate(link *Hyperlink) {
+
+	doi := extractDoi(link.Url)
+
+	var response struct {
+		ResponseCode int `json:"responseCode"`
+	}
+
+	// the api answers with status 404 for unknown dois, we therefore only rely on the response code
+	_, err := fetchJson(doiHandleApi+url.PathEscape(doi), &response)
+
+	switch {
+	case err != nil && response.ResponseCode == 0:
+		link.IsWorking = false
+		link.Reason = reasonResolverError
+	case response.ResponseCode == doiResponseCodeFound:
+		link.IsWorking = true
+
+		if options.CheckRetractions {
+			checkCrossrefRetraction(link, doi)
+		}
+	case response.ResponseCode == doiResponseCodeNotFound:
+		link.IsWorking = false
+		link.Reason = reasonDoiNotRegistered
+	default:
+		link.IsWorking = false
+		link.Reason = reasonResolverError
+	}
+
+}
+
+// define a validator checking pubmed identifiers with the ncbi e-utilities
+type pubmedValidator struct{}
+
+func (validator *pubmedValidator) Matches(link *Hyperlink) bool {
+	return extractPmid(link.Url) != ""
+}
+
+func (validator *pubmedValidator) Validate(link *Hyperlink) {
+
+	pmid := extractPmid(link.Url)
+
+	var response struct {
+		Result map[string]json.RawMessage `json:"result"`
+	}
+
+	_, err := fetchJson(pubmedSummaryApi+pmid, &response)
+	if err != nil {
+		link.IsWorking = false
+		link.Reason = reasonResolverError
+		return
+	}
+
+	var summary struct {
+		Error   string   `json:"error"`
+		PubType []string `json:"pubtype"`
+	}
+
+	data, exists := response.Result[pmid]
+	if exists == false || json.Unmarshal(data, &summary) != nil || summary.Error != "" {
+		link.IsWorking = false
+		link.Reason = reasonPmidNotFound
+		return
+	}
+
+	link.IsWorking = true
+
+	for _, pubType := range summary.PubType {
+		if pubType == pubmedRetractedPubType {
+			link.IsRetracted = true
+			link.Warnings = append(link.Warnings, warningRetractedPubMed)
+		}
+	}
+
+}
+
+// check the crossref metadata of the doi for retraction notices (crossref includes
+// the retraction watch database in the updated-by information of a work)
+func checkCrossrefRetraction(link *Hyperlink, doi string) {
+
+	var response struct {
+		Message struct {
+			UpdatedBy []struct {
+				Type string `json:"type"`
+				Doi  string `json:"DOI"`
+			} `json:"updated-by"`
+		} `json:"message"`
+	}
+
+	// dois not registered with crossref (i.e. datacite) cannot be checked
+	statusCode, err := fetchJson(crossrefWorksApi+url.PathEscape(doi), &response)
+	if err != nil || statusCode != 200 {
+		return
+	}
+
+	for _, update := range response.Message.UpdatedBy {
+
+		if update.Type != "retraction" {
+			continue
+		}
+
+		link.IsRetracted = true
+
+		warning := warningRetractedCrossref
+		if update.Doi != "" {
+			warning = warning + ", see doi:" + update.Doi
+		}
+
+		link.Warnings = append(link.Warnings, warning)
+
+		return
+
+	}
+
+}
+
+// get the nct number of a study referenced by the given clinicaltrials.gov url
+// (i.e. https://clinicaltrials.gov/study/NCT01234567 or .../ct2/show/NCT01234567)
+func extractNctId(rawUrl string) string {
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+
+	switch strings.ToLower(parsedUrl.Hostname()) {
+	case "clinicaltrials.gov", "www.clinicaltrials.gov", "classic.clinicaltrials.gov":
+	default:
+		return ""
+	}
+
+	match := matchers["nctId"].FindString(parsedUrl.Path + " " + parsedUrl.RawQuery)
+
+	return strings.ToUpper(match)
+
+}
+
+// define a validator checking studies with the clinicaltrials.gov registry api, as
+// the website itself answers with status 200 even for unknown studies
+type clinicalTrialsValidator struct{}
+
+func (validator *clinicalTrialsValidator) Matches(link *Hyperlink) bool {
+	return extractNctId(link.Url) != ""
+}
+
+func (validator *clinicalTrialsValidator) Validate(link *Hyperlink) {
+
+	nctId := extractNctId(link.Url)
+
+	var response struct {
+		ProtocolSection struct {
+			StatusModule struct {
+				OverallStatus string `json:"overallStatus"`
+			} `json:"statusModule"`
+		} `json:"protocolSection"`
+	}
+
+	statusCode, err := fetchJson(clinicalTrialsApi+nctId+"?fields=OverallStatus", &response)
+
+	switch {
+	case statusCode == 404:
+		link.IsWorking = false
+		link.Reason = reasonNctNotFound
+		return
+	case err != nil || statusCode != 200:
+		link.IsWorking = false
+		link.Reason = reasonResolverError
+		return
+	}
+
+	link.IsWorking = true
+
+	// withdrawn studies were stopped before enrolling the first participant
+	if response.ProtocolSection.StatusModule.OverallStatus == "WITHDRAWN" {
+		link.Warnings = append(link.Warnings, warningStudyWithdrawn)
+	}
+
+}
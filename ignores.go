@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// define a custom structure for the links of the configuration that are ignored
+// until the given date because of a known issue (i.e. a ticket), the links are
+// checked again once the date has passed
+type IgnoreEntry struct {
+	Pattern string `json:"pattern"`
+	Until   string `json:"until"`
+	Ticket  string `json:"ticket"`
+	Reason  string `json:"reason"`
+
+	matcher *regexp.Regexp
+	until   time.Time
+}
+
+// define a custom structure for a link ignored by an entry of the configuration
+// (as listed in the appendix of the report)
+type IgnoredLink struct {
+	Document string
+	Url      string
+	Ticket   string
+	Reason   string
+	Until    string
+}
+
+// check the ignore entry given in the configuration
+func (entry *IgnoreEntry) prepare() error {
+
+	matcher, err := regexp.Compile(entry.Pattern)
+	if err != nil {
+		return errors.New("invalid ignore pattern " + entry.Pattern)
+	}
+
+	// links are never ignored forever, so the suppressions are reviewed
+	until, err := time.ParseInLocation("2006-01-02", entry.Until, time.Local)
+	if err != nil {
+		return errors.New("invalid date " + entry.Until + " of the ignore pattern " + entry.Pattern + " (use yyyy-mm-dd)")
+	}
+
+	entry.matcher = matcher
+	entry.until = until
+
+	return nil
+
+}
+
+// check if the entry still applies (the links are ignored until the end of the day)
+func (entry *IgnoreEntry) isActive() bool {
+	return clock().Before(entry.until.AddDate(0, 0, 1))
+}
+
+// move the links ignored by the configuration to the ignored links of the
+// document, the links of expired entries are checked with a warning instead
+func (document *Document) applyIgnores(hyperlinks []Hyperlink) []Hyperlink {
+
+	if len(config.Ignores) == 0 {
+		return hyperlinks
+	}
+
+	remaining := []Hyperlink{}
+
+	for _, link := range hyperlinks {
+
+		ignored := false
+
+		for _, entry := range config.Ignores {
+
+			if entry.matcher.MatchString(link.Url) == false {
+				continue
+			}
+
+			if entry.isActive() == false {
+				link.Warnings = append(link.Warnings, "the ignore entry "+entry.Ticket+" expired on "+entry.Until)
+				continue
+			}
+
+			document.IgnoredLinks = append(document.IgnoredLinks, IgnoredLink{
+				Document: document.Path,
+				Url:      link.Url,
+				Ticket:   entry.Ticket,
+				Reason:   entry.Reason,
+				Until:    entry.Until,
+			})
+
+			ignored = true
+			break
+
+		}
+
+		if ignored == false {
+			remaining = append(remaining, link)
+		}
+
+	}
+
+	return remaining
+
+}
+
+// get the links of all documents ignored by the configuration
+func (report *Report) IgnoredLinks() []IgnoredLink {
+
+	links := []IgnoredLink{}
+
+	for _, document := range report.Documents {
+		links = append(links, document.IgnoredLinks...)
+	}
+
+	return links
+
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+)
+
+// define the issues of display texts found by the link text audit
+const (
+	textIssueRawUrl  = "the display text is the url itself"
+	textIssueGeneric = "the display text does not describe the target"
+)
+
+// the display texts that do not tell readers (and screen readers) where a link
+// leads, in the languages of our documents
+var genericLinkTexts = map[string]bool{
+	"here": true, "click here": true, "click": true, "link": true, "this link": true, "more": true,
+	"read more": true, "more info": true, "more information": true, "details": true, "go": true,
+	"hier": true, "hier klicken": true, "klicken sie hier": true, "mehr": true, "weiter": true,
+	"weitere informationen": true, "ici": true, "cliquez ici": true, "plus": true, "qui": true,
+}
+
+// define a custom structure for a link whose display text is not accessible (as
+// listed in the report)
+type LinkTextIssue struct {
+	Document string
+	Url      string
+	Text     string
+	Issue    string
+}
+
+// find the issue of the display text of the given link (empty if there is none)
+func linkTextIssue(link Hyperlink) string {
+
+	// only links clicked in the text have a display text chosen by the author
+	if link.Category != categoryHyperlink && link.Category != categoryFieldHyperlink {
+		return ""
+	}
+
+	text := strings.ToLower(strings.Join(strings.Fields(link.Text), " "))
+	text = strings.Trim(text, ".:!?»«\"'()[] ")
+
+	if text == "" {
+		return ""
+	}
+
+	if matchers["plainTextUrl"].FindString(text) == text || strings.EqualFold(text, strings.TrimPrefix(strings.TrimPrefix(link.Url, "https://"), "http://")) {
+		return textIssueRawUrl
+	}
+
+	if genericLinkTexts[text] {
+		return textIssueGeneric
+	}
+
+	return ""
+
+}
+
+// audit the display texts of the links of the document (i.e. for our
+// accessibility officer)
+func (document *Document) auditLinkTexts() {
+
+	for _, link := range document.Hyperlinks {
+		if issue := linkTextIssue(link); issue != "" {
+			document.LinkTextIssues = append(document.LinkTextIssues, LinkTextIssue{Document: document.Path, Url: link.Url, Text: link.Text, Issue: issue})
+		}
+	}
+
+}
+
+// get the link text issues of all documents of the report
+func (report *Report) LinkTextIssues() []LinkTextIssue {
+
+	issues := []LinkTextIssue{}
+
+	for _, document := range report.Documents {
+		issues = append(issues, document.LinkTextIssues...)
+	}
+
+	return issues
+
+}
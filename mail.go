@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// define the reasons and warnings reported for mailto links
+const (
+	reasonMalformedMailAddress = "malformed mail address"
+	warningMailDomain          = "mail address outside the approved domains"
+)
+
+// define a validator checking that mailto links only point to the approved
+// mail domains of the configuration (i.e. no personal addresses in official
+// study documents)
+type mailValidator struct{}
+
+// the validator handles all mailto links if approved domains are configured
+func (validator *mailValidator) Matches(link *Hyperlink) bool {
+	return len(config.MailDomains) > 0 && linkScheme(link.Url) == "mailto"
+}
+
+// check the addresses of the link against the approved domains
+func (validator *mailValidator) Validate(link *Hyperlink) {
+
+	addresses := mailAddresses(link.Url)
+
+	if len(addresses) == 0 {
+		link.IsWorking = false
+		link.Reason = reasonMalformedMailAddress
+		return
+	}
+
+	outside := []string{}
+
+	for _, address := range addresses {
+
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			link.IsWorking = false
+			link.Reason = reasonMalformedMailAddress + " " + address
+			return
+		}
+
+		domain := strings.ToLower(parsed.Address[strings.LastIndex(parsed.Address, "@")+1:])
+
+		if isApprovedMailDomain(domain) == false {
+			outside = append(outside, parsed.Address)
+		}
+
+	}
+
+	link.IsWorking = true
+
+	if len(outside) > 0 {
+		link.Warnings = append(link.Warnings, warningMailDomain+" ("+strings.Join(outside, ", ")+")")
+	}
+
+}
+
+// get the recipients of a mailto link, including the recipients given as
+// to, cc and bcc parameters
+func mailAddresses(link string) []string {
+
+	target := link[strings.Index(link, ":")+1:]
+	query := ""
+
+	if separator := strings.Index(target, "?"); separator >= 0 {
+		query = target[separator+1:]
+		target = target[:separator]
+	}
+
+	recipients := []string{target}
+
+	if parameters, err := url.ParseQuery(query); err == nil {
+		for name, values := range parameters {
+			switch strings.ToLower(name) {
+			case "to", "cc", "bcc":
+				recipients = append(recipients, values...)
+			}
+		}
+	}
+
+	addresses := []string{}
+
+	for _, recipient := range recipients {
+
+		if unescaped, err := url.PathUnescape(recipient); err == nil {
+			recipient = unescaped
+		}
+
+		for _, address := range strings.Split(recipient, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+
+	}
+
+	return addresses
+
+}
+
+// check if the domain is one of the approved domains (or one of their subdomains)
+func isApprovedMailDomain(domain string) bool {
+
+	for _, approved := range config.MailDomains {
+
+		approved = strings.ToLower(strings.TrimPrefix(approved, "@"))
+
+		if domain == approved || strings.HasSuffix(domain, "."+approved) {
+			return true
+		}
+
+	}
+
+	return false
+
+}
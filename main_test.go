@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// rewrite the fixture corpus and the golden reports instead of comparing them
+var update = flag.Bool("update", false, "rewrite the fixture corpus and the golden reports")
+
+// the host of all external hyperlinks of the fixture corpus (served by the fake server)
+const fakeHost = "links.test"
+
+// the directory of the fixture documents
+var corpusDirectory = filepath.Join("testdata", "corpus")
+
+// the hyperlinks of the fixture documents (by file name)
+var corpusDocuments = map[string][]string{
+	"guide.docx": {
+		"http://" + fakeHost + "/ok",
+		"http://" + fakeHost + "/moved",
+		"http://" + fakeHost + "/loop",
+		"http://" + fakeHost + "/missing",
+		"http://unreachable.test/",
+		"slides.pptx",
+		"missing.docx",
+	},
+	"slides.pptx": {
+		"http://" + fakeHost + "/ok",
+		"http://" + fakeHost + "/gone",
+		"guide.docx",
+	},
+}
+
+// initialize the options and the globals the same way as a regular run (without
+// any network requests apart from the ones to the fake server)
+func TestMain(m *testing.M) {
+
+	flag.Parse()
+
+	parseOptions([]string{
+		"-open=false",
+		"-network-probes=0",
+		"-check-identifiers=false",
+		"-check-retractions=false",
+		"-timezone=UTC",
+		"-max-redirects=3",
+		"-concurrency=4",
+	})
+
+	initializeMatchers()
+	initializeValidators()
+	initializeSessions()
+
+	err := initializeFilters()
+	if err != nil {
+		panic(err)
+	}
+
+	// the output of the link checks is of no interest in the tests
+	console = io.Discard
+
+	server := newFakeServer()
+	checker = &httpChecker{Timeout: 5 * time.Second, Client: fakeClient(server)}
+
+	status := m.Run()
+
+	server.Close()
+	os.Exit(status)
+
+}
+
+// start a web server answering the hyperlinks of the fixture corpus
+func newFakeServer() *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("<html><head><title>Fixture</title></head><body>ok</body></html>"))
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/gone":
+			http.Error(w, "gone", http.StatusGone)
+		default:
+			http.NotFound(w, r)
+		}
+
+	}))
+
+}
+
+// get a client sending the requests to the fake host to the given server, all
+// other hosts cannot be reached
+func fakeClient(server *httptest.Server) *http.Client {
+
+	dialer := &net.Dialer{}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network string, address string) (net.Conn, error) {
+
+			if address != fakeHost+":80" {
+				return nil, errors.New("dial " + address + ": no such host")
+			}
+
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+
+		},
+	}
+
+	return &http.Client{Transport: transport}
+
+}
+
+// write the documents of the fixture corpus
+func writeCorpus(t testing.TB) {
+
+	err := os.MkdirAll(corpusDirectory, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, targets := range corpusDocuments {
+
+		path := filepath.Join(corpusDirectory, name)
+
+		if filepath.Ext(name) == ".pptx" {
+			err = writePresentation(path, targets)
+		} else {
+			err = writeWordDocument(path, targets)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+}
+
+// remove everything from the report that changes between two runs or machines
+func normalizeReport(report *Report) {
+
+	report.Date = "2024-01-01 00:00"
+	report.Metadata = ReportMetadata{}
+
+	for documentIndex := range report.Documents {
+
+		document := &report.Documents[documentIndex]
+		document.Modified = time.Time{}
+
+		for linkIndex := range document.Hyperlinks {
+			document.Hyperlinks[linkIndex].Duration = 0
+		}
+
+	}
+
+	for index := range report.Domains {
+		report.Domains[index].AverageLatency = 0
+	}
+
+}
+
+// compare the given output with the golden file (or rewrite the golden file)
+func compareGolden(t *testing.T, name string, output []byte) {
+
+	path := filepath.Join("testdata", "golden", name)
+
+	// the absolute paths of the documents depend on the checkout
+	workingDirectory, _ := os.Getwd()
+	output = bytes.ReplaceAll(output, []byte(filepath.ToSlash(workingDirectory)), []byte("$WORKDIR"))
+	output = bytes.ReplaceAll(output, []byte(workingDirectory), []byte("$WORKDIR"))
+
+	if *update {
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, output, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return
+
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read the golden file (run the tests with -update): %v", err)
+	}
+
+	if bytes.Equal(expected, output) == false {
+		t.Errorf("the output differs from %s (run the tests with -update to accept it):\n%s", path, output)
+	}
+
+}
+
+// check the fixture corpus against the fake server and compare the reports with
+// the golden files
+func TestGoldenReports(t *testing.T) {
+
+	if *update {
+		writeCorpus(t)
+	}
+
+	report := validateDirectories([]string{corpusDirectory})
+	normalizeReport(&report)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareGolden(t, "report.json", append(data, '\n'))
+
+	html := bytes.Buffer{}
+
+	err = report.render(&html, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compareGolden(t, "report.html", html.Bytes())
+
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the date layout used for the history file (independent of the date format of the
+// report, so the trend chart can always extract month and day)
+const historyDateLayout = "2006-01-02 15:04:05"
+
+// define a custom structure with information about the run that created a report
+type ReportMetadata struct {
+	Started       time.Time
+	Duration      string
+	Hostname      string
+	Build         BuildInfo
+	Configuration []string
+}
+
+// format the given time with the date layout and in the timezone of the options
+func formatTimestamp(timestamp time.Time) string {
+	return timestamp.In(options.Timezone).Format(options.DateFormat)
+}
+
+// load the timezone given on the command line (local means the timezone of the
+// system, which respects the TZ environment variable)
+func loadTimezone(name string) (*time.Location, error) {
+
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(name)
+
+}
+
+// collect the metadata of a run started at the given time
+func newReportMetadata(started time.Time) ReportMetadata {
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return ReportMetadata{
+		Started:       started,
+		Duration:      clock().Sub(started).Round(time.Second).String(),
+		Hostname:      hostname,
+		Build:         currentBuildInfo(),
+		Configuration: summarizeConfiguration(),
+	}
+
+}
+
+// summarize the settings of the run that influence its results
+func summarizeConfiguration() []string {
+
+	summary := []string{}
+
+	if options.ConfigFile != "" {
+		summary = append(summary, "configuration file: "+options.ConfigFile)
+	}
+
+	summary = append(summary, fmt.Sprintf("concurrency: %d", options.Concurrency))
+	summary = append(summary, "order: "+options.Order)
+
+	if options.Scope != scopeAll {
+		summary = append(summary, "scope: "+options.Scope+" links only")
+	}
+
+	filters := []string{}
+	for _, filter := range filterChain {
+		filters = append(filters, filter.Name())
+	}
+
+	if len(filters) > 0 {
+		summary = append(summary, "filters: "+strings.Join(filters, ", "))
+	}
+
+	disabled := []string{}
+	for scheme, enabled := range config.Schemes {
+		if enabled == false {
+			disabled = append(disabled, scheme)
+		}
+	}
+
+	if len(disabled) > 0 {
+		sort.Strings(disabled)
+		summary = append(summary, "disabled schemes: "+strings.Join(disabled, ", "))
+	}
+
+	if options.StatusOverrides != "" {
+		summary = append(summary, fmt.Sprintf("status overrides: %s (%d urls)", options.StatusOverrides, len(statusOverrides)))
+	}
+
+	if options.Sitemap != "" {
+		summary = append(summary, fmt.Sprintf("sitemap: %s (%d pages)", options.Sitemap, len(sitemapPages)))
+	}
+
+	if len(config.Redactions) > 0 {
+		summary = append(summary, fmt.Sprintf("redaction rules: %d", len(config.Redactions)))
+	}
+
+	if options.ModifiedSince.IsZero() == false {
+		summary = append(summary, "modified since: "+options.ModifiedSince.Format("2006-01-02"))
+	}
+
+	if options.MaxRequests > 0 {
+		summary = append(summary, fmt.Sprintf("maximum requests: %d", options.MaxRequests))
+	}
+
+	if options.MaxDuration > 0 {
+		summary = append(summary, "maximum duration: "+options.MaxDuration.String())
+	}
+
+	// list the optional checks that were enabled
+	checks := []string{}
+
+	if options.CheckIdentifiers {
+		checks = append(checks, "identifiers")
+	}
+
+	if options.CheckRetractions {
+		checks = append(checks, "retractions")
+	}
+
+	if options.RequireValidDocuments {
+		checks = append(checks, "linked documents")
+	}
+
+	if options.DetectParking {
+		checks = append(checks, "parked domains")
+	}
+
+	if options.CheckHostExpiry {
+		checks = append(checks, "host expiry")
+	}
+
+	if options.AuditLinkTexts {
+		checks = append(checks, "link texts")
+	}
+
+	if options.QrCodes {
+		checks = append(checks, "qr codes")
+	}
+
+	if options.PlainTextUrls {
+		checks = append(checks, "plain text urls")
+	}
+
+	if options.StripTracking {
+		checks = append(checks, "tracking parameters")
+	}
+
+	if options.SuggestReplacements {
+		checks = append(checks, "replacement suggestions")
+	}
+
+	if options.BrowserFallback {
+		checks = append(checks, "browser fallback")
+	}
+
+	if options.SecondaryProxy != "" {
+		checks = append(checks, "secondary proxy")
+	}
+
+	if len(checks) > 0 {
+		summary = append(summary, "checks: "+strings.Join(checks, ", "))
+	}
+
+	return summary
+
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the upper bounds (in seconds) of the buckets of the request duration histogram
+var durationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 15}
+
+// define a custom structure with the counters exposed to prometheus in serve mode
+type Metrics struct {
+	mutex           sync.Mutex
+	documents       int
+	links           int
+	broken          int
+	durationCounts  []int
+	durationSum     float64
+	durationCount   int
+	domainFailures  map[string]int
+	runs            int
+	lastRun         time.Time
+	lastRunIsValid  bool
+	lastRunDuration time.Duration
+}
+
+// the metrics of the web interface (nil if no metrics are collected)
+var metrics *Metrics
+
+// create a new set of metrics
+func newMetrics() *Metrics {
+	return &Metrics{durationCounts: make([]int, len(durationBuckets)), domainFailures: make(map[string]int)}
+}
+
+// count a document whose links were all checked
+func (metrics *Metrics) documentScanned() {
+
+	// metrics are only collected in serve mode
+	if metrics == nil {
+		return
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	metrics.documents++
+
+}
+
+// count a checked link and the time it took to validate it
+func (metrics *Metrics) linkChecked(link *Hyperlink, duration time.Duration) {
+
+	if metrics == nil || link.NotChecked {
+		return
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	metrics.links++
+
+	seconds := duration.Seconds()
+	metrics.durationSum += seconds
+	metrics.durationCount++
+
+	for index, bound := range durationBuckets {
+		if seconds <= bound {
+			metrics.durationCounts[index]++
+		}
+	}
+
+	if link.IsWorking == false {
+		metrics.broken++
+
+		// links to local documents have no domain
+		domain := urlDomain(link.Url)
+		if domain == "" {
+			domain = "local"
+		}
+
+		metrics.domainFailures[domain]++
+	}
+
+}
+
+// remember the result of a finished run
+func (metrics *Metrics) runFinished(report *Report) {
+
+	if metrics == nil {
+		return
+	}
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	metrics.runs++
+	metrics.lastRun = clock()
+	metrics.lastRunIsValid = report.ResultOfValidation
+	metrics.lastRunDuration = metrics.lastRun.Sub(report.Metadata.Started)
+
+}
+
+// write the metrics in the text exposition format of prometheus
+func (metrics *Metrics) write(writer io.Writer) {
+
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	writeMetric(writer, "validate_links_documents_scanned_total", "counter", "Number of documents scanned.", metrics.documents)
+	writeMetric(writer, "validate_links_links_checked_total", "counter", "Number of hyperlinks checked.", metrics.links)
+	writeMetric(writer, "validate_links_links_broken_total", "counter", "Number of broken hyperlinks found.", metrics.broken)
+	writeMetric(writer, "validate_links_runs_total", "counter", "Number of finished validation runs.", metrics.runs)
+
+	if metrics.runs > 0 {
+
+		isValid := 0
+		if metrics.lastRunIsValid {
+			isValid = 1
+		}
+
+		writeMetric(writer, "validate_links_last_run_valid", "gauge", "Whether all documents of the last run were valid.", isValid)
+		writeMetric(writer, "validate_links_last_run_timestamp_seconds", "gauge", "Time the last run finished.", metrics.lastRun.Unix())
+		writeMetric(writer, "validate_links_last_run_duration_seconds", "gauge", "Duration of the last run.", metrics.lastRunDuration.Seconds())
+
+	}
+
+	// the duration of the link checks as histogram
+	name := "validate_links_request_duration_seconds"
+	fmt.Fprintf(writer, "# HELP %s Duration of the validation of a hyperlink.\n# TYPE %s histogram\n", name, name)
+
+	for index, bound := range durationBuckets {
+		fmt.Fprintf(writer, "%s_bucket{le=\"%g\"} %d\n", name, bound, metrics.durationCounts[index])
+	}
+
+	fmt.Fprintf(writer, "%s_bucket{le=\"+Inf\"} %d\n", name, metrics.durationCount)
+	fmt.Fprintf(writer, "%s_sum %g\n%s_count %d\n", name, metrics.durationSum, name, metrics.durationCount)
+
+	// the broken links by domain (sorted, so the output is stable)
+	name = "validate_links_domain_failures_total"
+	fmt.Fprintf(writer, "# HELP %s Number of broken hyperlinks by domain.\n# TYPE %s counter\n", name, name)
+
+	domains := []string{}
+	for domain := range metrics.domainFailures {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	for _, domain := range domains {
+		fmt.Fprintf(writer, "%s{domain=\"%s\"} %d\n", name, escapeLabel(domain), metrics.domainFailures[domain])
+	}
+
+}
+
+// write a single metric without labels
+func writeMetric(writer io.Writer, name string, kind string, help string, value interface{}) {
+	fmt.Fprintf(writer, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+}
+
+// escape the given label value for the exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// serve the metrics to prometheus
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.write(w)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"sync"
+
+	"github.com/franela/goreq"
+)
+
+// define the reason given for links that were not checked without network
+const reasonNetworkUnavailable = "not checked, the network was unavailable"
+
+// define a custom structure recognizing runs without network access (i.e. on a
+// laptop without vpn), where every external link would be reported as broken
+type Network struct {
+	mutex         sync.Mutex
+	probes        int
+	isDecided     bool
+	isUnavailable bool
+}
+
+// the network of the current run
+var network = &Network{}
+
+// start observing the network of a new run
+func (network *Network) start() {
+
+	network.mutex.Lock()
+	defer network.mutex.Unlock()
+
+	network.probes = 0
+	network.isDecided = false
+	network.isUnavailable = false
+
+}
+
+// record the result of a request, the network is considered unavailable if the
+// first requests of the run all failed to resolve or connect to their host
+func (network *Network) record(err error) {
+
+	network.mutex.Lock()
+	defer network.mutex.Unlock()
+
+	if network.isDecided || options.NetworkProbes < 1 {
+		return
+	}
+
+	if isNetworkError(err) == false {
+		network.isDecided = true
+		return
+	}
+
+	network.probes++
+
+	if network.probes >= options.NetworkProbes {
+		network.isDecided = true
+		network.isUnavailable = true
+	}
+
+}
+
+// check if the network was found to be unavailable
+func (network *Network) unavailable() bool {
+
+	network.mutex.Lock()
+	defer network.mutex.Unlock()
+
+	return network.isUnavailable
+
+}
+
+// check if the given error occurred while resolving or connecting to a host
+func isNetworkError(err error) bool {
+
+	if err == nil {
+		return false
+	}
+
+	if requestErr, ok := err.(*goreq.Error); ok {
+		err = requestErr.Err
+	}
+
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+
+	return false
+
+}
+
+// mark the links whose request failed as not checked with the given reason, i.e.
+// once the network was found to be unavailable (they are not broken, just
+// unreachable from here)
+func markFailedRequests(documents []Document, reason string) {
+
+	for index := range documents {
+
+		document := &documents[index]
+
+		for linkIndex := range document.Hyperlinks {
+
+			link := &document.Hyperlinks[linkIndex]
+
+			if link.requestFailed {
+				link.IsWorking = false
+				link.NotChecked = true
+				link.Reason = reason
+			}
+
+		}
+
+		document.updateValidity()
+
+	}
+
+}
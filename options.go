@@ -0,0 +1,283 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+// define the options that can be set on the command line
+type Options struct {
+	ProgressFile          string
+	ProgressFd            int
+	Gui                   bool
+	GuiAddress            string
+	ListLinks             bool
+	Concurrency           int
+	SplitAssets           bool
+	HistoryFile           string
+	HistoryRuns           int
+	Format                string
+	ConfigFile            string
+	CheckIdentifiers      bool
+	CheckRetractions      bool
+	Archive               bool
+	Roots                 []string
+	RepeatedLinks         int
+	RequireValidDocuments bool
+	OwnerReports          bool
+	ModifiedSince         time.Time
+	MaxRequests           int
+	MaxDuration           time.Duration
+	FailOn                string
+	FailThreshold         int
+	GraphFile             string
+	Deduplicate           bool
+	CodeQualityFile       string
+	Open                  bool
+	Browser               string
+	Order                 string
+	Scope                 string
+	Jitter                time.Duration
+	EvidenceDirectory     string
+	EvidenceBytes         int
+	ScreenshotDirectory   string
+	Chrome                string
+	BrowserFallback       bool
+	Sitemap               string
+	DetectParking         bool
+	CheckHostExpiry       bool
+	SecondaryProxy        string
+	DateFormat            string
+	Timeout               time.Duration
+	Proxy                 string
+	Stdout                bool
+	CpuProfile            string
+	MemProfile            string
+	Pprof                 bool
+	CacheFile             string
+	HostConnections       int
+	Head                  bool
+	MaxRedirects          int
+	RetryTimeout          time.Duration
+	NetworkProbes         int
+	Shard                 Shard
+	Zone                  string
+	StripTracking         bool
+	SuggestReplacements   bool
+	TopBrokenDomains      int
+	Whois                 bool
+	UrlSources            bool
+	StatusOverrides       string
+	PlainTextUrls         bool
+	QrCodes               bool
+	QrDecoder             string
+	AuditLinkTexts        bool
+	CheckHomoglyphs       bool
+	SigningKey            string
+	Timezone              *time.Location
+}
+
+// the options of the current run
+var options Options
+
+// parse the options given on the command line (without the name of the program)
+func parseOptions(arguments []string) {
+
+	flag.StringVar(&options.ProgressFile, "progress-file", "", "write progress events as newline delimited json to the given file")
+	flag.IntVar(&options.ProgressFd, "progress-fd", -1, "write progress events as newline delimited json to the given file descriptor")
+	flag.BoolVar(&options.Gui, "gui", false, "serve a local web interface instead of generating a single report")
+	flag.StringVar(&options.GuiAddress, "gui-address", "127.0.0.1:0", "address the web interface should listen on")
+	flag.BoolVar(&options.ListLinks, "list-links", false, "only print the hyperlinks of all documents without checking them")
+	flag.IntVar(&options.Concurrency, "concurrency", 20, "number of hyperlinks that are checked at the same time")
+	flag.BoolVar(&options.SplitAssets, "split-assets", false, "write the report as directory with separate css, js and json data files")
+	flag.StringVar(&options.HistoryFile, "history", "", "file to store the results of each run in (used for the trend chart in the report)")
+	flag.IntVar(&options.HistoryRuns, "history-runs", 10, "number of previous runs shown in the trend chart")
+	flag.StringVar(&options.Format, "format", "html", "format of the report (html, json or urls for the list of unique urls)")
+	flag.StringVar(&options.ConfigFile, "config", "", "configuration file (json)")
+	flag.BoolVar(&options.CheckIdentifiers, "check-identifiers", false, "check doi, pubmed and clinicaltrials.gov links with the corresponding registries instead of a plain request")
+	flag.BoolVar(&options.CheckRetractions, "check-retractions", false, "query crossref for retraction notices of linked dois")
+
+	flag.BoolVar(&options.Archive, "archive", false, "submit all working external links to the wayback machine to preserve a copy")
+	flag.IntVar(&options.RepeatedLinks, "repeated-links", 3, "report urls used at least this many times in the same document (0 to disable)")
+	flag.BoolVar(&options.OwnerReports, "owner-reports", false, "create an additional report for each document owner of the configuration")
+	flag.BoolVar(&options.RequireValidDocuments, "require-valid-documents", false, "treat links to other checked documents with broken links as broken")
+	flag.IntVar(&options.MaxRequests, "max-requests", 0, "maximum number of requests of a run, the remaining links are not checked (0 for no limit)")
+	flag.DurationVar(&options.MaxDuration, "max-duration", 0, "maximum duration of a run (i.e. 30m), the remaining links are not checked (0 for no limit)")
+	flag.StringVar(&options.FailOn, "fail-on", "none", "exit with status 1 for broken links (broken), broken links and warnings (warning) or never (none)")
+	flag.IntVar(&options.FailThreshold, "fail-threshold", 0, "number of issues tolerated before the run fails")
+	flag.BoolVar(&options.Deduplicate, "deduplicate", false, "check identical copies of a document only once and list them with the checked document")
+	flag.BoolVar(&options.Open, "open", isInteractiveDesktop(), "open the report in the browser (by default only for interactive runs on a desktop)")
+	flag.StringVar(&options.Browser, "browser", "", "command used to open the report instead of the default browser")
+	flag.StringVar(&options.Order, "order", orderDocument, "order of the link checks (document, host to alternate between the hosts, or random)")
+	flag.DurationVar(&options.Jitter, "jitter", 0, "wait a random time up to the given duration (i.e. 500ms) before each request")
+	flag.StringVar(&options.EvidenceDirectory, "evidence", "", "write the response of every broken link to a file in the given directory")
+	flag.IntVar(&options.EvidenceBytes, "evidence-bytes", 4096, "number of bytes of the content kept as evidence")
+	flag.StringVar(&options.ScreenshotDirectory, "screenshots", "", "capture screenshots of broken and suspect links with headless chrome to the given directory")
+	flag.StringVar(&options.Chrome, "chrome", "", "path of the chrome executable used for screenshots and the browser fallback (searched in the path by default)")
+	flag.BoolVar(&options.BrowserFallback, "browser-fallback", false, "check the links blocked by a bot protection again with headless chrome, which passes javascript challenges")
+	flag.BoolVar(&options.DetectParking, "detect-parking", false, "report links to parked domains and domains for sale as broken")
+	flag.BoolVar(&options.CheckHostExpiry, "check-host-expiry", false, "warn about linked hosts whose certificate or domain registration expires within the warning days of the configuration")
+	flag.StringVar(&options.SecondaryProxy, "secondary-proxy", "", "check working links again through the given proxy (i.e. in another region) and warn about links failing there")
+	flag.DurationVar(&options.Timeout, "timeout", 15*time.Second, "time to wait for the response of a hyperlink")
+	flag.StringVar(&options.Proxy, "proxy", "", "proxy all hyperlinks are checked through (i.e. http://proxy.example.com:3128)")
+	flag.StringVar(&options.DateFormat, "date-format", historyDateLayout, "layout of the dates in the report (in the notation of go, i.e. \"02.01.2006 15:04\")")
+	flag.BoolVar(&options.Stdout, "stdout", false, "write the report to standard output instead of a file (informative messages are written to standard error)")
+	flag.StringVar(&options.CpuProfile, "cpuprofile", "", "write a cpu profile of the run to the given file")
+	flag.StringVar(&options.MemProfile, "memprofile", "", "write a memory profile at the end of the run to the given file")
+	flag.BoolVar(&options.Pprof, "pprof", false, "serve the profiles of the process at /debug/pprof/ of the web interface")
+	flag.StringVar(&options.CacheFile, "cache", "", "file remembering the etag and last modification of the responses, so repeated runs make conditional requests")
+	flag.IntVar(&options.HostConnections, "host-connections", 0, "maximum number of requests sent to the same host at the same time, 1 checks the links of a host one after another over a kept-alive connection (0 for no limit)")
+	flag.BoolVar(&options.Head, "head", false, "check hyperlinks with head requests (falling back to get if a server does not support them)")
+	flag.IntVar(&options.MaxRedirects, "max-redirects", 10, "maximum number of redirects followed before a link is reported as broken")
+	flag.DurationVar(&options.RetryTimeout, "retry-timeout", 0, "check the hyperlinks whose request failed again in a second pass with the given (longer) timeout")
+	flag.IntVar(&options.NetworkProbes, "network-probes", 10, "consider the network unavailable if this many first requests fail to resolve or connect (0 to disable)")
+	flag.StringVar(&options.Zone, "zone", "local", "network zone the run checks the links from (i.e. intranet), links of other zones are checked by the workers of the configuration")
+	flag.StringVar(&options.SigningKey, "signing-key", "", "sign the json results with the given ed25519 private key (pem), the signature is written next to them and shown in the html report")
+	flag.BoolVar(&options.StripTracking, "strip-tracking", false, "remove tracking parameters (i.e. utm_source or fbclid) before checking the links and suggest the clean urls in the report")
+	flag.BoolVar(&options.SuggestReplacements, "suggest-replacements", false, "suggest replacements for pages that are gone (404 or 410), i.e. the closest parent page or the search of the site")
+	flag.IntVar(&options.TopBrokenDomains, "top-broken-domains", 10, "rank the given number of domains responsible for the most broken links in the report (0 to disable)")
+	flag.BoolVar(&options.Whois, "whois", false, "look up the organizations holding the top broken domains in whois")
+	flag.BoolVar(&options.UrlSources, "url-sources", false, "list the number of links and the documents linking to each url with -format urls (tab separated)")
+	flag.StringVar(&options.StatusOverrides, "status-overrides", "", "use the status of the urls reported by another system (csv with url, status and reason or json) instead of checking them")
+	flag.BoolVar(&options.PlainTextUrls, "plain-text-urls", false, "also check the urls written as plain text in the documents and slides (which cannot be clicked)")
+	flag.BoolVar(&options.QrCodes, "qr-codes", false, "also check the urls encoded in qr codes of the images embedded in the documents (requires zbarimg or -qr-decoder)")
+	flag.StringVar(&options.QrDecoder, "qr-decoder", "", "command decoding the qr codes of an image, {file} is replaced with the image (defaults to zbarimg --quiet --raw {file})")
+	flag.BoolVar(&options.AuditLinkTexts, "audit-link-texts", false, "list the links whose display text is the url itself or a generic text like click here (accessibility audit)")
+	flag.BoolVar(&options.CheckHomoglyphs, "check-homoglyphs", true, "warn about hostnames mixing scripts or looking like the protected domains of the configuration (i.e. with cyrillic letters)")
+	flag.StringVar(&options.Scope, "scope", scopeAll, "links to check: external (outside of our network), internal (files and intranet hosts) or all")
+	flag.StringVar(&options.Sitemap, "sitemap", "", "sitemap.xml (file or url) of our intranet, links to its hosts whose page is not listed are reported")
+	flag.StringVar(&options.CodeQualityFile, "codequality", "", "write the issues found as gitlab code quality report to the given file")
+	flag.StringVar(&options.GraphFile, "graph", "", "write the graph of documents and the domains and documents they link to (.dot, .graphml or .json)")
+
+	shard := flag.String("shard", "", "only check the given part of the documents (i.e. 2/5), the reports of all parts can be combined with the merge command")
+	timezone := flag.String("timezone", "Local", "timezone of the dates in the report (i.e. Europe/Zurich, by default the timezone of the system)")
+	modifiedSince := flag.String("modified-since", "", "only check documents modified on or after the given date (yyyy-mm-dd)")
+
+	// environment variables are overridden by the flags given on the command line
+	err := applyEnvironmentOptions(flag.CommandLine)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	flag.CommandLine.Parse(arguments)
+
+	if *modifiedSince != "" {
+
+		date, err := time.ParseInLocation("2006-01-02", *modifiedSince, time.Local)
+		if err != nil {
+			log.Fatalln("ERROR: invalid date given for -modified-since:", *modifiedSince)
+		}
+
+		options.ModifiedSince = date
+
+	}
+
+	if *shard != "" {
+		options.Shard, err = parseShard(*shard)
+		if err != nil {
+			log.Fatalln("ERROR:", err)
+		}
+	}
+
+	options.Timezone, err = loadTimezone(*timezone)
+	if err != nil {
+		log.Fatalln("ERROR: unknown timezone given for -timezone:", *timezone)
+	}
+
+	// all remaining arguments are directories to validate
+	options.Roots = flag.Args()
+
+	err = validateSeverity(options.FailOn)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	err = validateOrder(options.Order)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	err = validateScope(options.Scope)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	if options.QrCodes {
+		err = validateQrDecoder()
+		if err != nil {
+			log.Fatalln("ERROR:", err)
+		}
+	}
+
+	if options.Format != "html" && options.Format != "json" && options.Format != formatUrls {
+		log.Fatalln("ERROR: unknown format " + options.Format + " (use html, json or urls)")
+	}
+
+	if options.GraphFile != "" {
+		err = validateGraphFile(options.GraphFile)
+		if err != nil {
+			log.Fatalln("ERROR:", err)
+		}
+	}
+
+	if options.SigningKey != "" {
+		signingKey, err = loadSigningKey(options.SigningKey)
+		if err != nil {
+			log.Fatalln("ERROR:", err)
+		}
+	}
+
+	// the report on standard output is meant for other programs, not for a browser
+	if options.Stdout {
+
+		err = validateStdout()
+		if err != nil {
+			log.Fatalln("ERROR:", err)
+		}
+
+		options.Open = false
+		console = os.Stderr
+
+	}
+
+	checker = &httpChecker{Timeout: options.Timeout, Proxy: options.Proxy}
+
+	if options.RetryTimeout > 0 {
+		retryChecker = &httpChecker{Timeout: options.RetryTimeout, Proxy: options.Proxy}
+	}
+
+	if options.SecondaryProxy != "" {
+		secondaryChecker = &httpChecker{Timeout: options.Timeout, Proxy: options.SecondaryProxy}
+	}
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+}
+
+// check if the run was started interactively on a desktop (and not on a server
+// or in continuous integration, where no one will look at an opened browser)
+func isInteractiveDesktop() bool {
+
+	// a process running as pid 1 is the entry point of a container
+	if os.Getenv("CI") != "" || os.Getpid() == 1 {
+		return false
+	}
+
+	fileInfo, err := os.Stdout.Stat()
+	if err != nil || fileInfo.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	// linux and other unix systems only have a browser within a graphical session
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	}
+
+	return true
+
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// define the orders in which the hyperlinks can be checked
+const (
+	// check the links in the order of the documents (while the documents are found)
+	orderDocument = "document"
+	// alternate between the hosts, so no server receives many requests in a row
+	orderHost = "host"
+	// check the links in random order
+	orderRandom = "random"
+)
+
+// the random numbers used for the order and the jitter of the requests
+var (
+	random      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMutex sync.Mutex
+)
+
+// check the order given on the command line
+func validateOrder(order string) error {
+
+	switch order {
+	case orderDocument, orderHost, orderRandom:
+		return nil
+	}
+
+	return errors.New("unknown order " + order + " (use document, host or random)")
+
+}
+
+// sort the jobs according to the order of the options
+func orderJobs(jobs []linkJob) []linkJob {
+
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+
+	switch options.Order {
+
+	case orderRandom:
+		random.Shuffle(len(jobs), func(i, j int) {
+			jobs[i], jobs[j] = jobs[j], jobs[i]
+		})
+
+	case orderHost:
+
+		// take one link of every host in turn (the hosts in random order)
+		hosts := []string{}
+		jobsByHost := make(map[string][]linkJob)
+
+		for _, job := range jobs {
+
+			host := urlDomain(job.link.Url)
+
+			if _, exists := jobsByHost[host]; exists == false {
+				hosts = append(hosts, host)
+			}
+
+			jobsByHost[host] = append(jobsByHost[host], job)
+
+		}
+
+		sort.Strings(hosts)
+		random.Shuffle(len(hosts), func(i, j int) {
+			hosts[i], hosts[j] = hosts[j], hosts[i]
+		})
+
+		ordered := []linkJob{}
+
+		for len(ordered) < len(jobs) {
+			for _, host := range hosts {
+				if len(jobsByHost[host]) > 0 {
+					ordered = append(ordered, jobsByHost[host][0])
+					jobsByHost[host] = jobsByHost[host][1:]
+				}
+			}
+		}
+
+		return ordered
+
+	}
+
+	return jobs
+
+}
+
+// wait for a random time up to the jitter of the options before a request
+func waitForJitter() {
+
+	if options.Jitter <= 0 {
+		return
+	}
+
+	randomMutex.Lock()
+	delay := time.Duration(random.Int63n(int64(options.Jitter)))
+	randomMutex.Unlock()
+
+	time.Sleep(delay)
+
+}
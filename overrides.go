@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the reason of links reported broken by the status overrides
+const reasonOverriddenBroken = "reported broken by another system"
+
+// the status of the urls reported by other systems (i.e. the logs of our proxy),
+// by normalized url
+var statusOverrides map[string]StatusOverride
+
+// define a custom structure for the status of a url reported by another system,
+// the status is either valid, broken or the http status code seen
+type StatusOverride struct {
+	Url    string `json:"url"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+
+	isWorking bool
+}
+
+// load the status overrides from the given csv (url, status and an optional
+// reason per line) or json file (a list of objects with the same fields)
+func loadStatusOverrides(path string) (map[string]StatusOverride, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []StatusOverride{}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+
+		err = json.Unmarshal(data, &entries)
+		if err != nil {
+			return nil, err
+		}
+
+	} else {
+
+		reader := csv.NewReader(strings.NewReader(string(data)))
+		reader.FieldsPerRecord = -1
+		reader.Comment = '#'
+
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+
+			if len(record) < 2 {
+				return nil, errors.New("the line " + strings.Join(record, ",") + " needs an url and a status")
+			}
+
+			// a header line may name the columns
+			if strings.EqualFold(strings.TrimSpace(record[0]), "url") {
+				continue
+			}
+
+			entry := StatusOverride{Url: strings.TrimSpace(record[0]), Status: strings.TrimSpace(record[1])}
+			if len(record) > 2 {
+				entry.Reason = strings.TrimSpace(record[2])
+			}
+
+			entries = append(entries, entry)
+
+		}
+
+	}
+
+	overrides := make(map[string]StatusOverride)
+
+	for _, entry := range entries {
+
+		isWorking, err := parseOverrideStatus(entry.Status)
+		if err != nil {
+			return nil, errors.New("invalid status " + entry.Status + " of " + entry.Url)
+		}
+
+		entry.isWorking = isWorking
+		overrides[normalizeUrl(entry.Url)] = entry
+
+	}
+
+	return overrides, nil
+
+}
+
+// check if the given status of an override means the link is working (status
+// codes below 400 are working)
+func parseOverrideStatus(status string) (bool, error) {
+
+	switch strings.ToLower(status) {
+	case "valid", "ok", "working":
+		return true, nil
+	case "broken", "invalid":
+		return false, nil
+	}
+
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 100 || code > 599 {
+		return false, errors.New("invalid status")
+	}
+
+	return code < 400, nil
+
+}
+
+// apply the status reported by another system to the link instead of checking
+// it (returns false if no status is reported for the link)
+func (link *Hyperlink) applyStatusOverride() bool {
+
+	if len(statusOverrides) == 0 {
+		return false
+	}
+
+	override, exists := statusOverrides[normalizeUrl(link.RequestUrl)]
+	if exists == false {
+		override, exists = statusOverrides[normalizeUrl(link.Url)]
+	}
+
+	if exists == false {
+		return false
+	}
+
+	link.IsOverridden = true
+	link.IsWorking = override.isWorking
+
+	if override.isWorking == false {
+		link.Reason = reasonOverriddenBroken
+		if override.Reason != "" {
+			link.Reason += " (" + override.Reason + ")"
+		}
+	}
+
+	return true
+
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// define a custom structure mapping the documents matching a pattern to their owner
+type OwnerMapping struct {
+	Pattern string `json:"pattern"`
+	Owner   string `json:"owner"`
+
+	matcher *regexp.Regexp
+}
+
+// define a custom structure aggregating the results of all documents of an owner
+type OwnerSummary struct {
+	Owner     string
+	Documents int
+	Invalid   int
+}
+
+// get the owner of the document with the given path (the first matching mapping wins)
+func documentOwner(documentPath string) string {
+
+	// patterns are written with forward slashes on all platforms
+	normalizedPath := filepath.ToSlash(documentPath)
+
+	for _, mapping := range config.Owners {
+		if mapping.matcher.MatchString(normalizedPath) {
+			return mapping.Owner
+		}
+	}
+
+	return ""
+
+}
+
+// aggregate the results of all documents by their owner
+func summarizeOwners(documents []Document) []OwnerSummary {
+
+	summaries := make(map[string]*OwnerSummary)
+
+	for _, document := range documents {
+
+		if document.Owner == "" {
+			continue
+		}
+
+		summary, exists := summaries[document.Owner]
+		if exists == false {
+			summary = &OwnerSummary{Owner: document.Owner}
+			summaries[document.Owner] = summary
+		}
+
+		summary.Documents++
+
+		if document.IsValid == false {
+			summary.Invalid++
+		}
+
+	}
+
+	owners := []OwnerSummary{}
+
+	for _, summary := range summaries {
+		owners = append(owners, *summary)
+	}
+
+	sort.Slice(owners, func(i, j int) bool {
+		return owners[i].Owner < owners[j].Owner
+	})
+
+	return owners
+
+}
+
+// get the part of the report containing only the documents of the given owner
+// (i.e. to hand each owner only their section)
+func (report *Report) forOwner(owner string) Report {
+
+	section := *report
+	section.Documents = []Document{}
+	section.ResultOfValidation = true
+
+	for _, document := range report.Documents {
+
+		if document.Owner != owner {
+			continue
+		}
+
+		section.Documents = append(section.Documents, document)
+
+		if document.IsValid == false {
+			section.ResultOfValidation = false
+		}
+
+	}
+
+	section.Domains = summarizeDomains(section.Documents)
+	section.BrokenDomains = rankBrokenDomains(section.Domains)
+	section.Owners = summarizeOwners(section.Documents)
+	section.name = matchers["reportName"].ReplaceAllString(owner, "-")
+
+	// the reports of several roots are distinguished by the name of the root
+	if report.name != "" {
+		section.name = report.name + "-" + section.name
+	}
+
+	return section
+
+}
+
+// create a separate report for each owner of the documents of the report
+func (report *Report) createOwnerReports() {
+
+	for _, summary := range report.Owners {
+
+		section := report.forOwner(summary.Owner)
+		section.create()
+
+	}
+
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// define the roles of the parts of a package we extract hyperlinks from
+const (
+	// parts of word documents, which may contain hyperlink fields
+	partText = "text"
+	// slides of powerpoint presentations
+	partSlide = "slide"
+	// worksheets of excel workbooks, which may contain hyperlink formulas
+	partWorksheet = "worksheet"
+)
+
+// parts are streamed instead of being read into memory, but parts larger than this
+// (i.e. media wrongly declared as xml) are skipped to keep the memory bounded
+const maxPartSize = 64 << 20
+
+// the text collected from a part (i.e. the text of a link) is truncated to this length
+const maxTextLength = 8192
+
+// define the error reported for parts exceeding the maximum size
+var errPartTooLarge = errors.New("part is too large")
+
+// define a custom structure for a part opened for reading
+type partReader struct {
+	io.Reader
+	io.Closer
+}
+
+// open the part of the package for reading (without reading more than the maximum
+// part size, even if the size given in the archive is wrong)
+func openPart(file *zip.File) (io.ReadCloser, error) {
+
+	if file.UncompressedSize64 > maxPartSize {
+		return nil, errPartTooLarge
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	return partReader{Reader: io.LimitReader(reader, maxPartSize), Closer: reader}, nil
+
+}
+
+// define the roles of the content types declared in [Content_Types].xml
+var contentTypeRoles = map[string]string{
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml": partText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.template.main+xml": partText,
+	"application/vnd.ms-word.document.macroenabled.main+xml":                           partText,
+	"application/vnd.ms-word.template.macroenabledtemplate.main+xml":                   partText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml":        partText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml":        partText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.footnotes+xml":     partText,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.endnotes+xml":      partText,
+	"application/vnd.openxmlformats-officedocument.presentationml.slide+xml":           partSlide,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml":        partWorksheet,
+}
+
+// the role of the parts referencing the relationships matched by the fallback
+// matchers of each document type
+var fallbackPartRoles = map[string]string{
+	".docx": partText,
+	".pptx": partSlide,
+	".xlsx": partWorksheet,
+}
+
+// the relationship type of the main part of the package ends with this name
+const officeDocumentRelationship = "officeDocument"
+
+// find the parts of the package we extract hyperlinks from by following the
+// declared content types and the relationships of the package, instead of
+// relying on the paths used by microsoft office (returned with lower case names,
+// as part names are case insensitive)
+func findContentParts(parts map[string]*zip.File, documentType string) map[string]string {
+
+	contentParts := make(map[string]string)
+
+	if file, exists := parts["[content_types].xml"]; exists {
+
+		defaults, overrides := readContentTypes(file)
+
+		for name := range parts {
+
+			contentType, isOverridden := overrides[name]
+			if isOverridden == false {
+				contentType = defaults[strings.TrimPrefix(path.Ext(name), ".")]
+			}
+
+			if role, exists := contentTypeRoles[strings.ToLower(contentType)]; exists {
+				contentParts[name] = role
+			}
+
+		}
+
+	}
+
+	// some writers do not declare the content type of the main part of word
+	// documents (which contains the text, unlike the main part of the other types)
+	if documentType == ".docx" {
+		if mainPart := packageMainPart(parts); mainPart != "" && parts[mainPart] != nil {
+			contentParts[mainPart] = partText
+		}
+	}
+
+	if len(contentParts) > 0 {
+		return contentParts
+	}
+
+	// fall back to the paths used by microsoft office for packages without valid
+	// content types
+	for name := range parts {
+
+		switch {
+		case documentType == ".docx" && name == "word/document.xml":
+			contentParts[name] = partText
+		case documentType == ".xlsx" && matchers["worksheet"].MatchString(name):
+			contentParts[name] = partWorksheet
+		case matchers[documentType].MatchString(name):
+			contentParts[sourcePartName(name)] = fallbackPartRoles[documentType]
+		}
+
+	}
+
+	return contentParts
+
+}
+
+// read the content types of the package (by extension and by part name)
+func readContentTypes(file *zip.File) (map[string]string, map[string]string) {
+
+	defaults := make(map[string]string)
+	overrides := make(map[string]string)
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the content types")
+		return defaults, overrides
+	}
+	defer fileContentReader.Close()
+
+	decoder := newTolerantDecoder(fileContentReader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return defaults, overrides
+		}
+		if err != nil {
+			log.Println("ERROR: could not read the content types")
+			return defaults, overrides
+		}
+
+		element, isStartElement := token.(xml.StartElement)
+		if isStartElement == false {
+			continue
+		}
+
+		switch element.Name.Local {
+		case "Default":
+			defaults[strings.ToLower(attributeValue(element, "Extension"))] = attributeValue(element, "ContentType")
+		case "Override":
+			partName := strings.TrimPrefix(attributeValue(element, "PartName"), "/")
+			overrides[strings.ToLower(partName)] = attributeValue(element, "ContentType")
+		}
+
+	}
+
+}
+
+// get the name of the main part of the package from the package relationships
+func packageMainPart(parts map[string]*zip.File) string {
+
+	file, exists := parts["_rels/.rels"]
+	if exists == false {
+		return ""
+	}
+
+	relationships, _ := readRelationships(file)
+
+	for _, relationship := range relationships {
+		if path.Base(relationship.Type) == officeDocumentRelationship {
+			return strings.ToLower(resolvePartName(file.Name, relationship.Target))
+		}
+	}
+
+	return ""
+
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+)
+
+// define the reason reported for links to parked domains
+const reasonParkedDomain = "parked domain"
+
+// the content needed to recognize parking pages (these are mostly small)
+const parkingContentBytes = 64 * 1024
+
+// the hosts of parking services and domain marketplaces parked domains redirect to
+var parkingHosts = []string{
+	"sedoparking.com", "sedo.com", "parkingcrew.net", "bodis.com", "dan.com",
+	"afternic.com", "hugedomains.com", "above.com", "parklogic.com", "undeveloped.com",
+	"domainmarket.com", "buydomains.com",
+}
+
+// markers found on the templates of registrars and parking services
+var strongParkingMarkers = []string{
+	"this domain is for sale", "buy this domain", "this domain may be for sale",
+	"the domain name is for sale", "domain is parked", "this domain is parked",
+	"sedoparking", "parkingcrew", "bodis.com", "parked free, courtesy of",
+	"this domain has been registered", "inquire about this domain",
+}
+
+// markers also found on regular pages, which only mark a parked domain together
+// with wildcard dns (parked domains resolve every subdomain)
+var weakParkingMarkers = []string{
+	"for sale", "related searches", "sponsored listings", "make an offer",
+}
+
+// remember the domains with wildcard dns (the lookups are done once per domain)
+var (
+	wildcardDomains = make(map[string]bool)
+	wildcardMutex   sync.Mutex
+)
+
+// check if the response of the link is a parking page (even though the request
+// was successful), the reason explains which heuristic recognized it
+func isParkedDomain(link *Hyperlink, response *CheckResponse) (bool, string) {
+
+	if response == nil {
+		return false, ""
+	}
+
+	// requests to parked domains are often redirected to a marketplace
+	finalHost := urlDomain(response.FinalUrl)
+
+	for _, parkingHost := range parkingHosts {
+		if finalHost == parkingHost || strings.HasSuffix(finalHost, "."+parkingHost) {
+			return true, "redirected to " + finalHost
+		}
+	}
+
+	content := bytes.ToLower(response.Body)
+
+	for _, marker := range strongParkingMarkers {
+		if bytes.Contains(content, []byte(marker)) {
+			return true, "page contains \"" + marker + "\""
+		}
+	}
+
+	for _, marker := range weakParkingMarkers {
+		if bytes.Contains(content, []byte(marker)) && hasWildcardDns(urlDomain(link.RequestUrl)) {
+			return true, "page contains \"" + marker + "\" and every subdomain resolves"
+		}
+	}
+
+	return false, ""
+
+}
+
+// check if a random subdomain of the domain of the host resolves to the same
+// address as the host itself
+func hasWildcardDns(host string) bool {
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 || net.ParseIP(host) != nil {
+		return false
+	}
+
+	// strip the subdomain (i.e. www) of the host
+	domain := host
+	if len(labels) > 2 {
+		domain = strings.Join(labels[1:], ".")
+	}
+
+	wildcardMutex.Lock()
+	defer wildcardMutex.Unlock()
+
+	if isWildcard, exists := wildcardDomains[domain]; exists {
+		return isWildcard
+	}
+
+	wildcardDomains[domain] = false
+
+	hostAddresses, err := net.LookupHost(host)
+	if err != nil {
+		return false
+	}
+
+	randomLabel := make([]byte, 8)
+	rand.Read(randomLabel)
+
+	randomAddresses, err := net.LookupHost("vl-" + hex.EncodeToString(randomLabel) + "." + domain)
+	if err != nil {
+		return false
+	}
+
+	for _, address := range randomAddresses {
+		if containsString(hostAddresses, address) {
+			wildcardDomains[domain] = true
+			return true
+		}
+	}
+
+	return false
+
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"html/template"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// windows paths with this prefix are not limited to 260 characters
+const (
+	extendedLengthPrefix    = `\\?\`
+	extendedLengthUncPrefix = `\\?\UNC\`
+)
+
+// windows still limits paths without the extended length prefix to 260
+// characters (including the terminating null character of the api)
+const maxWindowsPath = 248
+
+// check if the path is a unc path (i.e. \\server\share\directory)
+func isUncPath(path string) bool {
+	return strings.HasPrefix(path, `\\`) && strings.HasPrefix(path, extendedLengthPrefix) == false
+}
+
+// normalize a directory given on the command line or in the configuration, the
+// root of a share needs a trailing separator to be accessible (\\server\share\)
+func normalizeRoot(root string) string {
+
+	if runtime.GOOS != "windows" {
+		return root
+	}
+
+	root = filepath.FromSlash(root)
+
+	if isUncPath(root) && strings.Count(strings.TrimRight(root[2:], `\`), `\`) == 1 {
+		return strings.TrimRight(root, `\`) + `\`
+	}
+
+	return root
+
+}
+
+// get the path with the extended length prefix, so windows accepts paths longer
+// than 260 characters (the path is returned unchanged on other systems)
+func extendedLengthPath(path string) string {
+
+	if runtime.GOOS != "windows" || strings.HasPrefix(path, extendedLengthPrefix) {
+		return path
+	}
+
+	path = filepath.Clean(getAbsoluteFilePath(path))
+
+	if isUncPath(path) {
+		return extendedLengthUncPrefix + path[2:]
+	}
+
+	return extendedLengthPrefix + path
+
+}
+
+// get the path with the extended length prefix only if the path is too long to be
+// accessed without it
+func longPath(path string) string {
+
+	if len(path) < maxWindowsPath {
+		return path
+	}
+
+	return extendedLengthPath(path)
+
+}
+
+// get the url to open the file with the given path from the report, the path is
+// converted to forward slashes and percent-encoded (i.e. file:///C:/Studien/%C3%BCbersicht.docx
+// on windows, file:///home/user/a%20b.docx elsewhere), files on shares are linked as
+// file://server/share/path
+func fileUrl(path string) template.URL {
+
+	path = getAbsoluteFilePath(path)
+
+	fileUrl := url.URL{Scheme: "file", Path: "/"}
+
+	switch {
+
+	case isUncPath(path):
+		parts := strings.SplitN(filepath.ToSlash(path[2:]), "/", 2)
+
+		fileUrl.Host = parts[0]
+		if len(parts) > 1 {
+			fileUrl.Path += parts[1]
+		}
+
+	case isWindowsDrivePath(path):
+		fileUrl.Path += filepath.ToSlash(path)
+
+	default:
+		fileUrl.Path = filepath.ToSlash(path)
+
+	}
+
+	// the url package omits the empty host of local files
+	if fileUrl.Host == "" {
+		return template.URL("file://" + fileUrl.EscapedPath())
+	}
+
+	return template.URL(fileUrl.String())
+
+}
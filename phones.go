@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// define the reasons and warnings reported for phone links
+const (
+	reasonMalformedPhoneNumber = "malformed phone number"
+	warningPhoneNotInDirectory = "phone number is not listed in the directory"
+)
+
+// international phone numbers (e.164) consist of a plus sign and up to 15 digits
+var internationalPhoneNumber = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// define a validator checking the syntax of tel: and sms: links (and optionally
+// if the number is listed in the phone directory of the configuration)
+type phoneValidator struct {
+	once      sync.Once
+	directory map[string]bool
+}
+
+// the validator handles all phone links
+func (validator *phoneValidator) Matches(link *Hyperlink) bool {
+
+	scheme := linkScheme(link.Url)
+
+	return scheme == "tel" || scheme == "sms"
+
+}
+
+// check the number of the link
+func (validator *phoneValidator) Validate(link *Hyperlink) {
+
+	number := phoneNumber(link.Url)
+
+	if isValidPhoneNumber(number) == false {
+		link.IsWorking = false
+		link.Reason = reasonMalformedPhoneNumber
+		return
+	}
+
+	link.IsWorking = true
+
+	validator.once.Do(validator.loadDirectory)
+
+	if validator.directory != nil && validator.directory[normalizePhoneNumber(number)] == false {
+		link.Warnings = append(link.Warnings, warningPhoneNotInDirectory)
+	}
+
+}
+
+// get the number of a tel: or sms: link without the scheme, the parameters
+// (i.e. ;ext=12) and the message body of sms links
+func phoneNumber(link string) string {
+
+	number := link[strings.Index(link, ":")+1:]
+	number = strings.TrimPrefix(number, "//")
+
+	if end := strings.IndexAny(number, ";?"); end >= 0 {
+		number = number[:end]
+	}
+
+	if unescaped, err := url.PathUnescape(number); err == nil {
+		number = unescaped
+	}
+
+	return strings.TrimSpace(number)
+
+}
+
+// check if the number is an international number or matches one of the national
+// formats of the configuration
+func isValidPhoneNumber(number string) bool {
+
+	if internationalPhoneNumber.MatchString(removePhoneSeparators(number)) {
+		return true
+	}
+
+	for _, format := range config.phoneFormats {
+		if format.MatchString(number) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// remove the visual separators allowed in phone numbers (see rfc 3966)
+func removePhoneSeparators(number string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "").Replace(number)
+}
+
+// normalize the number to the international format to compare it with the
+// directory (national numbers are prefixed with the country code of the configuration)
+func normalizePhoneNumber(number string) string {
+
+	number = removePhoneSeparators(number)
+
+	switch {
+	case strings.HasPrefix(number, "00"):
+		number = "+" + number[2:]
+	case strings.HasPrefix(number, "0") && config.PhoneCountryCode != "":
+		number = "+" + strings.TrimPrefix(config.PhoneCountryCode, "+") + number[1:]
+	}
+
+	return number
+
+}
+
+// read the numbers of the phone directory (every field of the csv file that
+// contains a valid phone number is considered a listed number)
+func (validator *phoneValidator) loadDirectory() {
+
+	if config.PhoneDirectory == "" {
+		return
+	}
+
+	file, err := os.Open(config.PhoneDirectory)
+	if err != nil {
+		log.Println("ERROR: could not open the phone directory")
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		log.Println("ERROR: could not read the phone directory")
+		return
+	}
+
+	validator.directory = make(map[string]bool)
+
+	for _, record := range records {
+		for _, field := range record {
+			if isValidPhoneNumber(strings.TrimSpace(field)) {
+				validator.directory[normalizePhoneNumber(strings.TrimSpace(field))] = true
+			}
+		}
+	}
+
+}
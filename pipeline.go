@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// define a custom structure for a hyperlink that should be checked
+type linkJob struct {
+	documentIndex int
+	linkIndex     int
+	documentPath  string
+	link          Hyperlink
+}
+
+// define a custom structure for the result of a hyperlink check
+type linkResult struct {
+	documentIndex int
+	linkIndex     int
+	link          Hyperlink
+}
+
+// the aggregator receives newly found documents and checked hyperlinks over the
+// same channel, so a document is always known before any of its results arrive
+type pipelineEvent struct {
+	document     *Document
+	result       *linkResult
+	documentCopy *documentCopy
+}
+
+// check all documents in the given directory and return them together with the
+// documents excluded because of their age
+func getAndCheckFilesInDirectory(rootDirectory string) ([]Document, []ExcludedDocument) {
+
+	var fileChannel chan Document = make(chan Document)
+	var jobs chan linkJob = make(chan linkJob)
+	var events chan pipelineEvent = make(chan pipelineEvent)
+	var aggregated chan []Document = make(chan []Document)
+
+	// walk recursively through our directory in a separate thread
+	// send each matching file into our file channel
+	go walkDirectory(rootDirectory, fileChannel)
+
+	// check the hyperlinks with a fixed number of workers
+	var workers sync.WaitGroup
+
+	// the links whose request failed are checked again after all others (if requested)
+	retries := &retryQueue{}
+
+	for index := 0; index < options.Concurrency; index++ {
+		workers.Add(1)
+		go checkHyperlinks(jobs, events, retries, &workers)
+	}
+
+	// a single routine is collecting all results (and is the only one modifying the documents)
+	go aggregateResults(events, aggregated)
+
+	// for each file we find, we get all links and send them to the workers
+	documentIndex := 0
+	excludedDocuments := []ExcludedDocument{}
+
+	// remember the index of the documents checked by the hash of their content
+	checkedContents := make(map[string]int)
+
+	// the links are only checked in another order once all documents are read
+	orderedJobs := []linkJob{}
+
+	for file := range fileChannel {
+
+		// the other documents are checked by the other shards
+		if options.Shard.contains(rootDirectory, file.Path) == false {
+			continue
+		}
+
+		// archived documents are not checked over and over again
+		if options.ModifiedSince.IsZero() == false {
+
+			file.readModificationDate()
+
+			if file.isTooOld() {
+				excludedDocuments = append(excludedDocuments, ExcludedDocument{Path: file.Path, Modified: file.Modified})
+				continue
+			}
+
+		}
+
+		// identical copies of a document are only checked once
+		if options.Deduplicate {
+
+			hash, err := hashDocument(file.Path)
+
+			if err == nil {
+
+				if index, exists := checkedContents[hash]; exists {
+					events <- pipelineEvent{documentCopy: &documentCopy{documentIndex: index, path: file.Path}}
+					continue
+				}
+
+				checkedContents[hash] = documentIndex
+
+			}
+
+		}
+
+		progress.documentStarted(&file)
+
+		// remember who is responsible for the document
+		file.Owner = documentOwner(file.Path)
+
+		// the policy file of the directory may be stricter (or less strict) than the options
+		file.Severity = documentPolicy(file.Path).Severity
+
+		// get all hyperlinks from the document
+		file.Hyperlinks, file.OrphanedLinks = extractHyperlinksFromDocument(&file)
+
+		// links with known issues are ignored until the date of the configuration
+		file.Hyperlinks = file.applyIgnores(file.Hyperlinks)
+
+		// point out display texts that do not describe the target (if requested)
+		if options.AuditLinkTexts {
+			file.auditLinkTexts()
+		}
+
+		// point out links that were probably copied and pasted by mistake
+		file.findDuplicateLinks(options.RepeatedLinks)
+
+		// point out documents that should rather use a reference list
+		file.checkQuotas()
+
+		// register the document before any of its hyperlinks is checked
+		document := file
+		events <- pipelineEvent{document: &document}
+
+		for linkIndex, link := range file.Hyperlinks {
+
+			job := linkJob{documentIndex: documentIndex, linkIndex: linkIndex, documentPath: file.Path, link: link}
+
+			if options.Order != orderDocument {
+				orderedJobs = append(orderedJobs, job)
+				continue
+			}
+
+			jobs <- job
+
+		}
+
+		documentIndex++
+
+	}
+
+	for _, job := range orderJobs(orderedJobs) {
+		jobs <- job
+	}
+
+	// wait until all hyperlinks are checked
+	close(jobs)
+	workers.Wait()
+
+	// check the failed hyperlinks again with the longer timeout in a second pass
+	if len(retries.jobs) > 0 {
+
+		fmt.Fprintf(console, "checking %d failed links again\n", len(retries.jobs))
+
+		secondPass := make(chan linkJob)
+
+		for index := 0; index < options.Concurrency; index++ {
+			workers.Add(1)
+			go checkHyperlinks(secondPass, events, retries, &workers)
+		}
+
+		for _, job := range retries.jobs {
+			job.link.isSecondPass = true
+			secondPass <- job
+		}
+
+		close(secondPass)
+		workers.Wait()
+
+	}
+
+	// we are finished with finding and checking all elements
+	close(events)
+	documents := <-aggregated
+
+	fmt.Fprintln(console, "we are done with these files")
+
+	return documents, excludedDocuments
+
+}
+
+// define a custom structure collecting the hyperlinks to check again in a second pass
+type retryQueue struct {
+	mutex sync.Mutex
+	jobs  []linkJob
+}
+
+// add the given job to the second pass
+func (queue *retryQueue) add(job linkJob) {
+
+	queue.mutex.Lock()
+	defer queue.mutex.Unlock()
+
+	queue.jobs = append(queue.jobs, job)
+
+}
+
+// check all hyperlinks received until the job channel is closed
+func checkHyperlinks(jobs chan linkJob, events chan pipelineEvent, retries *retryQueue, workers *sync.WaitGroup) {
+
+	for job := range jobs {
+
+		fmt.Fprintln(console, "-- checking link: "+redactUrl(job.link.Url))
+
+		// the worker only modifies its own copy of the hyperlink
+		link := job.link
+		started := time.Now()
+		link.validate(job.documentPath)
+
+		// slow hosts get a second chance once all other links are checked
+		if link.requestFailed && options.RetryTimeout > 0 && job.link.isSecondPass == false {
+			retries.add(job)
+			continue
+		}
+
+		metrics.linkChecked(&link, time.Since(started))
+
+		// preserve a copy of the working target (if requested)
+		if options.Archive {
+			archiver.submit(&link)
+		}
+
+		// show what broken and suspect targets render (if requested)
+		if options.ScreenshotDirectory != "" && link.needsScreenshot() {
+			link.captureScreenshot(job.documentPath)
+		}
+
+		events <- pipelineEvent{result: &linkResult{documentIndex: job.documentIndex, linkIndex: job.linkIndex, link: link}}
+
+	}
+
+	workers.Done()
+
+}
+
+// collect all documents and results of the hyperlink checks until the event channel
+// is closed and return the checked documents on the aggregated channel
+func aggregateResults(events chan pipelineEvent, aggregated chan []Document) {
+
+	documents := []Document{}
+
+	// remember the number of hyperlinks still to be checked per document
+	pending := []int{}
+
+	for event := range events {
+
+		if event.documentCopy != nil {
+			document := &documents[event.documentCopy.documentIndex]
+			document.Copies = append(document.Copies, event.documentCopy.path)
+			continue
+		}
+
+		if event.document != nil {
+
+			documents = append(documents, *event.document)
+			pending = append(pending, len(event.document.Hyperlinks))
+
+			// documents without hyperlinks are finished right away
+			if len(event.document.Hyperlinks) == 0 {
+				finishDocument(&documents[len(documents)-1])
+			}
+
+			continue
+
+		}
+
+		result := event.result
+		document := &documents[result.documentIndex]
+
+		document.Hyperlinks[result.linkIndex] = result.link
+		progress.linkChecked(document, &document.Hyperlinks[result.linkIndex])
+
+		pending[result.documentIndex]--
+
+		if pending[result.documentIndex] == 0 {
+			finishDocument(document)
+		}
+
+	}
+
+	aggregated <- documents
+
+}
+
+// update the validity of a document once all of its hyperlinks are checked
+func finishDocument(document *Document) {
+
+	// the document is only valid if all hyperlinks are working
+	document.updateValidity()
+	metrics.documentScanned()
+
+	progress.documentFinished(document)
+
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// define a checker counting its requests and the requests checked at the same time
+type countingChecker struct {
+	mutex       sync.Mutex
+	calls       map[string]int
+	inFlight    int
+	maxInFlight int
+	// the requests to urls containing the string fail
+	failing string
+}
+
+// check the url without any network request, the later links of a document take
+// less time, so their results arrive before the results of the earlier links
+func (checker *countingChecker) Check(url string) (*CheckResponse, error) {
+
+	checker.mutex.Lock()
+	checker.calls[url]++
+	checker.inFlight++
+	if checker.inFlight > checker.maxInFlight {
+		checker.maxInFlight = checker.inFlight
+	}
+	checker.mutex.Unlock()
+
+	var index int
+	fmt.Sscanf(url[strings.LastIndex(url, "/")+1:], "%d", &index)
+	time.Sleep(time.Duration(10-index) * 2 * time.Millisecond)
+
+	checker.mutex.Lock()
+	checker.inFlight--
+	checker.mutex.Unlock()
+
+	if checker.failing != "" && strings.Contains(url, checker.failing) {
+		return nil, errors.New("connection timed out")
+	}
+
+	return &CheckResponse{StatusCode: 200, FinalUrl: url}, nil
+
+}
+
+// get the targets of the hyperlinks of a generated pipeline document
+func pipelineTargets(document int, links int) []string {
+
+	targets := make([]string, links)
+
+	for index := range targets {
+		targets[index] = fmt.Sprintf("http://pipeline.test/document-%d/%d", document, index)
+	}
+
+	return targets
+
+}
+
+// write a directory with the given number of word documents and hyperlinks
+func writePipelineDocuments(t *testing.T, documents int, links int) string {
+
+	directory := t.TempDir()
+
+	for document := 0; document < documents; document++ {
+
+		path := filepath.Join(directory, fmt.Sprintf("document-%d.docx", document))
+
+		err := writeWordDocument(path, pipelineTargets(document, links))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+	}
+
+	return directory
+
+}
+
+// replace the checkers and the options of the pipeline for a single test
+func usePipelineCheckers(t *testing.T, first Checker, second Checker) {
+
+	previousOptions, previousChecker, previousRetryChecker := options, checker, retryChecker
+
+	t.Cleanup(func() {
+		options, checker, retryChecker = previousOptions, previousChecker, previousRetryChecker
+	})
+
+	checker, retryChecker = first, second
+
+}
+
+// check that the links are checked by all workers at the same time and that every
+// link is checked (and aggregated) exactly once
+func TestPipelineFanOut(t *testing.T) {
+
+	directory := writePipelineDocuments(t, 3, 10)
+
+	first := &countingChecker{calls: map[string]int{}}
+	usePipelineCheckers(t, first, nil)
+
+	options.Concurrency = 4
+
+	documents, _ := getAndCheckFilesInDirectory(directory)
+
+	if first.maxInFlight < 2 || first.maxInFlight > options.Concurrency {
+		t.Errorf("%d links were checked at the same time by %d workers", first.maxInFlight, options.Concurrency)
+	}
+
+	if len(first.calls) != 30 {
+		t.Errorf("%d of 30 links were checked", len(first.calls))
+	}
+
+	for url, calls := range first.calls {
+		if calls != 1 {
+			t.Errorf("%s was checked %d times", url, calls)
+		}
+	}
+
+	if len(documents) != 3 {
+		t.Fatalf("%d of 3 documents were aggregated", len(documents))
+	}
+
+	for _, document := range documents {
+
+		if document.IsValid == false {
+			t.Errorf("%s was not finished with all working links", document.Path)
+		}
+
+		for _, link := range document.Hyperlinks {
+			if link.IsWorking == false {
+				t.Errorf("the result of %s was not aggregated", link.Url)
+			}
+		}
+
+	}
+
+}
+
+// check that the failed links are checked again in a second pass with the retry
+// checker (and only the links failing twice are broken)
+func TestPipelineRetry(t *testing.T) {
+
+	directory := writePipelineDocuments(t, 2, 5)
+
+	first := &countingChecker{calls: map[string]int{}, failing: "/document-"}
+	second := &countingChecker{calls: map[string]int{}, failing: "/document-1/"}
+	usePipelineCheckers(t, first, second)
+
+	options.Concurrency = 3
+	options.RetryTimeout = time.Second
+
+	documents, _ := getAndCheckFilesInDirectory(directory)
+
+	if len(first.calls) != 10 || len(second.calls) != 10 {
+		t.Errorf("%d links were checked in the first and %d in the second pass", len(first.calls), len(second.calls))
+	}
+
+	for url, calls := range second.calls {
+		if calls != 1 || first.calls[url] != 1 {
+			t.Errorf("%s was checked %d times in the first and %d times in the second pass", url, first.calls[url], calls)
+		}
+	}
+
+	for index, document := range documents {
+
+		isWorking := index == 0
+
+		if document.IsValid != isWorking {
+			t.Errorf("%s is valid: %t", document.Path, document.IsValid)
+		}
+
+		for _, link := range document.Hyperlinks {
+			if link.IsWorking != isWorking {
+				t.Errorf("%s is working after the second pass: %t", link.Url, link.IsWorking)
+			}
+		}
+
+	}
+
+}
+
+// check that the documents and their hyperlinks are reported in the order of the
+// directory and the documents, regardless of the order the checks finish in
+func TestPipelineOrdering(t *testing.T) {
+
+	directory := writePipelineDocuments(t, 4, 10)
+
+	for _, order := range []string{orderDocument, orderHost, orderRandom} {
+
+		usePipelineCheckers(t, &countingChecker{calls: map[string]int{}}, nil)
+
+		options.Concurrency = 5
+		options.Order = order
+
+		documents, _ := getAndCheckFilesInDirectory(directory)
+
+		if len(documents) != 4 {
+			t.Fatalf("%s: %d of 4 documents were aggregated", order, len(documents))
+		}
+
+		for index, document := range documents {
+
+			if filepath.Base(document.Path) != fmt.Sprintf("document-%d.docx", index) {
+				t.Errorf("%s: document %d is %s", order, index, document.Path)
+			}
+
+			targets := pipelineTargets(index, 10)
+
+			for linkIndex, link := range document.Hyperlinks {
+				if link.Url != targets[linkIndex] {
+					t.Errorf("%s: link %d of %s is %s", order, linkIndex, document.Path, link.Url)
+				}
+			}
+
+		}
+
+	}
+
+}
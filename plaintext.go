@@ -0,0 +1,147 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"log"
+	"strings"
+)
+
+// define the category of urls written as plain text (which cannot be clicked)
+const categoryPlainText = "plainText"
+
+// the punctuation ending a sentence is not part of an url written in the text
+const trailingPunctuation = `.,;:!?)]}'"»“”`
+
+// extract the urls written as plain text in the paragraphs of the given part of
+// a word document or slide of a presentation
+func readPlainTextUrls(file *zip.File) []Hyperlink {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the text of " + file.Name)
+		return []Hyperlink{}
+	}
+	defer fileContentReader.Close()
+
+	paragraphs, err := decodeParagraphs(fileContentReader)
+	if err != nil {
+		log.Println("ERROR: could not read the text of " + file.Name)
+	}
+
+	links := []Hyperlink{}
+
+	for _, paragraph := range paragraphs {
+		for _, match := range matchers["plainTextUrl"].FindAllString(paragraph, -1) {
+
+			target := strings.TrimRight(match, trailingPunctuation)
+
+			// addresses are often written without scheme
+			if strings.HasPrefix(strings.ToLower(target), "www.") {
+				target = "http://" + target
+			}
+
+			links = append(links, Hyperlink{Url: target, Category: categoryPlainText, IsExternal: true, Text: match})
+
+		}
+	}
+
+	return links
+
+}
+
+// decode the text of all paragraphs of the part, the text of hyperlinks is left
+// out (the links are validated through their relationships already)
+func decodeParagraphs(reader io.Reader) ([]string, error) {
+
+	paragraphs := []string{}
+
+	var current strings.Builder
+
+	// the hyperlink elements of word and the linked text runs of powerpoint
+	hyperlinkDepth := 0
+	isLinkedRun := false
+	isText := false
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return paragraphs, nil
+		}
+		if err != nil {
+			return paragraphs, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+
+			switch element.Name.Local {
+			case "hyperlink":
+				hyperlinkDepth++
+			case "r":
+				isLinkedRun = false
+			case "hlinkClick":
+				isLinkedRun = true
+			case "t":
+				isText = true
+			case "tab", "br":
+				current.WriteString(" ")
+			}
+
+		case xml.CharData:
+			if isText && hyperlinkDepth == 0 && isLinkedRun == false && current.Len() < maxTextLength {
+				current.Write(element)
+			}
+
+		case xml.EndElement:
+
+			switch element.Name.Local {
+			case "hyperlink":
+				hyperlinkDepth--
+			case "r":
+				isLinkedRun = false
+			case "t":
+				isText = false
+			case "p":
+				if current.Len() > 0 {
+					paragraphs = append(paragraphs, current.String())
+				}
+				current.Reset()
+			}
+
+		}
+
+	}
+
+}
+
+// remove the urls written as plain text that are linked in the document anyway
+// (i.e. the display text of a hyperlink field)
+func removeLinkedPlainTextUrls(links []Hyperlink) []Hyperlink {
+
+	linked := map[string]bool{}
+
+	for _, link := range links {
+		if link.Category != categoryPlainText {
+			linked[link.Url] = true
+		}
+	}
+
+	remaining := []Hyperlink{}
+
+	for _, link := range links {
+		if link.Category == categoryPlainText && linked[link.Url] {
+			continue
+		}
+		remaining = append(remaining, link)
+	}
+
+	return remaining
+
+}
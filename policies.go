@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// the name of the policy file overriding the settings for a directory and its
+// subdirectories (i.e. a less strict policy for the legacy archive)
+const policyFileName = ".linkpolicy.json"
+
+// define a custom structure for the policy of a directory, the settings not
+// given are inherited from the policies of the parent directories (or the
+// configuration and the options)
+type DirectoryPolicy struct {
+	Filters  []FilterConfig `json:"filters"`
+	Timeout  string         `json:"timeout"`
+	Severity string         `json:"severity"`
+
+	filterChain []Filter
+	checker     Checker
+}
+
+// the effective policies of the directories by their absolute path
+var (
+	directoryPolicies     = map[string]*DirectoryPolicy{}
+	directoryPoliciesLock sync.Mutex
+)
+
+// the policy applied if no policy file is found
+var defaultPolicy = &DirectoryPolicy{}
+
+// read the policy file of the given directory (nil if there is none)
+func readPolicyFile(directory string) (*DirectoryPolicy, error) {
+
+	path := filepath.Join(directory, policyFileName)
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &DirectoryPolicy{}
+
+	err = json.Unmarshal(data, policy)
+	if err != nil {
+		return nil, errors.New("invalid policy file " + path + ": " + err.Error())
+	}
+
+	if policy.Filters != nil {
+		policy.filterChain, err = newFilterChain(policy.Filters)
+		if err != nil {
+			return nil, errors.New("invalid filters in " + path + ": " + err.Error())
+		}
+	}
+
+	if policy.Timeout != "" {
+
+		timeout, err := time.ParseDuration(policy.Timeout)
+		if err != nil {
+			return nil, errors.New("invalid timeout in " + path + ": " + policy.Timeout)
+		}
+
+		policy.checker = &httpChecker{Timeout: timeout, Proxy: options.Proxy}
+
+	}
+
+	if policy.Severity != "" {
+		err = validateSeverity(policy.Severity)
+		if err != nil {
+			return nil, errors.New("invalid severity in " + path + ": " + err.Error())
+		}
+	}
+
+	return policy, nil
+
+}
+
+// get the effective policy of the given directory, combining its policy file
+// with the policies of its parent directories (the nearest setting wins)
+func directoryPolicy(directory string) *DirectoryPolicy {
+
+	directoryPoliciesLock.Lock()
+	policy, exists := directoryPolicies[directory]
+	directoryPoliciesLock.Unlock()
+
+	if exists {
+		return policy
+	}
+
+	inherited := defaultPolicy
+	if parent := filepath.Dir(directory); parent != directory {
+		inherited = directoryPolicy(parent)
+	}
+
+	own, err := readPolicyFile(directory)
+	if err != nil {
+		log.Fatalln("ERROR:", err)
+	}
+
+	policy = inherited
+
+	if own != nil {
+
+		merged := *inherited
+
+		if own.filterChain != nil {
+			merged.filterChain = own.filterChain
+		}
+
+		if own.checker != nil {
+			merged.checker = own.checker
+		}
+
+		if own.Severity != "" {
+			merged.Severity = own.Severity
+		}
+
+		policy = &merged
+
+	}
+
+	directoryPoliciesLock.Lock()
+	directoryPolicies[directory] = policy
+	directoryPoliciesLock.Unlock()
+
+	return policy
+
+}
+
+// get the effective policy of the document with the given path
+func documentPolicy(documentPath string) *DirectoryPolicy {
+
+	directory, err := filepath.Abs(filepath.Dir(documentPath))
+	if err != nil {
+		return defaultPolicy
+	}
+
+	return directoryPolicy(directory)
+
+}
+
+// get the filters applied to the links of the document
+func (policy *DirectoryPolicy) filters() []Filter {
+
+	if policy.filterChain != nil {
+		return policy.filterChain
+	}
+
+	return filterChain
+
+}
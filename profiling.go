@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	rpprof "runtime/pprof"
+	"sync"
+)
+
+// the file the cpu profile is written to (nil if no profile is recorded)
+var cpuProfile *os.File
+
+// make sure the profiles are only written once
+var profilingStopped sync.Once
+
+// start recording the cpu profile (if requested)
+func startProfiling() {
+
+	if options.CpuProfile == "" {
+		return
+	}
+
+	file, err := os.Create(options.CpuProfile)
+	if err != nil {
+		log.Fatalln("ERROR: could not create the cpu profile:", err)
+	}
+
+	err = rpprof.StartCPUProfile(file)
+	if err != nil {
+		log.Fatalln("ERROR: could not start the cpu profile:", err)
+	}
+
+	cpuProfile = file
+
+}
+
+// stop recording the cpu profile and write the memory profile (if requested)
+func stopProfiling() {
+
+	profilingStopped.Do(func() {
+
+		if cpuProfile != nil {
+			rpprof.StopCPUProfile()
+			cpuProfile.Close()
+		}
+
+		if options.MemProfile == "" {
+			return
+		}
+
+		file, err := os.Create(options.MemProfile)
+		if err != nil {
+			log.Println("ERROR: could not create the memory profile")
+			return
+		}
+		defer file.Close()
+
+		// get up-to-date statistics of the memory still in use
+		runtime.GC()
+
+		err = rpprof.WriteHeapProfile(file)
+		if err != nil {
+			log.Println("ERROR: could not write the memory profile")
+		}
+
+	})
+
+}
+
+// serve the profiles of the running process at /debug/pprof/ (i.e. for
+// go tool pprof http://localhost:port/debug/pprof/profile)
+func registerProfiling(mux *http.ServeMux) {
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+}
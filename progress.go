@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// define a custom progress event structure (serialized as one json object per line)
+type ProgressEvent struct {
+	Event      string `json:"event"`
+	Time       string `json:"time"`
+	Document   string `json:"document,omitempty"`
+	Url        string `json:"url,omitempty"`
+	Category   string `json:"category,omitempty"`
+	IsWorking  *bool  `json:"isWorking,omitempty"`
+	NotChecked bool   `json:"notChecked,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	IsValid    *bool  `json:"isValid,omitempty"`
+	Links      int    `json:"links,omitempty"`
+	Version    string `json:"version,omitempty"`
+}
+
+// define a custom progress structure writing events to a stream
+// and/or handing them to a listener (i.e. the gui)
+type Progress struct {
+	mutex    sync.Mutex
+	writer   io.WriteCloser
+	encoder  *json.Encoder
+	listener func(event ProgressEvent)
+}
+
+// the progress stream of the current run (nil if no progress should be reported)
+var progress *Progress
+
+// initialize the progress stream from the options given
+func initializeProgress() {
+
+	var writer io.WriteCloser
+
+	if options.ProgressFile != "" {
+
+		// create the file to write our progress events to
+		file, err := os.Create(options.ProgressFile)
+		if err != nil {
+			log.Println("ERROR: could not create the progress file")
+			return
+		}
+
+		writer = file
+
+	} else if options.ProgressFd >= 0 {
+
+		// use the file descriptor handed to us by the calling process
+		writer = os.NewFile(uintptr(options.ProgressFd), "progress")
+
+		if writer == nil {
+			log.Println("ERROR: invalid progress file descriptor")
+			return
+		}
+
+	} else {
+		// no progress reporting requested
+		return
+	}
+
+	progress = &Progress{writer: writer, encoder: json.NewEncoder(writer)}
+
+}
+
+// emit a single event to the progress stream
+func (progress *Progress) emit(event ProgressEvent) {
+
+	// progress reporting is optional
+	if progress == nil {
+		return
+	}
+
+	event.Time = time.Now().Format(time.RFC3339Nano)
+
+	// events may be sent from several routines at once
+	progress.mutex.Lock()
+	defer progress.mutex.Unlock()
+
+	if progress.listener != nil {
+		progress.listener(event)
+	}
+
+	if progress.encoder == nil {
+		return
+	}
+
+	err := progress.encoder.Encode(event)
+	if err != nil {
+		log.Println("ERROR: could not write progress event")
+	}
+
+}
+
+// inform about the start of the run
+func (progress *Progress) runStarted() {
+	progress.emit(ProgressEvent{Event: "run_started", Version: currentBuildInfo().Version})
+}
+
+// inform that the checking of a document started
+func (progress *Progress) documentStarted(document *Document) {
+	progress.emit(ProgressEvent{Event: "document_started", Document: document.Path})
+}
+
+// inform that a hyperlink of a document was checked
+func (progress *Progress) linkChecked(document *Document, link *Hyperlink) {
+	isWorking := link.IsWorking
+	progress.emit(ProgressEvent{Event: "link_checked", Document: document.Path, Url: redactUrl(link.Url), Category: link.Category, IsWorking: &isWorking, NotChecked: link.NotChecked, Reason: redactText(link.Reason)})
+}
+
+// inform that all links of a document were checked
+func (progress *Progress) documentFinished(document *Document) {
+	isValid := document.IsValid
+	progress.emit(ProgressEvent{Event: "document_finished", Document: document.Path, IsValid: &isValid, Links: len(document.Hyperlinks)})
+}
+
+// inform about the end of the run
+func (progress *Progress) runFinished(report *Report) {
+	isValid := report.ResultOfValidation
+	progress.emit(ProgressEvent{Event: "run_finished", IsValid: &isValid})
+}
+
+// close the progress stream
+func (progress *Progress) close() {
+
+	if progress == nil || progress.writer == nil {
+		return
+	}
+
+	progress.writer.Close()
+
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+)
+
+// define the category of urls encoded in qr codes of embedded images
+const categoryQrCode = "qrCode"
+
+// the command decoding the qr codes of an image if none is given, the
+// placeholder {file} is replaced with the path of the image (zbarimg prints the
+// payload of every code found on a line of its own)
+const defaultQrDecoder = "zbarimg --quiet --raw {file}"
+
+// the time the decoder may take for a single image
+const qrDecoderTimeout = 30 * time.Second
+
+// the formats of embedded images the decoder is given
+var qrImageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".tif": true, ".tiff": true}
+
+// extract the urls encoded in the qr codes of all images embedded in the package
+// (i.e. on patient flyers), the images are decoded with an external command
+func readQrCodeUrls(files []*zip.File) []Hyperlink {
+
+	links := []Hyperlink{}
+
+	for _, file := range files {
+
+		if qrImageExtensions[strings.ToLower(path.Ext(file.Name))] == false {
+			continue
+		}
+
+		payloads, err := decodeQrCodes(file)
+		if err != nil {
+			log.Println("ERROR: could not decode the qr codes of " + file.Name + ": " + err.Error())
+			continue
+		}
+
+		for _, payload := range payloads {
+
+			// qr codes also encode contacts or plain text, which are not checked
+			if isKnownScheme(linkScheme(payload)) == false || linkScheme(payload) == "file" {
+				continue
+			}
+
+			links = append(links, Hyperlink{Url: payload, Category: categoryQrCode, IsExternal: true, Text: file.Name})
+
+		}
+
+	}
+
+	return links
+
+}
+
+// get the arguments of the decoder command of the options (or the default one)
+func qrDecoderArguments() []string {
+
+	command := options.QrDecoder
+	if command == "" {
+		command = defaultQrDecoder
+	}
+
+	return strings.Fields(command)
+
+}
+
+// check that the decoder command of the options is installed
+func validateQrDecoder() error {
+
+	arguments := qrDecoderArguments()
+	if len(arguments) == 0 {
+		return errors.New("-qr-decoder needs a command")
+	}
+
+	_, err := exec.LookPath(arguments[0])
+	if err != nil {
+		return errors.New("the qr code decoder " + arguments[0] + " is not installed (install zbar or give -qr-decoder)")
+	}
+
+	return nil
+
+}
+
+// decode the qr codes of the given image with the decoder command of the options
+func decodeQrCodes(file *zip.File) ([]string, error) {
+
+	reader, err := openPart(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// the decoder reads the image from a temporary file
+	image, err := ioutil.TempFile("", "validate-links-*"+path.Ext(file.Name))
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(image.Name())
+
+	_, err = io.Copy(image, reader)
+	image.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := qrDecoderArguments()
+	for index := range arguments {
+		arguments[index] = strings.Replace(arguments[index], "{file}", image.Name(), -1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), qrDecoderTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, arguments[0], arguments[1:]...).Output()
+
+	// zbarimg exits with status 4 if the image does not contain any code
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 4 {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	payloads := []string{}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			payloads = append(payloads, line)
+		}
+	}
+
+	return payloads, nil
+
+}
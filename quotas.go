@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+)
+
+// check the number of external links and of distinct domains of the document
+// against the quotas of the configuration (documents exceeding them should often
+// be converted to use a reference list)
+func (document *Document) checkQuotas() {
+
+	document.QuotaWarnings = nil
+
+	externalLinks := 0
+	domains := make(map[string]bool)
+
+	for _, link := range document.Hyperlinks {
+
+		if link.IsExternal == false {
+			continue
+		}
+
+		externalLinks++
+
+		if domain := urlDomain(link.Url); domain != "" {
+			domains[domain] = true
+		}
+
+	}
+
+	if config.MaxExternalLinks > 0 && externalLinks > config.MaxExternalLinks {
+		document.QuotaWarnings = append(document.QuotaWarnings, fmt.Sprintf("%d external links (more than %d)", externalLinks, config.MaxExternalLinks))
+	}
+
+	if config.MaxDomains > 0 && len(domains) > config.MaxDomains {
+		document.QuotaWarnings = append(document.QuotaWarnings, fmt.Sprintf("links to %d distinct domains (more than %d)", len(domains), config.MaxDomains))
+	}
+
+}
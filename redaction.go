@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// the value replacing the redacted query parameters
+const redactedValue = "REDACTED"
+
+// find the urls mentioned in error messages and warnings
+var mentionedUrlMatcher = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// define a custom structure for a redaction rule, the values of the query
+// parameters matching the parameter pattern are removed from the report for all
+// links matching the (optional) url pattern (i.e. access tokens or study ids)
+type RedactionRule struct {
+	Pattern   string `json:"pattern"`
+	Parameter string `json:"parameter"`
+
+	matcher          *regexp.Regexp
+	parameterMatcher *regexp.Regexp
+}
+
+// check the redaction rule given in the configuration
+func (rule *RedactionRule) prepare() error {
+
+	if rule.Pattern != "" {
+
+		matcher, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return errors.New("invalid redaction pattern " + rule.Pattern)
+		}
+
+		rule.matcher = matcher
+
+	}
+
+	parameterMatcher, err := regexp.Compile(rule.Parameter)
+	if err != nil || rule.Parameter == "" {
+		return errors.New("invalid redaction parameter " + rule.Parameter)
+	}
+
+	rule.parameterMatcher = parameterMatcher
+
+	return nil
+
+}
+
+// check if the given query parameter of the url is to be redacted
+func isRedactedParameter(link string, name string) bool {
+
+	for _, rule := range config.Redactions {
+
+		if rule.matcher != nil && rule.matcher.MatchString(link) == false {
+			continue
+		}
+
+		if rule.parameterMatcher.MatchString(name) {
+			return true
+		}
+
+	}
+
+	return false
+
+}
+
+// replace the values of the redacted query parameters of the given url, the
+// order and the encoding of the other parameters are kept
+func redactUrl(link string) string {
+
+	if len(config.Redactions) == 0 || strings.Contains(link, "?") == false {
+		return link
+	}
+
+	address, err := url.Parse(link)
+	if err != nil || address.RawQuery == "" {
+		return link
+	}
+
+	parameters := strings.Split(address.RawQuery, "&")
+	redacted := false
+
+	for index, parameter := range parameters {
+
+		name := strings.SplitN(parameter, "=", 2)[0]
+
+		decodedName, err := url.QueryUnescape(name)
+		if err != nil {
+			decodedName = name
+		}
+
+		if isRedactedParameter(link, decodedName) {
+			parameters[index] = name + "=" + redactedValue
+			redacted = true
+		}
+
+	}
+
+	if redacted == false {
+		return link
+	}
+
+	address.RawQuery = strings.Join(parameters, "&")
+
+	return address.String()
+
+}
+
+// redact all urls mentioned in the given text (i.e. in the error of a request)
+func redactText(text string) string {
+
+	if len(config.Redactions) == 0 {
+		return text
+	}
+
+	return mentionedUrlMatcher.ReplaceAllStringFunc(text, redactUrl)
+
+}
+
+// redact the urls of the link in everything shown in the report
+func (link *Hyperlink) redact() {
+
+	link.Url = redactUrl(link.Url)
+	link.RequestUrl = redactUrl(link.RequestUrl)
+	link.Reason = redactText(link.Reason)
+	link.ArchiveUrl = redactText(link.ArchiveUrl)
+	link.CleanUrl = redactUrl(link.CleanUrl)
+
+	for index := range link.Replacements {
+		link.Replacements[index] = redactUrl(link.Replacements[index])
+	}
+
+	for index := range link.Warnings {
+		link.Warnings[index] = redactText(link.Warnings[index])
+	}
+
+	for index := range link.Zones {
+		link.Zones[index].Reason = redactText(link.Zones[index].Reason)
+	}
+
+}
+
+// redact the urls of all documents once they are validated, so the full urls
+// are checked but never written to the reports
+func redactDocuments(documents []Document) {
+
+	if len(config.Redactions) == 0 {
+		return
+	}
+
+	for index := range documents {
+
+		document := &documents[index]
+
+		for linkIndex := range document.Hyperlinks {
+			document.Hyperlinks[linkIndex].redact()
+		}
+
+		for linkIndex := range document.OrphanedLinks {
+			document.OrphanedLinks[linkIndex].redact()
+		}
+
+		for linkIndex := range document.LinkTextIssues {
+			document.LinkTextIssues[linkIndex].Url = redactUrl(document.LinkTextIssues[linkIndex].Url)
+			document.LinkTextIssues[linkIndex].Text = redactText(document.LinkTextIssues[linkIndex].Text)
+		}
+
+		for linkIndex := range document.IgnoredLinks {
+			document.IgnoredLinks[linkIndex].Url = redactUrl(document.IgnoredLinks[linkIndex].Url)
+		}
+
+		for linkIndex := range document.RepeatedLinks {
+			document.RepeatedLinks[linkIndex].Url = redactUrl(document.RepeatedLinks[linkIndex].Url)
+		}
+
+		for linkIndex := range document.ConflictingLinks {
+			urls := document.ConflictingLinks[linkIndex].Urls
+			for urlIndex := range urls {
+				urls[urlIndex] = redactUrl(urls[urlIndex])
+			}
+		}
+
+	}
+
+}
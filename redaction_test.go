@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// the value of the redacted query parameter of the links in the tests
+const redactionSecret = "s3cr3t-value"
+
+// define a buffer that the workers of the pipeline can write to at the same time
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+// append the given data to the buffer
+func (output *lockedBuffer) Write(data []byte) (int, error) {
+
+	output.mutex.Lock()
+	defer output.mutex.Unlock()
+
+	return output.buffer.Write(data)
+
+}
+
+// check the redaction of the query parameters matching the rules
+func TestRedactUrl(t *testing.T) {
+
+	useRedactions(t, RedactionRule{Parameter: "^token$"}, RedactionRule{Pattern: `^https://study\.test/`, Parameter: "^id$"})
+
+	tests := []struct {
+		link     string
+		redacted string
+	}{
+		{"https://example.com/?token=abc&page=2", "https://example.com/?token=REDACTED&page=2"},
+		{"https://example.com/?page=2&to%6Ben=abc", "https://example.com/?page=2&to%6Ben=REDACTED"},
+		{"https://example.com/?page=2", "https://example.com/?page=2"},
+		{"https://example.com/?id=7", "https://example.com/?id=7"},
+		{"https://study.test/?id=7", "https://study.test/?id=REDACTED"},
+		{"https://example.com/path", "https://example.com/path"},
+	}
+
+	for _, test := range tests {
+		if redacted := redactUrl(test.link); redacted != test.redacted {
+			t.Errorf("redactUrl(%s) = %s, expected %s", test.link, redacted, test.redacted)
+		}
+	}
+
+	text := redactText("redirect from https://example.com/?token=abc to https://example.com/next?token=def")
+	if strings.Contains(text, "abc") || strings.Contains(text, "def") {
+		t.Errorf("redactText kept the tokens: %s", text)
+	}
+
+}
+
+// check that the secret parameters of the links appear in no output of a run
+// (the console, the log, the progress events, the report, the cache, the evidence
+// and the list of the links)
+func TestRedactionOfAllOutputs(t *testing.T) {
+
+	useRedactions(t, RedactionRule{Parameter: "^token$"})
+
+	// every response keeps the query (and thereby the secret) of the request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte("ok"))
+		case "/loop":
+			http.Redirect(w, r, "/loop?"+r.URL.RawQuery, http.StatusFound)
+		default:
+			w.Header().Set("Location", "http://"+fakeHost+"/other?"+r.URL.RawQuery)
+			http.NotFound(w, r)
+		}
+
+	}))
+	defer server.Close()
+
+	directory := t.TempDir()
+	query := "?token=" + redactionSecret
+
+	err := writeWordDocument(filepath.Join(directory, "secret.docx"), []string{
+		"http://" + fakeHost + "/ok" + query,
+		"http://" + fakeHost + "/loop" + query,
+		"http://" + fakeHost + "/missing" + query,
+		"http://unreachable.test/" + query,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usePipelineCheckers(t, &httpChecker{Timeout: 5 * time.Second, Client: fakeClient(server)}, nil)
+
+	options.EvidenceDirectory = filepath.Join(t.TempDir(), "evidence")
+	cachePath := filepath.Join(t.TempDir(), "cache.json")
+
+	output := &lockedBuffer{}
+	events := []ProgressEvent{}
+
+	previousConsole, previousProgress, previousCache := console, progress, linkCache
+	defer func() {
+		console, progress, linkCache = previousConsole, previousProgress, previousCache
+		log.SetOutput(os.Stderr)
+	}()
+
+	console = output
+	log.SetOutput(output)
+	progress = &Progress{listener: func(event ProgressEvent) { events = append(events, event) }}
+	linkCache = loadLinkCache(cachePath)
+
+	report := validateDirectories([]string{directory})
+	linkCache.save()
+	listHyperlinks([]RootConfig{{Path: directory}}, output)
+
+	// the outputs are only meaningful if the links were checked
+	if output.buffer.Len() == 0 || len(events) == 0 || len(report.Documents) != 1 || len(report.Documents[0].Hyperlinks) != 4 {
+		t.Fatalf("the links were not checked: %s", output.buffer.String())
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string][]byte{
+		"console and log": output.buffer.Bytes(),
+		"report":          data,
+	}
+
+	outputs["progress"], _ = json.Marshal(events)
+	outputs["cache"], _ = os.ReadFile(cachePath)
+
+	evidence, _ := filepath.Glob(filepath.Join(options.EvidenceDirectory, "*.txt"))
+	if len(evidence) == 0 {
+		t.Errorf("no evidence was captured")
+	}
+
+	for _, path := range evidence {
+		outputs[path], _ = os.ReadFile(path)
+	}
+
+	for name, content := range outputs {
+		if bytes.Contains(content, []byte(redactionSecret)) {
+			t.Errorf("the %s contains the secret:\n%s", name, content)
+		}
+	}
+
+	if bytes.Contains(outputs["cache"], []byte(redactedValue)) == false {
+		t.Errorf("the successful link is missing in the cache: %s", outputs["cache"])
+	}
+
+}
+
+// use the given redaction rules for a single test
+func useRedactions(t *testing.T, rules ...RedactionRule) {
+
+	previousConfig := config
+	t.Cleanup(func() { config = previousConfig })
+
+	config.Redactions = rules
+
+	for index := range config.Redactions {
+		err := config.Redactions[index].prepare()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
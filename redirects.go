@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// define the reasons given for links whose redirects could not be followed
+const (
+	reasonRedirectLoop     = "redirect loop"
+	reasonTooManyRedirects = "too many redirects"
+)
+
+// define a custom error for redirects that could not be followed to their end
+type RedirectError struct {
+	Reason string
+	// the urls visited (including the one that was not followed anymore)
+	Chain []string
+}
+
+// describe the redirect error together with the urls visited
+func (err *RedirectError) Error() string {
+	return err.Reason + ": " + strings.Join(err.Chain, " -> ")
+}
+
+// check if the given response redirects to another location
+func isRedirect(response *http.Response) bool {
+
+	switch response.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return response.Header.Get("Location") != ""
+	}
+
+	return false
+
+}
+
+// resolve the location of a redirect relative to the url redirecting
+func resolveRedirect(current string, location string) (string, error) {
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := base.Parse(location)
+	if err != nil {
+		return "", errors.New("invalid redirect location " + location)
+	}
+
+	// the fragment is not sent to the server and cannot cause a loop
+	target.Fragment = ""
+
+	return target.String(), nil
+
+}
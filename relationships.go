@@ -0,0 +1,458 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"log"
+	"path"
+	"strings"
+	"sync"
+)
+
+// define a custom relationship structure as found in the .rels files of the document package
+type Relationship struct {
+	Id         string
+	Type       string
+	Target     string
+	TargetMode string
+}
+
+// check if the relationship points outside of the document package
+func (relationship Relationship) isExternal() bool {
+	return strings.EqualFold(relationship.TargetMode, "External")
+}
+
+// define the categories of relationships we validate
+const (
+	categoryHyperlink = "hyperlink"
+	categoryImage     = "image"
+	categoryOleObject = "oleObject"
+
+	// click and hover actions of powerpoint shapes jumping to another slide
+	categorySlideJump = "slideJump"
+
+	// hyperlinks created with field codes or formulas are not stored as relationship
+	categoryFieldHyperlink   = "fieldHyperlink"
+	categoryFormulaHyperlink = "formulaHyperlink"
+)
+
+// get the category of the given relationship type (returns an empty string for
+// relationships we are not interested in)
+func relationshipCategory(relationshipType string) string {
+
+	// relationship types are urls ending with the name of the type, the namespace
+	// however differs between transitional and strict documents
+	switch path.Base(relationshipType) {
+	case "hyperlink":
+		return categoryHyperlink
+	case "image":
+		return categoryImage
+	case "oleObject":
+		return categoryOleObject
+	case "slide":
+		// slides only reference other slides for hlinkClick and hlinkHover actions
+		return categorySlideJump
+	}
+
+	return ""
+
+}
+
+// extract all hyperlinks of the document that should be validated and all orphaned
+// hyperlinks (relationships that are not referenced from the content anymore, i.e.
+// leftovers of deleted content that cannot be clicked)
+func extractHyperlinksFromDocument(document *Document) ([]Hyperlink, []Hyperlink) {
+
+	// initialize an empty slice of hyperlinks
+	links := []Hyperlink{}
+	orphanedLinks := []Hyperlink{}
+
+	// open the docx file with our zip module (as it is basically a container)
+	documentContainer, err := openDocumentContainer(document.Path)
+
+	// password protected documents are reported separately
+	switch {
+	case err == errDocumentLocked:
+		log.Println("ERROR: " + document.Path + " is password protected")
+		document.Protection = protectionLocked
+		return links, orphanedLinks
+	case err == errUnsupportedEncryption:
+		log.Println("ERROR: " + document.Path + " is encrypted with an unsupported method")
+		document.Protection = protectionUnsupported
+		return links, orphanedLinks
+	case err != nil:
+		log.Println("ERROR: could not open the file")
+		return links, orphanedLinks
+	}
+	defer documentContainer.Close()
+
+	if documentContainer.isDecrypted {
+		document.Protection = protectionDecrypted
+	}
+
+	// remember all parts of the package to check internal targets (part names
+	// are case insensitive)
+	parts := make(map[string]*zip.File)
+
+	for _, file := range documentContainer.File {
+		parts[strings.ToLower(file.Name)] = file
+	}
+
+	// find the parts containing the content of the document
+	contentParts := findContentParts(parts, document.Type)
+
+	// the parts are read concurrently, the results are collected in the order of
+	// the parts to keep the order of the links stable
+	partLinks := make([][]Hyperlink, len(documentContainer.File))
+	partOrphanedLinks := make([][]Hyperlink, len(documentContainer.File))
+
+	partJobs := make(chan int)
+	var workers sync.WaitGroup
+
+	for worker := 0; worker < extractionWorkers; worker++ {
+
+		workers.Add(1)
+
+		go func() {
+
+			for index := range partJobs {
+				partLinks[index], partOrphanedLinks[index] = extractHyperlinksFromPart(documentContainer.File[index], parts, contentParts)
+			}
+
+			workers.Done()
+
+		}()
+
+	}
+
+	for index := range documentContainer.File {
+		partJobs <- index
+	}
+
+	close(partJobs)
+	workers.Wait()
+
+	for index := range documentContainer.File {
+		links = append(links, partLinks[index]...)
+		orphanedLinks = append(orphanedLinks, partOrphanedLinks[index]...)
+	}
+
+	// the anchors must exist somewhere in the document
+	resolveAnchors(links, documentContainer.File, contentParts)
+
+	// qr codes of embedded images break silently without anyone noticing (if requested)
+	if options.QrCodes {
+		links = append(links, readQrCodeUrls(documentContainer.File)...)
+	}
+
+	// urls written as plain text are only of interest if they are not linked
+	if options.PlainTextUrls {
+		links = removeLinkedPlainTextUrls(links)
+	}
+
+	// now filter out all links excluded by the filter chain (of the policy of
+	// the directory if it has its own filters)
+	chain := documentPolicy(document.Path).filters()
+
+	return filterHyperlinks(links, chain), filterHyperlinks(orphanedLinks, chain)
+
+}
+
+// the number of parts of a document read at the same time
+const extractionWorkers = 4
+
+// extract the hyperlinks and the orphaned hyperlinks of a single part of the package
+func extractHyperlinksFromPart(file *zip.File, parts map[string]*zip.File, contentParts map[string]string) ([]Hyperlink, []Hyperlink) {
+
+	links := []Hyperlink{}
+	orphanedLinks := []Hyperlink{}
+
+	name := strings.ToLower(file.Name)
+
+	switch contentParts[name] {
+
+	case partText:
+		// hyperlink fields are stored in the text of the document body
+		links = readFieldHyperlinks(file)
+
+		// jumps to the bookmarks of the document are not stored as relationship
+		links = append(links, readAnchorHyperlinks(file)...)
+
+		// authors often paste urls without making them clickable (if requested)
+		if options.PlainTextUrls {
+			links = append(links, readPlainTextUrls(file)...)
+		}
+
+		return links, orphanedLinks
+
+	case partSlide:
+		if options.PlainTextUrls {
+			return readPlainTextUrls(file), orphanedLinks
+		}
+
+	case partWorksheet:
+		// hyperlink formulas are stored in the cells of the worksheets, jumps
+		// to other sheets only in the hyperlinks of the worksheet
+		return append(readFormulaHyperlinks(file), readAnchorHyperlinks(file)...), orphanedLinks
+
+	}
+
+	// links are stored in the relationships of the content parts (but without
+	// the name of the link)
+	if path.Ext(name) != ".rels" || contentParts[sourcePartName(name)] == "" {
+		return links, orphanedLinks
+	}
+
+	// we still use all relationships decoded before any error
+	relationships, err := readRelationships(file)
+	if err != nil {
+		log.Println("ERROR: could not read the relationships of " + file.Name)
+	}
+
+	// the tooltips and texts are stored with the elements referencing the relationships
+	references := map[string]LinkReference{}
+	isComplete := false
+
+	if sourcePart, exists := parts[sourcePartName(name)]; exists {
+		references, isComplete = readLinkReferences(sourcePart)
+	}
+
+	for _, relationship := range relationships {
+
+		category := relationshipCategory(relationship.Type)
+		if category == "" {
+			continue
+		}
+
+		_, isReferenced := references[relationship.Id]
+
+		link := Hyperlink{
+			Url:        relationship.Target,
+			Category:   category,
+			IsExternal: relationship.isExternal(),
+			Text:       references[relationship.Id].Text,
+			Tooltip:    references[relationship.Id].Tooltip,
+		}
+
+		if link.IsExternal == false {
+
+			// embedded images and objects are always part of the document
+			if category != categoryHyperlink && category != categorySlideJump {
+				continue
+			}
+
+			link.ResolvedPath = resolvePartName(file.Name, relationship.Target)
+			link.isPartPresent = parts[strings.ToLower(link.ResolvedPath)] != nil
+
+		}
+
+		// we can only tell that a relationship is not used if we read the whole part
+		if isComplete && isReferenced == false {
+			orphanedLinks = append(orphanedLinks, link)
+			continue
+		}
+
+		links = append(links, link)
+
+	}
+
+	return links, orphanedLinks
+
+}
+
+// define a custom structure for an opened document package
+type DocumentContainer struct {
+	*zip.Reader
+	file        io.Closer
+	isDecrypted bool
+}
+
+// close the underlying file of the document package
+func (container *DocumentContainer) Close() error {
+	return container.file.Close()
+}
+
+// open the document with the given path as zip archive
+func openDocumentContainer(path string) (*DocumentContainer, error) {
+
+	file, err := fileSystem.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	// password protected documents are stored as compound file
+	signature := make([]byte, len(compoundFileSignature))
+	file.ReadAt(signature, 0)
+
+	if isCompoundFile(signature) {
+		return openEncryptedContainer(file, fileInfo.Size())
+	}
+
+	reader, err := zip.NewReader(file, fileInfo.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &DocumentContainer{Reader: reader, file: file}, nil
+
+}
+
+// decrypt the password protected document and open the decrypted zip archive
+func openEncryptedContainer(file File, size int64) (*DocumentContainer, error) {
+
+	// the whole document is decrypted in memory
+	defer file.Close()
+
+	data := make([]byte, size)
+
+	_, err := file.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	decrypted, err := decryptDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(decrypted), int64(len(decrypted)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DocumentContainer{Reader: reader, file: ioutil.NopCloser(nil), isDecrypted: true}, nil
+
+}
+
+// decode the relationships of the given .rels file
+func readRelationships(file *zip.File) ([]Relationship, error) {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		return []Relationship{}, err
+	}
+	defer fileContentReader.Close()
+
+	return decodeRelationships(fileContentReader)
+
+}
+
+// decode all relationship elements from the given stream, the elements are matched
+// by their local name only, as writers differ in their use of namespace prefixes
+func decodeRelationships(reader io.Reader) ([]Relationship, error) {
+
+	relationships := []Relationship{}
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return relationships, nil
+		}
+		if err != nil {
+			return relationships, err
+		}
+
+		element, isStartElement := token.(xml.StartElement)
+		if isStartElement == false || element.Name.Local != "Relationship" {
+			continue
+		}
+
+		relationship := Relationship{}
+
+		for _, attribute := range element.Attr {
+
+			switch attribute.Name.Local {
+			case "Id":
+				relationship.Id = attribute.Value
+			case "Type":
+				relationship.Type = attribute.Value
+			case "Target":
+				relationship.Target = attribute.Value
+			case "TargetMode":
+				relationship.TargetMode = attribute.Value
+			}
+
+		}
+
+		relationships = append(relationships, relationship)
+
+	}
+
+}
+
+// create a xml decoder accepting the quirks of the different office writers
+func newTolerantDecoder(reader io.Reader) *xml.Decoder {
+
+	decoder := xml.NewDecoder(skipByteOrderMark(reader))
+
+	// some writers produce slightly malformed xml (i.e. unescaped ampersands in targets)
+	decoder.Strict = false
+
+	// we only support utf-8 documents, but some writers declare other (compatible) names
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	return decoder
+
+}
+
+// skip the utf-8 byte order mark some writers put in front of the xml declaration
+func skipByteOrderMark(reader io.Reader) io.Reader {
+
+	buffered := bufio.NewReader(reader)
+
+	mark, err := buffered.Peek(3)
+	if err == nil && bytes.Equal(mark, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+
+	return buffered
+
+}
+
+// remove the item specifier from the target of a linked object, i.e. the target
+// file:///C:\data\book.xlsx!Sheet1!R1C1:R5C3 refers to the file C:\data\book.xlsx
+func stripOleItem(target string) string {
+
+	// the item is separated by an exclamation mark from the name of the file
+	fileNameStart := strings.LastIndexAny(target, `/\`) + 1
+	itemStart := strings.Index(target[fileNameStart:], "!")
+
+	if itemStart < 0 {
+		return target
+	}
+
+	return target[:fileNameStart+itemStart]
+
+}
+
+// resolve the target of an internal relationship to the name of the part in the package
+func resolvePartName(relationshipFile string, target string) string {
+
+	// targets starting with a slash are relative to the root of the package
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+
+	// the relationships of word/document.xml are stored in word/_rels/document.xml.rels
+	sourceDirectory := path.Dir(path.Dir(relationshipFile))
+
+	return strings.TrimPrefix(path.Join(sourceDirectory, target), "/")
+
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// check the decoding of the relationships written by different office suites
+func TestDecodeRelationships(t *testing.T) {
+
+	const namespace = "http://schemas.openxmlformats.org/package/2006/relationships"
+	const hyperlinkType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	const imageType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+
+	hyperlink := Relationship{Id: "rId1", Type: hyperlinkType, Target: "https://example.com/a?b=1&c=2", TargetMode: "External"}
+	image := Relationship{Id: "rId2", Type: imageType, Target: "media/image1.png"}
+
+	tests := []struct {
+		name          string
+		content       string
+		relationships []Relationship
+	}{
+		{
+			"word",
+			`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+				`<Relationships xmlns="` + namespace + `"><Relationship Id="rId2" Type="` + imageType + `" Target="media/image1.png"/>` +
+				`<Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&amp;c=2" TargetMode="External"/></Relationships>`,
+			[]Relationship{image, hyperlink},
+		},
+		{
+			"powerpoint",
+			`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+				`<Relationships xmlns="` + namespace + `"><Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&amp;c=2" TargetMode="External"/>` +
+				`<Relationship Id="rId2" Type="` + imageType + `" Target="../media/image1.png"/></Relationships>`,
+			[]Relationship{hyperlink, {Id: "rId2", Type: imageType, Target: "../media/image1.png"}},
+		},
+		{
+			"libreoffice",
+			`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<Relationships xmlns="` + namespace + `">` + "\n" +
+				`  <Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&amp;c=2" TargetMode="External"/>` + "\n" +
+				`</Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"google docs",
+			`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="` + namespace + `">` +
+				`<Relationship TargetMode="External" Target="https://example.com/a?b=1&amp;c=2" Type="` + hyperlinkType + `" Id="rId1"/></Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"prefixed namespace",
+			`<?xml version="1.0" encoding="UTF-8"?><pr:Relationships xmlns:pr="` + namespace + `">` +
+				`<pr:Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&amp;c=2" TargetMode="External"/></pr:Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"single quoted attributes",
+			`<?xml version='1.0' encoding='UTF-8'?><Relationships xmlns='` + namespace + `'>` +
+				`<Relationship Id='rId1' Type='` + hyperlinkType + `' Target='https://example.com/a?b=1&amp;c=2' TargetMode='External'/></Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"byte order mark",
+			"\xEF\xBB\xBF" + `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="` + namespace + `">` +
+				`<Relationship Id="rId2" Type="` + imageType + `" Target="media/image1.png"/></Relationships>`,
+			[]Relationship{image},
+		},
+		{
+			"unescaped ampersand",
+			`<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="` + namespace + `">` +
+				`<Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&c=2" TargetMode="External"/></Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"other charset declared",
+			`<?xml version="1.0" encoding="windows-1252"?><Relationships xmlns="` + namespace + `">` +
+				`<Relationship Id="rId1" Type="` + hyperlinkType + `" Target="https://example.com/a?b=1&amp;c=2" TargetMode="External"/></Relationships>`,
+			[]Relationship{hyperlink},
+		},
+		{
+			"without relationships",
+			`<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="` + namespace + `"/>`,
+			[]Relationship{},
+		},
+		{
+			"empty part",
+			"",
+			[]Relationship{},
+		},
+	}
+
+	for _, test := range tests {
+
+		relationships, err := decodeRelationships(strings.NewReader(test.content))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		if reflect.DeepEqual(relationships, test.relationships) == false {
+			t.Errorf("%s: decodeRelationships = %+v, expected %+v", test.name, relationships, test.relationships)
+		}
+
+	}
+
+}
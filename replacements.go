@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// the search page tried on sites without a search given in the configuration
+const defaultSiteSearch = "/search?q={query}"
+
+// the maximum number of parent paths tried for a link whose page is gone
+const maxParentCandidates = 3
+
+// define the reason of links whose page is gone
+const reasonPageNotFound = "the server answered that the page does not exist anymore"
+
+// check if the response states that the page of the link does not exist
+func isPageGone(response *CheckResponse) bool {
+	return response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone)
+}
+
+// check if the given candidate url leads to an existing page (with the checker of
+// the link, so the candidates are requested the same way as the link itself)
+func isResolvingCandidate(candidate string, linkChecker Checker) bool {
+
+	// the recovery requests count against the budget of the run too
+	if budget.take() == false {
+		return false
+	}
+
+	response, err := linkChecker.Check(candidate)
+
+	return err == nil && response.StatusCode < 400
+
+}
+
+// try to find pages replacing the page of the link that is gone, first the
+// closest parent path that still exists (i.e. the overview of a moved page)
+// and then the search of the site with the last path segment as query
+func (link *Hyperlink) suggestReplacements(linkChecker Checker) {
+
+	address, err := url.Parse(link.RequestUrl)
+	if err != nil || address.Host == "" {
+		return
+	}
+
+	// the front page of a site has no parent to fall back to
+	if strings.Trim(address.Path, "/") == "" {
+		return
+	}
+
+	segments := strings.Split(strings.Trim(address.Path, "/"), "/")
+
+	for count := len(segments) - 1; count >= 0 && len(segments)-count <= maxParentCandidates; count-- {
+
+		parent := *address
+		parent.Path = "/" + strings.Join(segments[:count], "/")
+		parent.RawPath = ""
+		parent.RawQuery = ""
+		parent.Fragment = ""
+
+		if count > 0 {
+			parent.Path += "/"
+		}
+
+		if isResolvingCandidate(parent.String(), linkChecker) {
+			link.Replacements = append(link.Replacements, parent.String())
+			break
+		}
+
+	}
+
+	// the old slug usually names the content that was moved
+	slug := strings.TrimSuffix(segments[len(segments)-1], path.Ext(segments[len(segments)-1]))
+	query := strings.TrimSpace(strings.NewReplacer("-", " ", "_", " ", "+", " ").Replace(slug))
+
+	if query == "" {
+		return
+	}
+
+	search, exists := config.SiteSearch[address.Hostname()]
+	if exists == false {
+		search = defaultSiteSearch
+	}
+
+	// searches given as path are relative to the site of the link
+	if strings.HasPrefix(search, "/") {
+		search = address.Scheme + "://" + address.Host + search
+	}
+
+	candidate := strings.Replace(search, "{query}", url.QueryEscape(query), -1)
+
+	if isResolvingCandidate(candidate, linkChecker) {
+		link.Replacements = append(link.Replacements, candidate)
+	}
+
+}
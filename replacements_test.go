@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// check that the replacements are requested with the checker of the link (i.e.
+// of its directory, zones or worker) and not with the default checker of the run
+func TestSuggestReplacementsWithLinkChecker(t *testing.T) {
+
+	runChecker := &countingChecker{calls: map[string]int{}, failing: fakeHost}
+	usePipelineCheckers(t, runChecker, nil)
+
+	linkChecker := &countingChecker{calls: map[string]int{}}
+
+	link := &Hyperlink{RequestUrl: "http://" + fakeHost + "/handbook/old-page"}
+	link.suggestReplacements(linkChecker)
+
+	expected := []string{"http://" + fakeHost + "/handbook/", "http://" + fakeHost + "/search?q=old+page"}
+
+	if reflect.DeepEqual(link.Replacements, expected) == false {
+		t.Errorf("replacements %v, expected %v", link.Replacements, expected)
+	}
+
+	if len(runChecker.calls) > 0 {
+		t.Errorf("the replacements were requested with the checker of the run: %v", runChecker.calls)
+	}
+
+}
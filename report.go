@@ -0,0 +1,1016 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/skratchdot/open-golang/open"
+)
+
+// define a custom report structre
+type Report struct {
+	ResultOfValidation bool
+	Directories        []string
+	Documents          []Document
+	Domains            []DomainSummary
+	BrokenDomains      []BrokenDomain
+	Owners             []OwnerSummary
+	Dependencies       []DocumentDependency
+	HostExpiries       []HostExpiry
+	Statistics         []DirectoryStatistics
+	Trends             []Trend
+	ExcludedDocuments  []ExcludedDocument
+	IsTruncated        bool
+	NetworkUnavailable bool
+	Connectivity       Connectivity
+	InvalidHyperlinks  []Hyperlink
+	Date               string
+	Metadata           ReportMetadata
+
+	// the signature of the json results (not part of the signed data itself)
+	Signature *ReportSignature `json:"-"`
+
+	// reports of several roots are written to separate files
+	name string
+}
+
+// check if any document of the report contains linked figures that are broken
+func (report *Report) HasBrokenImages() bool {
+
+	for _, document := range report.Documents {
+		if document.BrokenImages > 0 {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// check if any document of the report cites retracted publications
+func (report *Report) HasRetractedCitations() bool {
+
+	for _, document := range report.Documents {
+		if document.RetractedCitations > 0 {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// check if any document of the report could not be opened because it is password protected
+func (report *Report) HasLockedDocuments() bool {
+
+	for _, document := range report.Documents {
+		if document.IsLocked() {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// create a custom html report (either as single file or as directory with
+// separate style, script and data files)
+func (report *Report) create() bool {
+
+	// the report can be handed to other programs directly (i.e. in a container)
+	if options.Stdout {
+		return report.writeStdout()
+	}
+
+	// the results can also be written as json (i.e. to compare runs)
+	if options.Format == "json" {
+		return report.writeJson(report.path())
+	}
+
+	// the unique urls can be handed to other tools (i.e. a web archiving service)
+	if options.Format == formatUrls {
+		return report.writeUrls(report.path())
+	}
+
+	if options.SplitAssets {
+		return report.createSplit()
+	}
+
+	// the signed json results are written next to the page, so the signature
+	// embedded in the page can be verified
+	if signingKey != nil && report.writeJson(report.baseName()+".json") == false {
+		return false
+	}
+
+	// open a new file to write our report to
+	file, err := os.Create(report.baseName() + ".html")
+	if err != nil {
+		log.Println("Could not create report file")
+		return false
+	}
+	defer file.Close()
+
+	err = report.render(file, false)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	return true
+
+}
+
+// create a report directory with separate files for the html, css, js and json data
+func (report *Report) createSplit() bool {
+
+	err := os.MkdirAll(report.baseName(), 0755)
+	if err != nil {
+		log.Println("Could not create report directory")
+		return false
+	}
+
+	// the data of the report can be used by other pages (i.e. intranet portals),
+	// it is written first so its signature can be embedded in the page
+	if report.writeJson(filepath.Join(report.baseName(), "report.json")) == false {
+		return false
+	}
+
+	// write the page referencing the separate assets
+	file, err := os.Create(report.path())
+	if err != nil {
+		log.Println("Could not create report file")
+		return false
+	}
+	defer file.Close()
+
+	err = report.render(file, true)
+	if err != nil {
+		log.Println(err)
+		return false
+	}
+
+	assets := map[string]string{
+		"report.css": reportStyle,
+		"report.js":  reportScript,
+	}
+
+	for name, content := range assets {
+
+		err = ioutil.WriteFile(filepath.Join(report.baseName(), name), []byte(content), 0644)
+		if err != nil {
+			log.Println("Could not write " + name)
+			return false
+		}
+
+	}
+
+	return true
+
+}
+
+// write the data of the report as json file (and its signature if a signing
+// key is given)
+func (report *Report) writeJson(path string) bool {
+
+	if signingKey == nil {
+		return writeJsonFile(path, report)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Println("Could not serialize the report data")
+		return false
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		log.Println("Could not write " + path)
+		return false
+	}
+
+	// the exact bytes written are signed
+	report.Signature, err = signResults(path, data)
+	if err != nil {
+		log.Println("ERROR:", err)
+		return false
+	}
+
+	return true
+
+}
+
+// write the given data as indented json file
+func writeJsonFile(path string, value interface{}) bool {
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Println("Could not serialize the report data")
+		return false
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		log.Println("Could not write " + path)
+		return false
+	}
+
+	return true
+
+}
+
+// read a report previously written as json file
+func readReport(path string) (Report, error) {
+
+	var report Report
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+
+	err = json.Unmarshal(data, &report)
+
+	return report, err
+
+}
+
+// write the html report to the given writer, referencing the style and script
+// files instead of inlining them if requested
+func (report *Report) render(writer io.Writer, splitAssets bool) error {
+
+	functionMap := template.FuncMap{
+		"absolutePath": getAbsoluteFilePath,
+		"fileUrl":      fileUrl,
+		"domain":       urlDomain,
+		"trendChart":   trendChart,
+		"splitAssets":  func() bool { return splitAssets },
+		"style":        func() template.CSS { return template.CSS(reportStyle) },
+		"script":       func() template.JS { return template.JS(reportScript) },
+	}
+
+	// load our template from the templat file
+	tmpl, err := template.New("report").Funcs(functionMap).Parse(reportTemplate)
+	if err != nil {
+		return errors.New("Could not load template: " + err.Error())
+	}
+
+	// fill our template with content and write it to the writer
+	err = tmpl.ExecuteTemplate(writer, "report", report)
+	if err != nil {
+		return errors.New("Could not fill the template with report data: " + err.Error())
+	}
+
+	return nil
+
+}
+
+// get the path of the html file of the report
+func (report *Report) path() string {
+
+	if options.Format == "json" {
+		return report.baseName() + ".json"
+	}
+
+	if options.Format == formatUrls {
+		return report.baseName() + ".txt"
+	}
+
+	if options.SplitAssets {
+		return filepath.Join(report.baseName(), "index.html")
+	}
+
+	return report.baseName() + ".html"
+
+}
+
+// open the report in the standard browser
+func (report *Report) open() {
+	openReport(report.path())
+}
+
+// open the report with the given path in the standard browser
+func openReport(path string) {
+
+	// only html reports are meant to be read by humans
+	if options.Format != "html" {
+		return
+	}
+
+	// servers and continuous integration runs have no one to look at the report
+	if options.Open == false {
+		return
+	}
+
+	err := openInBrowser(path)
+	if err != nil {
+		log.Println("Could not open report")
+	}
+
+}
+
+// open the given file or address with the browser command of the options or the
+// default browser
+func openInBrowser(target string) error {
+
+	if arguments := strings.Fields(options.Browser); len(arguments) > 0 {
+		return exec.Command(arguments[0], append(arguments[1:], target)...).Start()
+	}
+
+	return open.Start(target)
+
+}
+
+// we define the name of our report
+var reportName string = "report"
+
+const reportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<title>Check hyperlinks in docx, pptx and xlsx files</title>
+<meta charset="utf-8">
+<meta name="author" content="Dr. med. Ramon Saccilotto, DKF, University Hospital Basel, Switzerland">
+
+{{if splitAssets}}<link rel="stylesheet" type="text/css" href="report.css">{{else}}<style type="text/css">
+{{style}}</style>{{end}}
+</head>
+<body>
+<main class="container">
+
+<h1>Directory searched</h1>
+
+<ul class="directories">
+{{range .Directories}}
+<li><a href="{{fileUrl .}}">{{absolutePath .}}</a></li>
+{{end}}
+</ul>
+
+<h1>Result of link validation</h1>
+
+
+{{if .ResultOfValidation}}
+<div class="result valid" role="status">
+<span aria-hidden="true">&#10004;</span> All files contain valid links
+</div>
+{{else}}
+<div class="result invalid" role="alert">
+<span aria-hidden="true">&#10008;</span> There are some files with invalid links
+</div>
+{{end}}
+
+{{if .NetworkUnavailable}}
+<div class="result invalid" role="alert">
+The network was unavailable, the external links were not checked
+</div>
+{{end}}
+
+{{if .Connectivity.IsBad}}
+<div class="result invalid" role="alert">
+The connectivity was bad during the run ({{.Connectivity.FailedBefore}} of {{len .Connectivity.Before}} canary urls failed before and {{.Connectivity.FailedAfter}} of {{len .Connectivity.After}} after checking the links), failed links are reported as not checked
+</div>
+{{end}}
+
+{{if .IsTruncated}}
+<div class="result invalid" role="alert">
+The run was truncated as its budget was exhausted, some links were not checked
+</div>
+{{end}}
+
+{{if .HasBrokenImages}}
+<div class="result invalid">
+The following files contain linked figures that can no longer be displayed:
+<ul class="figures">
+{{range .Documents}}{{if .BrokenImages}}
+<li><a href="{{fileUrl .Path}}">{{.Path}}</a> ({{.BrokenImages}} figures)</li>
+{{end}}{{end}}
+</ul>
+</div>
+{{end}}
+
+{{if .HasRetractedCitations}}
+<div class="result invalid" role="alert">
+The following files cite retracted publications:
+<ul class="figures">
+{{range .Documents}}{{if .RetractedCitations}}
+<li><a href="{{fileUrl .Path}}">{{.Path}}</a> ({{.RetractedCitations}} retracted)</li>
+{{end}}{{end}}
+</ul>
+</div>
+{{end}}
+
+{{if .HasLockedDocuments}}
+<div class="result invalid" role="alert">
+The following files are password protected and could not be checked:
+<ul class="figures">
+{{range .Documents}}{{if .IsLocked}}
+<li><a href="{{fileUrl .Path}}">{{.Path}}</a> ({{if eq .Protection "locked"}}none of the configured passwords is correct{{else}}unsupported encryption{{end}})</li>
+{{end}}{{end}}
+</ul>
+</div>
+{{end}}
+
+{{if .Trends}}
+<h1>Broken links over time</h1>
+
+{{range .Trends}}
+<figure class="trend">
+{{trendChart .}}
+<figcaption>{{absolutePath .Directory}}</figcaption>
+</figure>
+{{end}}
+{{end}}
+
+{{if .Domains}}
+<h1>Results by domain</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Results aggregated by target domain</caption>
+<thead>
+<tr><th scope="col">Domain</th><th scope="col">Links checked</th><th scope="col">Broken</th><th scope="col">Average latency</th></tr>
+</thead>
+<tbody>
+{{range .Domains}}
+<tr class="{{if .Broken}}invalid{{else}}valid{{end}}">
+<td>{{.Domain}}</td>
+<td>{{.Links}}</td>
+<td>{{.Broken}}</td>
+<td>{{.AverageLatency}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .BrokenDomains}}
+<h1>Top broken domains</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Registered domains responsible for the most broken links</caption>
+<thead>
+<tr><th scope="col">Domain</th><th scope="col">Hosts</th><th scope="col">Broken</th><th scope="col">Links checked</th><th scope="col">Ownership hint</th></tr>
+</thead>
+<tbody>
+{{range .BrokenDomains}}
+<tr class="invalid">
+<td>{{.Domain}}</td>
+<td>{{range $index, $host := .Hosts}}{{if $index}}, {{end}}{{$host}}{{end}}</td>
+<td>{{.Broken}}</td>
+<td>{{.Links}}</td>
+<td>{{if .Hint}}{{.Hint}}{{else if .Organization}}{{.Organization}}{{else}}unknown{{end}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .Owners}}
+<h1>Results by owner</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Results aggregated by document owner</caption>
+<thead>
+<tr><th scope="col">Owner</th><th scope="col">Documents</th><th scope="col">With broken links</th></tr>
+</thead>
+<tbody>
+{{range .Owners}}
+<tr class="{{if .Invalid}}invalid{{else}}valid{{end}}">
+<td>{{.Owner}}</td>
+<td>{{.Documents}}</td>
+<td>{{.Invalid}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .HostExpiries}}
+<h1>Expiry of the linked hosts</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Expiry of the certificates and domain registrations of the linked hosts</caption>
+<thead>
+<tr><th scope="col">Host</th><th scope="col">Certificate expires</th><th scope="col">Domain expires</th></tr>
+</thead>
+<tbody>
+{{range .HostExpiries}}
+<tr class="{{if or .IsCertificateExpiring .IsDomainExpiring}}invalid{{else}}valid{{end}}">
+<td>{{.Host}}</td>
+<td>{{if .CertificateExpires.IsZero}}unknown{{else}}{{.CertificateExpires.Format "2006-01-02"}}{{end}}</td>
+<td>{{if .DomainExpires.IsZero}}unknown{{else}}{{.DomainExpires.Format "2006-01-02"}}{{end}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .Dependencies}}
+<h1>Linked documents</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Links between the documents</caption>
+<thead>
+<tr><th scope="col">Document</th><th scope="col">Linked document</th><th scope="col">Status</th></tr>
+</thead>
+<tbody>
+{{range .Dependencies}}
+<tr class="{{if and .Exists (or (not .IsChecked) .IsValid)}}valid{{else}}invalid{{end}}">
+<td>{{.Document}}</td>
+<td><a href="{{fileUrl .Target}}">{{.Target}}</a></td>
+<td>{{if not .Exists}}missing{{else if not .IsChecked}}not part of this run{{else if .IsValid}}all working{{else}}some broken{{end}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .ZoneLinks}}
+<h1>Reachability by network zone</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Links checked from several network zones</caption>
+<thead>
+<tr><th scope="col">Document</th><th scope="col">Link</th>{{range .ZoneNames}}<th scope="col">{{.}}</th>{{end}}<th scope="col">Reachability</th></tr>
+</thead>
+<tbody>
+{{$zones := .ZoneNames}}
+{{range .ZoneLinks}}
+<tr class="{{if .Link.IsWorking}}valid{{else}}invalid{{end}}">
+<td>{{.Document}}</td>
+<td><a href="{{.Link.Url}}">{{.Link.Url}}</a></td>
+{{$link := .Link}}{{range $zones}}<td>{{$link.ZoneStatus .}}</td>{{end}}
+<td>{{.Link.Reachability}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+<div class="controls" role="search">
+<label for="filter" class="visually-hidden">Filter</label>
+<input type="text" id="filter" placeholder="Filter by document, link, tooltip or domain">
+<label for="sort" class="visually-hidden">Sort order</label>
+<select id="sort">
+<option value="document">Sort by document</option>
+<option value="status">Sort by status</option>
+<option value="domain">Sort by domain</option>
+<option value="owner">Sort by owner</option>
+</select>
+</div>
+
+<ul class="documents" aria-label="Documents">
+{{range .Documents}}
+<li class="result" data-path="{{.Path}}" data-owner="{{.Owner}}" data-status="{{if .IsValid}}valid{{else}}invalid{{end}}">
+<details{{if not .IsValid}} open{{end}}>
+<summary><h2 class="{{if .IsValid}}valid{{else}}invalid{{end}}"><a href="{{fileUrl .Path}}">{{.Path}}</a></h2> <span class="count">{{len .Hyperlinks}} links{{if .AnchorLinks}} ({{.AnchorLinks}} within the document){{end}}, {{if .IsValid}}all working{{else}}some broken{{end}}{{if .Owner}}, owned by {{.Owner}}{{end}}{{if .Protection}}, password protected{{end}}{{if .IsTemplate}}, template{{end}}{{if .Copies}}, {{len .Copies}} identical copies{{end}}</span></summary>
+{{if .BrokenImages}}<p class="warning" role="note">{{.BrokenImages}} linked figures will not be displayed</p>{{end}}
+{{if .RetractedCitations}}<p class="warning" role="note">{{.RetractedCitations}} links point to retracted publications</p>{{end}}
+{{if .BrokenObjects}}<p class="warning" role="note">{{.BrokenObjects}} linked objects can no longer be updated from their source</p>{{end}}
+{{if .Copies}}<p class="warning" role="note">identical copies of this document (not checked again): {{range $index, $path := .Copies}}{{if $index}}, {{end}}<a href="{{fileUrl $path}}">{{$path}}</a>{{end}}</p>{{end}}
+{{range .QuotaWarnings}}<p class="warning" role="note">the document contains {{.}}, consider using a reference list</p>{{end}}
+{{range .RepeatedLinks}}<p class="warning" role="note">{{.Url}} is linked {{.Count}} times</p>{{end}}
+{{if .OrphanedLinks}}<p class="warning" role="note">{{len .OrphanedLinks}} links are left over from deleted content and should be removed: {{range $index, $link := .OrphanedLinks}}{{if $index}}, {{end}}{{$link.Url}}{{end}}</p>{{end}}
+{{range .ConflictingLinks}}<p class="warning" role="note">the text &ldquo;{{.Text}}&rdquo; links to different targets: {{range $index, $url := .Urls}}{{if $index}}, {{end}}{{$url}}{{end}}</p>{{end}}
+
+<table class="links">
+<caption class="visually-hidden">Links in {{.Path}}</caption>
+<thead>
+<tr><th scope="col">Status</th><th scope="col">Link</th><th scope="col">Type</th><th scope="col">Details</th></tr>
+</thead>
+<tbody>
+{{range .Hyperlinks}}
+<tr class="result {{if .NotChecked}}unchecked{{else if .IsWorking}}valid{{else}}invalid{{end}}" data-url="{{.Url}}" data-tooltip="{{.Tooltip}}" data-domain="{{domain .Url}}" data-status="{{if .NotChecked}}unchecked{{else if .IsWorking}}valid{{else}}invalid{{end}}">
+<td class="status"><span class="icon" aria-hidden="true"></span>{{if .HasUnknownScheme}}Unknown scheme{{else if and .IsBlocked .NotChecked}}Unverifiable{{else if .NotChecked}}Not checked{{else if .IsWorking}}Working{{else}}Broken{{end}}</td>
+<td><a href="{{.Url}}">{{.Url}}</a>{{if .Tooltip}}<span class="tooltip">{{.Tooltip}}</span>{{end}}</td>
+<td>{{.Category}}{{if not .IsExternal}} (internal){{end}}</td>
+<td>{{if .Reason}}<span class="reason">{{.Reason}}</span>{{end}}{{if and .RequestUrl (ne .RequestUrl .Url)}}<span class="encoded">checked as {{.RequestUrl}}</span>{{end}}{{if .ResolvedPath}}<span class="encoded">resolved to {{.ResolvedPath}}</span>{{end}}{{if .IsOverridden}}<span class="encoded">status reported by another system (not checked)</span>{{end}}{{range .Warnings}}<span class="warning"><span aria-hidden="true">&#9888;</span> Warning: {{.}}</span>{{end}}{{if .CleanUrl}}<span class="encoded">consider replacing it with the url without tracking parameters <a href="{{.CleanUrl}}">{{.CleanUrl}}</a></span>{{end}}{{if .Replacements}}<span class="encoded">possible replacements:{{range .Replacements}} <a href="{{.}}">{{.}}</a>{{end}}</span>{{end}}{{if .ArchiveUrl}}<span class="encoded">archived as <a href="{{.ArchiveUrl}}">{{.ArchiveUrl}}</a></span>{{end}}{{if .EvidencePath}}<span class="encoded"><a href="{{fileUrl .EvidencePath}}">evidence</a></span>{{end}}{{if .ScreenshotPath}}<a href="{{fileUrl .ScreenshotPath}}"><img class="screenshot" src="{{fileUrl .ScreenshotPath}}" alt="Screenshot of {{.Url}}" loading="lazy"></a>{{end}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+</details>
+</li>
+{{end}}
+</ul>
+
+{{if .ExcludedDocuments}}
+<h1>Excluded by age</h1>
+
+<ul class="figures" aria-label="Documents excluded by age">
+{{range .ExcludedDocuments}}
+<li><a href="{{fileUrl .Path}}">{{.Path}}</a> (last modified {{.Modified.Format "2006-01-02"}})</li>
+{{end}}
+</ul>
+{{end}}
+
+{{if .LinkTextIssues}}
+<h1>Link text audit</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Links whose display text does not describe their target</caption>
+<thead>
+<tr><th scope="col">Document</th><th scope="col">Link</th><th scope="col">Display text</th><th scope="col">Issue</th></tr>
+</thead>
+<tbody>
+{{range .LinkTextIssues}}
+<tr>
+<td><a href="{{fileUrl .Document}}">{{.Document}}</a></td>
+<td><a href="{{.Url}}">{{.Url}}</a></td>
+<td>{{.Text}}</td>
+<td>{{.Issue}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+{{if .IgnoredLinks}}
+<h1>Ignored links</h1>
+
+<table class="domains">
+<caption class="visually-hidden">Links ignored by the configuration until the given date</caption>
+<thead>
+<tr><th scope="col">Document</th><th scope="col">Link</th><th scope="col">Ticket</th><th scope="col">Reason</th><th scope="col">Ignored until</th></tr>
+</thead>
+<tbody>
+{{range .IgnoredLinks}}
+<tr>
+<td><a href="{{fileUrl .Document}}">{{.Document}}</a></td>
+<td><a href="{{.Url}}">{{.Url}}</a></td>
+<td>{{.Ticket}}</td>
+<td>{{.Reason}}</td>
+<td>{{.Until}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+
+</main>
+
+<footer class="info">
+<p class="time">Link validation conducted on {{.Date}} in {{.Metadata.Duration}} on {{.Metadata.Hostname}} (validate-links {{.Metadata.Build}})</p>
+{{if .Connectivity.Before}}<p class="time">Connectivity verified with {{len .Connectivity.Before}} canary urls ({{.Connectivity.FailedBefore}} failed before and {{.Connectivity.FailedAfter}} after checking the links)</p>{{end}}
+{{if .Signature}}<p class="signature">The json results ({{.Signature.Results}}) are signed with the key {{.Signature.Fingerprint}}: <code>{{.Signature.Signature}}</code> (verify them with <code>validate-links verify</code>)</p>{{end}}
+{{if .Metadata.Configuration}}<p class="configuration">{{range $index, $setting := .Metadata.Configuration}}{{if $index}} &middot; {{end}}{{$setting}}{{end}}</p>{{end}}
+<p class="author">&copy;&nbsp;2014, Department of Clinical Research, University Hospital Basel</p>
+</footer>
+
+{{if splitAssets}}<script src="report.js"></script>{{else}}<script>
+{{script}}</script>{{end}}
+</body>
+</html>
+`
+
+const reportStyle = `
+* {
+font-family: "Helvetica Neue", "Helvetica", "Calibri", "Arial", sans-serif;
+font-weight: normal;
+font-size: 14px;
+}
+
+a {
+text-decoration: none;
+color: inherit;
+font-size: inherit;
+}
+
+body {
+background-color: #eaeaea;
+}
+
+.container {
+min-width: 600px;
+margin: 40px 20px 20px 20px;
+padding: 20px;
+padding-bottom: 40px;
+border: 1px solid #ccc;
+background-color: #fff;
+box-shadow: 0px 1px 1px rgba(74, 69, 69, 0.6), 0px -1px 1px rgba(50, 50, 50, 0.05);
+}
+
+.info {
+margin: 20px;
+}
+
+.info p {
+font-size: 12px;
+opacity: 0.2;
+}
+
+.info:hover p{
+opacity: 1;
+transition: opacity 500ms;
+}
+
+.info .signature code {
+font-family: monospace;
+font-size: 11px;
+word-break: break-all;
+}
+
+h1 {
+margin: 0px;
+padding: 0px;
+margin-bottom: 15px;
+font-size: 20px;
+border-bottom: 1px solid #ccc;
+font-weight: bold;
+padding-bottom: 10px;
+
+}
+
+ul {
+margin: 0px;
+padding: 0px;
+list-style-type: none;
+padding-left: 5px;
+}
+
+ul li {
+position: relative;
+padding-left: 28px;
+}
+
+ul.directories > li + li{
+margin-top: 20px;
+}
+
+ul li:before {
+content: "";
+background-position: top left;
+background-repeat: no-repeat;
+display: block;
+position: absolute;
+left: 0px;
+top: 0px;
+width: 20px;
+height: 20px;
+}
+
+ul.directories {
+margin-bottom: 40px;
+}
+
+ul.directories li:before {
+background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAAj0lEQVQ4T2NkYGBgBGJ2IIaBv0DGbyQ+XiZI81Ig/gLE/6EqFYB0PRCfJMYQkAELgDgBSTEHkD2DCM0gvaewGUCEXriSdSADzgPxLSQvEGsASK8aiLgPxIrE6kJTdx9kwB0gViHTgDtUMeAo0HZrMl1wlNJYWDBqAAM4DNAzE7ERAtLLAyLQszOxBoDU/QQAylQgG9KLVSEAAAAASUVORK5CYII=);
+}
+
+ul.documents > li:before {
+background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABQAAAAUCAYAAACNiR0NAAABqElEQVQ4T52VPSiFURjH3VDyMRhEDK6Uj0wG+VgskhSDDJLMmCUD8p2S7RbJxKZMLGKgJBsGJKQsZCeUj9//dl699ziXk1O/3vs873P+5/8+57zvjaQkjhHCbCvnCs9JrrpuRKzkNXGfh+AKNUswZ9faghcUVHoInlHzAhswG64PBNNM8pBrgyX4Sfxu5STYCmuwA1PBfQnWmZVukzirIp/rEGwk9wRbcAyDqgkEe/i9D5dwB1lGoJqrNkqLhoccqt+vkAMtMA4TgWA3QTnMQA08wxWUQa9DUK4zrEXWiUsDwS4C9enIONrl+gGjsGcE1dtmS0ThpKmNb2hYUI/bDidQDxKdNovokaNQ4RDcJqeN+yGo5sagFh6NwH1IUJvQ5hAcSuZQ1uVAR0cTN81ktUEOi6DEIXhgcgkOl42Qoz7ehkJogk5HwYDtMJOEdlNjEfqtSerPKeQZl7ameq7x7TBcELx6+SQXXHYdOR0rnYhfBdMpKPYU1AH/02EBFfqa+IwOH4eppmc+gg8+Dn2E7BpnD2+oGv6PGnPmIWp/YH3/AlxrvpEc+wLSwV8VusxZtAAAAABJRU5ErkJggg==);
+top: -2px;
+}
+
+ul.documents > li + li {
+margin-top: 25px;
+}
+
+div.controls {
+margin-bottom: 25px;
+}
+
+div.controls input {
+width: 60%;
+padding: 4px;
+}
+
+div.result {
+border-width: 1px;
+border-style: solid;
+border-radius: 2px;
+padding: 10px;
+margin-bottom: 25px;
+}
+
+div.result.valid {
+border-color: #20d420;
+background-color: #dcffe7;
+}
+
+div.result.invalid {
+border-color: #db2d2d;
+background-color: #fff5f5;
+}
+
+h2 {
+font-weight: bold;
+display: inline;
+}
+
+summary {
+cursor: pointer;
+margin-bottom: 10px;
+}
+
+summary span.count {
+color: #999;
+font-size: 12px;
+}
+
+table.links, table.domains {
+width: 100%;
+border-collapse: collapse;
+}
+
+table.domains {
+margin-bottom: 40px;
+}
+
+figure.trend {
+margin: 0px 0px 30px 0px;
+}
+
+figure.trend figcaption {
+font-size: 12px;
+color: #999;
+}
+
+figure.trend rect {
+fill: #db2d2d;
+}
+
+figure.trend text {
+font-size: 10px;
+fill: #666;
+}
+
+table.links th, table.links td, table.domains th, table.domains td {
+text-align: left;
+vertical-align: top;
+padding: 6px 8px;
+font-size: 12px;
+border-bottom: 1px solid #eee;
+}
+
+table.links th, table.domains th {
+font-weight: bold;
+}
+
+table.links td.status {
+white-space: nowrap;
+}
+
+table.links span.icon {
+display: inline-block;
+width: 20px;
+height: 16px;
+vertical-align: text-bottom;
+background-position: top left;
+background-repeat: no-repeat;
+}
+
+table.links tr.invalid span.icon {
+background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAA/UlEQVQ4T2NkoBAwUqifAa8B/xkYjIAW/AcqOo/LIpwGADVLAjVdBxkAxFpAhc+xGYLPgHVADYFQTeuACoOJNgBopT9Q8QY0Df5AQzahG4LhAqBmLqCiy0CsBMTToBqygPQ9INYBaviObAg2A3qACoqhihqhdD2U7gFqKMVpANB2Q6DkKSBmwWHAH6C4GXKswF0A1AxiHwJiGyQbJkPZuUhiR4BsO6BiUOwg0gGQB/LnVGwhjUUsC2jAdLgBQM0iQM5NIBZCU7wCakk4mvg7IF8daMgbsBeABiQAqflE2g5TFg/UvAhmAD9QFGQIB5GG/ABZCNT8ibaZiRjXAABQjy8Rw0RFZAAAAABJRU5ErkJggg==);
+}
+
+table.links tr.valid span.icon {
+background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABQAAAAUCAYAAACNiR0NAAAA6UlEQVQ4T2NkoDJgpLJ5DKMGEhei/Ve1DRj/M/T///01sNDwwQdkXSSHIdSw/YyMDAL//zMcKNC56ki2gWiGffjL8NuhWOfWRbIMJMYwkMEYXoZo/J9foHMtEWZz/2UNB0ZG5vVQb2J1GUwthoETrmidZ2RkNPj3769joe6NA0ALEpgYGOaDNADDDK9hWF3Ye0VNn4WR9cJ/hv8P/v//P5GJkamfWMOwGggSnHhZM5+BiWkCzBvEuAynl2ESQK/PB3o9gRTDcLoQJNFxRomfg4NzAzBpFKAnDXzJn+SETSgvjRpIKIQIywMAWmd1FTm7YC8AAAAASUVORK5CYII=);
+background-size: 16px 16px;
+}
+
+.visually-hidden {
+position: absolute;
+width: 1px;
+height: 1px;
+overflow: hidden;
+clip: rect(0 0 0 0);
+}
+
+span.reason {
+display: block;
+color: #db2d2d;
+}
+
+span.encoded {
+display: block;
+color: #999;
+}
+
+span.tooltip {
+display: block;
+color: #666;
+font-style: italic;
+}
+
+img.screenshot {
+display: block;
+width: 160px;
+margin-top: 4px;
+border: 1px solid #ddd;
+}
+
+span.category {
+display: inline-block;
+margin-left: 8px;
+padding: 0px 4px;
+border: 1px solid #ccc;
+border-radius: 2px;
+color: #666;
+font-size: 10px;
+}
+
+p.warning {
+margin: 0px 0px 10px 0px;
+color: #e39b00;
+}
+
+ul.figures {
+margin-top: 10px;
+}
+
+span.warning {
+display: block;
+color: #e39b00;
+}
+
+.valid {
+color: #20d420;
+}
+
+.invalid {
+color: #db2d2d;
+}
+
+.unchecked {
+color: #999;
+}
+
+
+
+`
+
+const reportScript = `(function() {
+	var list = document.querySelector("ul.documents");
+	var documents = Array.prototype.slice.call(list.children);
+
+	// remember the original order of all elements
+	documents.forEach(function(item, index) {
+		item.dataset.index = index;
+		Array.prototype.slice.call(item.querySelectorAll("table.links tbody > tr")).forEach(function(link, linkIndex) {
+			link.dataset.index = linkIndex;
+		});
+	});
+
+	function compare(a, b, key) {
+		if (key == "status" && a.dataset.status != b.dataset.status) {
+			return a.dataset.status == "invalid" ? -1 : 1;
+		}
+		if (key == "domain" && a.dataset.domain != b.dataset.domain) {
+			return (a.dataset.domain || "") < (b.dataset.domain || "") ? -1 : 1;
+		}
+		if (key == "owner" && a.dataset.owner != b.dataset.owner) {
+			return (a.dataset.owner || "\uffff") < (b.dataset.owner || "\uffff") ? -1 : 1;
+		}
+		if ((key == "document" || key == "owner") && a.dataset.path != b.dataset.path) {
+			return a.dataset.path < b.dataset.path ? -1 : 1;
+		}
+		return a.dataset.index - b.dataset.index;
+	}
+
+	function sort() {
+		var key = document.getElementById("sort").value;
+		documents.sort(function(a, b) {
+			return compare(a, b, key == "domain" ? "document" : key);
+		});
+		documents.forEach(function(item) {
+			var links = item.querySelector("table.links tbody");
+			var children = Array.prototype.slice.call(links.children);
+			children.sort(function(a, b) {
+				return compare(a, b, key);
+			});
+			children.forEach(function(link) {
+				links.appendChild(link);
+			});
+			list.appendChild(item);
+		});
+	}
+
+	function filter() {
+		var text = document.getElementById("filter").value.toLowerCase();
+		documents.forEach(function(item) {
+			var matchesDocument = (item.dataset.path + " " + item.dataset.owner).toLowerCase().indexOf(text) >= 0;
+			var visibleLinks = 0;
+			Array.prototype.slice.call(item.querySelectorAll("table.links tbody > tr")).forEach(function(link) {
+				var matches = matchesDocument || (link.dataset.url + " " + link.dataset.tooltip + " " + link.dataset.domain).toLowerCase().indexOf(text) >= 0;
+				link.style.display = matches ? "" : "none";
+				if (matches) visibleLinks++;
+			});
+			item.style.display = (matchesDocument || visibleLinks > 0) ? "" : "none";
+
+			// show the matching links of collapsed documents
+			if (text && visibleLinks > 0) {
+				item.querySelector("details").open = true;
+			}
+		});
+	}
+
+	document.getElementById("sort").onchange = sort;
+	document.getElementById("filter").oninput = filter;
+})();
+`
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// define a writer failing on every write (i.e. a full disk)
+type failingWriter struct{}
+
+func (writer failingWriter) Write(data []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+// check that the cause of a failed rendering is not lost
+func TestRenderError(t *testing.T) {
+
+	report := Report{}
+
+	err := report.render(failingWriter{}, false)
+	if err == nil || strings.Contains(err.Error(), "no space left on device") == false {
+		t.Errorf("the cause of the failed rendering is missing: %v", err)
+	}
+
+}
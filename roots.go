@@ -0,0 +1,251 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// define a custom structure for a directory that is validated as separate job
+type RootConfig struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// define a custom structure for the index report linking the reports of several roots
+type ReportIndex struct {
+	ResultOfValidation bool
+	Date               string
+	Build              BuildInfo
+	Entries            []IndexEntry
+}
+
+// define a custom structure for the summary of a single root in the index
+type IndexEntry struct {
+	Name      string
+	Directory string
+	Report    string
+	IsValid   bool
+	Documents int
+	Links     int
+	Broken    int
+}
+
+// get the directories to validate, directories given on the command line take
+// precedence over the roots of the configuration file
+func configuredRoots() []RootConfig {
+
+	roots := []RootConfig{}
+
+	for _, directory := range options.Roots {
+		roots = append(roots, RootConfig{Path: directory})
+	}
+
+	if len(roots) == 0 {
+		roots = append(roots, config.Roots...)
+	}
+
+	// we are only interested in the current directory by default
+	if len(roots) == 0 {
+		roots = append(roots, RootConfig{Path: "."})
+	}
+
+	// every root needs a distinct name for its report file
+	names := make(map[string]bool)
+
+	for index := range roots {
+
+		name := roots[index].Name
+		if name == "" {
+			name = filepath.Base(getAbsoluteFilePath(roots[index].Path))
+		}
+
+		name = matchers["reportName"].ReplaceAllString(name, "-")
+		unique := name
+
+		for suffix := 2; names[unique]; suffix++ {
+			unique = name + "-" + strconv.Itoa(suffix)
+		}
+
+		names[unique] = true
+		roots[index].Name = unique
+
+	}
+
+	return roots
+
+}
+
+// validate all roots as independent jobs running in parallel and return their reports
+func validateRoots(roots []RootConfig) []Report {
+
+	reports := make([]Report, len(roots))
+
+	var jobs sync.WaitGroup
+
+	for index, root := range roots {
+
+		jobs.Add(1)
+
+		go func(index int, root RootConfig) {
+
+			defer jobs.Done()
+
+			report := validateDirectories([]string{root.Path})
+			report.name = root.Name
+
+			reports[index] = report
+
+		}(index, root)
+
+	}
+
+	jobs.Wait()
+
+	return reports
+
+}
+
+// summarize the given reports in a single index
+func newReportIndex(reports []Report) ReportIndex {
+
+	index := ReportIndex{ResultOfValidation: true, Date: formatTimestamp(clock()), Build: currentBuildInfo()}
+
+	for _, report := range reports {
+
+		entry := IndexEntry{
+			Name:    report.name,
+			Report:  filepath.ToSlash(report.path()),
+			IsValid: report.ResultOfValidation,
+		}
+
+		for _, statistics := range report.Statistics {
+			entry.Directory = statistics.Directory
+			entry.Documents += statistics.Documents
+			entry.Links += statistics.Links
+			entry.Broken += statistics.Broken
+		}
+
+		if entry.IsValid == false {
+			index.ResultOfValidation = false
+		}
+
+		index.Entries = append(index.Entries, entry)
+
+	}
+
+	return index
+
+}
+
+// create the index report (as html or json file)
+func (index *ReportIndex) create() bool {
+
+	if options.Format == "json" {
+		return writeJsonFile(index.path(), index)
+	}
+
+	file, err := os.Create(index.path())
+	if err != nil {
+		log.Println("Could not create index file")
+		return false
+	}
+	defer file.Close()
+
+	functionMap := template.FuncMap{
+		"absolutePath": getAbsoluteFilePath,
+		"fileUrl":      fileUrl,
+		"style":        func() template.CSS { return template.CSS(reportStyle) },
+	}
+
+	tmpl, err := template.New("index").Funcs(functionMap).Parse(indexTemplate)
+	if err != nil {
+		log.Println("Could not load index template")
+		return false
+	}
+
+	err = tmpl.Execute(file, index)
+	if err != nil {
+		log.Println("Could not fill the index template with report data")
+		return false
+	}
+
+	return true
+
+}
+
+// get the path of the index file
+func (index *ReportIndex) path() string {
+
+	if options.Format == "json" {
+		return reportName + ".json"
+	}
+
+	return reportName + ".html"
+
+}
+
+// get the name of the report files without extension (reports of several roots
+// are distinguished by the name of their root)
+func (report *Report) baseName() string {
+
+	if report.name == "" {
+		return reportName
+	}
+
+	return reportName + "-" + strings.ToLower(report.name)
+
+}
+
+const indexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<title>Check hyperlinks in docx, pptx and xlsx files</title>
+<meta charset="utf-8">
+<style type="text/css">
+{{style}}</style>
+</head>
+<body>
+<main class="container">
+
+<h1>Result of link validation</h1>
+
+{{if .ResultOfValidation}}
+<div class="result valid" role="status">
+<span aria-hidden="true">&#10004;</span> All directories contain valid links
+</div>
+{{else}}
+<div class="result invalid" role="alert">
+<span aria-hidden="true">&#10008;</span> There are some directories with invalid links
+</div>
+{{end}}
+
+<table class="domains">
+<caption class="visually-hidden">Reports of all directories</caption>
+<thead>
+<tr><th scope="col">Directory</th><th scope="col">Documents</th><th scope="col">Links checked</th><th scope="col">Broken</th></tr>
+</thead>
+<tbody>
+{{range .Entries}}
+<tr class="{{if .IsValid}}valid{{else}}invalid{{end}}">
+<td><a href="{{.Report}}">{{.Name}}</a> (<a href="{{fileUrl .Directory}}">{{absolutePath .Directory}}</a>)</td>
+<td>{{.Documents}}</td>
+<td>{{.Links}}</td>
+<td>{{.Broken}}</td>
+</tr>
+{{end}}
+</tbody>
+</table>
+
+</main>
+<footer class="info">
+<p class="time">Link validation conducted on {{.Date}} (validate-links {{.Build}})</p>
+<p class="author">&copy;&nbsp;2014, Department of Clinical Research, University Hospital Basel</p>
+</footer>
+</body>
+</html>
+`
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// links with schemes not listed here are reported as unknown scheme instead of
+// being checked with a request that is bound to fail
+var knownSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"ftp":    true,
+	"file":   true,
+	"tel":    true,
+	"sms":    true,
+	"ldap":   true,
+	"ldaps":  true,
+	"mailto": true,
+}
+
+// define the name used in the configuration to enable or disable all unknown schemes
+const schemeUnknown = "unknown"
+
+// define the reasons for links that are not checked because of their scheme
+const (
+	reasonUnknownScheme     = "unknown scheme"
+	reasonSchemeDisabled    = "the scheme is not checked"
+	reasonSchemeUnsupported = "links with this scheme cannot be checked"
+)
+
+// get the lower case scheme of the target (local paths and relative links are
+// considered file links)
+func linkScheme(target string) string {
+
+	if isWindowsDrivePath(target) || strings.HasPrefix(target, `\\`) {
+		return "file"
+	}
+
+	match := matchers["scheme"].FindStringSubmatch(strings.TrimSpace(target))
+	if match == nil {
+		return "file"
+	}
+
+	return strings.ToLower(match[1])
+
+}
+
+// check if links with the given scheme should be validated according to the
+// configuration (all schemes are enabled by default)
+func isSchemeEnabled(scheme string) bool {
+
+	if isKnownScheme(scheme) == false {
+		scheme = schemeUnknown
+	}
+
+	isEnabled, isConfigured := config.Schemes[scheme]
+
+	return isConfigured == false || isEnabled
+
+}
+
+// check if the scheme is one we can validate (or one of the custom schemes of the
+// configuration)
+func isKnownScheme(scheme string) bool {
+	return knownSchemes[scheme] || findCustomScheme(scheme) != nil
+}
+
+// check if links with the given scheme are validated with a http request
+func isHttpScheme(scheme string) bool {
+	return scheme == "http" || scheme == "https"
+}
+
+// define a validator for links to servers not speaking http (i.e. ftp and ldap),
+// which only checks that the server accepts connections
+type connectionValidator struct {
+	scheme      string
+	defaultPort string
+}
+
+// the validator handles all links with its scheme
+func (validator *connectionValidator) Matches(link *Hyperlink) bool {
+	return linkScheme(link.Url) == validator.scheme
+}
+
+// connect to the server of the link
+func (validator *connectionValidator) Validate(link *Hyperlink) {
+
+	target, err := url.Parse(link.RequestUrl)
+	if err != nil || target.Hostname() == "" {
+		link.IsWorking = false
+		return
+	}
+
+	port := target.Port()
+	if port == "" {
+		port = validator.defaultPort
+	}
+
+	connection, err := net.DialTimeout("tcp", net.JoinHostPort(target.Hostname(), port), 15000*time.Millisecond)
+	if err != nil {
+		link.IsWorking = false
+		return
+	}
+
+	connection.Close()
+	link.IsWorking = true
+
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+)
+
+// define the scopes of the links that can be checked
+const (
+	// check only the links to servers outside of our network (i.e. in the ci)
+	scopeExternal = "external"
+	// check only the links to our own network and to files (i.e. from inside the hospital)
+	scopeInternal = "internal"
+	// check all links
+	scopeAll = "all"
+)
+
+// check the scope given on the command line
+func validateScope(scope string) error {
+
+	switch scope {
+	case scopeExternal, scopeInternal, scopeAll:
+		return nil
+	}
+
+	return errors.New("unknown scope " + scope + " (use external, internal or all)")
+
+}
+
+// get the scope of the given link, links to files and to hosts of our network
+// are internal (links without host, i.e. mail addresses and phone numbers, are
+// part of all scopes)
+func linkScope(link *Hyperlink) string {
+
+	if linkScheme(link.Url) == "file" {
+		return scopeInternal
+	}
+
+	host := urlDomain(link.Url)
+	if host == "" {
+		return scopeAll
+	}
+
+	if isIntranetHost(host) {
+		return scopeInternal
+	}
+
+	return scopeExternal
+
+}
+
+// check if the link is part of the scope of the options
+func isInScope(link *Hyperlink) bool {
+
+	if options.Scope == scopeAll {
+		return true
+	}
+
+	scope := linkScope(link)
+
+	return scope == scopeAll || scope == options.Scope
+
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// the names of the chrome executables searched for if none is given
+var chromeExecutables = []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome"}
+
+// the time chrome may take to render a page
+const screenshotTimeout = 30 * time.Second
+
+// find the chrome executable only once per run
+var (
+	chromeOnce sync.Once
+	chromePath string
+)
+
+// get the path of the chrome executable of the options or the first one found
+// in the path (an empty string if chrome is not installed), which is used for
+// screenshots and the browser fallback
+func findChrome() string {
+
+	chromeOnce.Do(func() {
+
+		if options.Chrome != "" {
+			chromePath = options.Chrome
+			return
+		}
+
+		for _, name := range chromeExecutables {
+			if path, err := exec.LookPath(name); err == nil {
+				chromePath = path
+				return
+			}
+		}
+
+		log.Println("ERROR: no headless chrome found, screenshots are not captured and blocked links are not checked in the browser")
+
+	})
+
+	return chromePath
+
+}
+
+// check if a screenshot of the link should be captured (for broken and suspect
+// external links only)
+func (link *Hyperlink) needsScreenshot() bool {
+
+	if link.IsExternal == false || link.NotChecked || isHttpScheme(linkScheme(link.Url)) == false {
+		return false
+	}
+
+	return link.IsWorking == false || len(link.Warnings) > 0
+
+}
+
+// capture a screenshot of what the target of the link renders with headless
+// chrome (i.e. to detect parked domains or login walls)
+func (link *Hyperlink) captureScreenshot(documentPath string) {
+
+	chrome := findChrome()
+	if chrome == "" {
+		return
+	}
+
+	err := os.MkdirAll(options.ScreenshotDirectory, 0755)
+	if err != nil {
+		log.Println("ERROR: could not create the screenshot directory")
+		return
+	}
+
+	digest := sha1.Sum([]byte(documentPath + "\x00" + link.Url))
+	path := getAbsoluteFilePath(filepath.Join(options.ScreenshotDirectory, hex.EncodeToString(digest[:8])+".png"))
+
+	// a screenshot of a previous run must not be mistaken for a new one
+	os.Remove(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), screenshotTimeout)
+	defer cancel()
+
+	err = exec.CommandContext(ctx, chrome, "--headless", "--disable-gpu", "--hide-scrollbars",
+		"--window-size=1280,800", "--screenshot="+path, link.RequestUrl).Run()
+
+	if _, statError := os.Stat(path); err != nil || statError != nil {
+		log.Println("ERROR: could not capture a screenshot of " + redactUrl(link.Url))
+		return
+	}
+
+	link.ScreenshotPath = path
+
+}
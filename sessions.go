@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/franela/goreq"
+)
+
+// define a custom structure for the login to a portal protecting some of the link
+// targets, the steps are requested in order and the session cookies they set are
+// used for all subsequent checks
+type LoginConfig struct {
+	Host  string      `json:"host"`
+	Steps []LoginStep `json:"steps"`
+
+	once sync.Once
+}
+
+// define a custom structure for a single request of a login, the values of the
+// fields may reference environment variables (i.e. $PORTAL_PASSWORD), so the
+// credentials do not have to be stored in the configuration
+type LoginStep struct {
+	Url    string            `json:"url"`
+	Method string            `json:"method"`
+	Fields map[string]string `json:"fields"`
+}
+
+// the cookies of all requests are shared if any logins are configured
+var cookieJar http.CookieJar
+
+// check the login given in the configuration
+func (login *LoginConfig) prepare() error {
+
+	login.Host = strings.ToLower(login.Host)
+
+	if login.Host == "" || len(login.Steps) == 0 {
+		return errors.New("logins need a host and at least one step")
+	}
+
+	for index := range login.Steps {
+		if login.Steps[index].Method == "" {
+			login.Steps[index].Method = "POST"
+		}
+	}
+
+	return nil
+
+}
+
+// initialize the cookie jar shared by all requests (if any logins are configured)
+func initializeSessions() {
+
+	if len(config.Logins) == 0 {
+		return
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		log.Println("ERROR: could not create the cookie jar")
+		return
+	}
+
+	cookieJar = jar
+
+}
+
+// log in to the portal of the host of the given url (only once per run and
+// before the first request to the host)
+func ensureSession(rawUrl string) {
+
+	if login := findLogin(rawUrl); login != nil {
+		login.once.Do(login.run)
+	}
+
+}
+
+// find the login of the host of the given url (nil if there is none)
+func findLogin(rawUrl string) *LoginConfig {
+
+	host := urlDomain(rawUrl)
+
+	for index := range config.Logins {
+
+		login := &config.Logins[index]
+
+		if host == login.Host || strings.HasSuffix(host, "."+login.Host) {
+			return login
+		}
+
+	}
+
+	return nil
+
+}
+
+// request all steps of the login
+func (login *LoginConfig) run() {
+
+	for _, step := range login.Steps {
+
+		values := url.Values{}
+
+		for name, value := range step.Fields {
+			values.Set(name, os.ExpandEnv(value))
+		}
+
+		request := goreq.Request{
+			Method:    strings.ToUpper(step.Method),
+			Uri:       step.Url,
+			CookieJar: cookieJar,
+			Timeout:   15000 * time.Millisecond,
+		}
+
+		if request.Method == "GET" {
+			request.QueryString = values
+		} else {
+			request.Body = values.Encode()
+			request.ContentType = "application/x-www-form-urlencoded"
+		}
+
+		response, err := request.Do()
+		if err != nil {
+			log.Println("ERROR: could not log in to " + login.Host)
+			return
+		}
+
+		response.Body.Close()
+
+		if response.StatusCode >= 400 {
+			log.Println("ERROR: login to " + login.Host + " failed")
+			return
+		}
+
+	}
+
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"log"
+)
+
+// define the severities that can fail a run
+const (
+	severityNone    = "none"
+	severityBroken  = "broken"
+	severityWarning = "warning"
+)
+
+// check the severity given on the command line
+func validateSeverity(severity string) error {
+
+	switch severity {
+	case severityNone, severityBroken, severityWarning:
+		return nil
+	}
+
+	return errors.New("unknown severity " + severity + " (use none, broken or warning)")
+
+}
+
+// count the issues of the reports with at least the given severity
+func countIssues(reports []Report, severity string) int {
+
+	issues := 0
+
+	for _, report := range reports {
+		for _, document := range report.Documents {
+
+			// the policy of the directory of the document takes precedence
+			severity := severity
+			if document.Severity != "" {
+				severity = document.Severity
+			}
+
+			if severity == severityNone {
+				continue
+			}
+
+			// documents that could not be opened cannot be considered valid
+			if document.IsLocked() {
+				issues++
+			}
+
+			if severity == severityWarning {
+				issues += len(document.QuotaWarnings)
+			}
+
+			for _, link := range document.Hyperlinks {
+
+				if link.NotChecked {
+					continue
+				}
+
+				if link.IsWorking == false {
+					issues++
+					continue
+				}
+
+				// warnings only count if the severity includes them
+				if severity == severityWarning && (len(link.Warnings) > 0 || link.IsRetracted) {
+					issues++
+				}
+
+			}
+
+		}
+	}
+
+	return issues
+
+}
+
+// get the exit status of the run according to the failure severity and threshold
+// of the options (the run fails if there are more issues than the threshold, or
+// with status 2 if the network was unavailable)
+func exitStatus(reports []Report) int {
+
+	// a run without network says nothing about the links (whatever the severity)
+	if network.unavailable() {
+		log.Println("Failed! (the network was unavailable, the external links were not checked)")
+		return 2
+	}
+
+	// the policies of single directories may fail the run even if the options
+	// never do
+	issues := countIssues(reports, options.FailOn)
+
+	if issues > options.FailThreshold {
+		log.Printf("Failed! (%d issues found, %d tolerated)\n", issues, options.FailThreshold)
+		return 1
+	}
+
+	return 0
+
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// define a custom structure for the part of the documents checked by this run
+// (i.e. shard 2 of 5), so large archives can be validated on several machines
+type Shard struct {
+	Index int
+	Count int
+}
+
+// parse the shard given on the command line (i.e. 2/5)
+func parseShard(value string) (Shard, error) {
+
+	parts := strings.Split(value, "/")
+
+	if len(parts) != 2 {
+		return Shard{}, errors.New("invalid shard " + value + " (use index/count, i.e. 2/5)")
+	}
+
+	index, indexErr := strconv.Atoi(parts[0])
+	count, countErr := strconv.Atoi(parts[1])
+
+	if indexErr != nil || countErr != nil || count < 1 || index < 1 || index > count {
+		return Shard{}, errors.New("invalid shard " + value + " (use index/count, i.e. 2/5)")
+	}
+
+	return Shard{Index: index, Count: count}, nil
+
+}
+
+// check if the document with the given path belongs to the shard. The documents
+// are assigned by the hash of their path relative to the root directory, so
+// every machine assigns them the same way
+func (shard Shard) contains(rootDirectory string, path string) bool {
+
+	if shard.Count <= 1 {
+		return true
+	}
+
+	relativePath, err := filepath.Rel(rootDirectory, path)
+	if err != nil {
+		relativePath = path
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(filepath.ToSlash(relativePath)))
+
+	return int(hash.Sum32()%uint32(shard.Count)) == shard.Index-1
+
+}
+
+// merge the json reports of several shards into a single report
+func runMerge(arguments []string) {
+
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	format := flags.String("format", "html", "format of the merged report (html or json)")
+	flags.IntVar(&options.TopBrokenDomains, "top-broken-domains", 10, "rank the given number of domains responsible for the most broken links in the report (0 to disable)")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links merge [-format html|json] [-top-broken-domains n] shard.json [shard.json ...]")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	shards := []Report{}
+
+	for _, path := range flags.Args() {
+
+		report, err := readReport(path)
+		if err != nil {
+			log.Fatalln("ERROR: could not read " + path)
+		}
+
+		shards = append(shards, report)
+
+	}
+
+	options.Format = *format
+
+	report := mergeReports(shards)
+
+	if report.create() == false {
+		os.Exit(1)
+	}
+
+	log.Printf("Merged %d reports into %s\n", len(shards), report.path())
+
+}
+
+// combine the results of the given reports into a single report
+func mergeReports(shards []Report) Report {
+
+	merged := Report{ResultOfValidation: true, Date: shards[0].Date, Metadata: shards[0].Metadata}
+
+	directories := map[string]bool{}
+	statistics := map[string]int{}
+	hosts := map[string]bool{}
+
+	for _, shard := range shards {
+
+		for _, directory := range shard.Directories {
+			if directories[directory] == false {
+				directories[directory] = true
+				merged.Directories = append(merged.Directories, directory)
+			}
+		}
+
+		// the statistics of the same directory are added up
+		for _, shardStatistics := range shard.Statistics {
+
+			index, exists := statistics[shardStatistics.Directory]
+
+			if exists == false {
+				statistics[shardStatistics.Directory] = len(merged.Statistics)
+				merged.Statistics = append(merged.Statistics, shardStatistics)
+				continue
+			}
+
+			merged.Statistics[index].Documents += shardStatistics.Documents
+			merged.Statistics[index].Links += shardStatistics.Links
+			merged.Statistics[index].Broken += shardStatistics.Broken
+
+		}
+
+		for _, expiry := range shard.HostExpiries {
+			if hosts[expiry.Host] == false {
+				hosts[expiry.Host] = true
+				merged.HostExpiries = append(merged.HostExpiries, expiry)
+			}
+		}
+
+		merged.Documents = append(merged.Documents, shard.Documents...)
+		merged.ExcludedDocuments = append(merged.ExcludedDocuments, shard.ExcludedDocuments...)
+		merged.ResultOfValidation = merged.ResultOfValidation && shard.ResultOfValidation
+		merged.IsTruncated = merged.IsTruncated || shard.IsTruncated
+		merged.NetworkUnavailable = merged.NetworkUnavailable || shard.NetworkUnavailable
+
+	}
+
+	// the summaries and the links between documents span all shards
+	merged.Domains = summarizeDomains(merged.Documents)
+	merged.BrokenDomains = rankBrokenDomains(merged.Domains)
+	merged.Owners = summarizeOwners(merged.Documents)
+	merged.Dependencies = checkLinkedDocuments(merged.Documents)
+	merged.Metadata.Configuration = append(merged.Metadata.Configuration, fmt.Sprintf("merged from %d shards", len(shards)))
+
+	return merged
+
+}
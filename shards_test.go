@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// check the parsing of the shards given on the command line
+func TestParseShard(t *testing.T) {
+
+	tests := []struct {
+		value   string
+		shard   Shard
+		isValid bool
+	}{
+		{"2/5", Shard{Index: 2, Count: 5}, true},
+		{"1/1", Shard{Index: 1, Count: 1}, true},
+		{"0/5", Shard{}, false},
+		{"6/5", Shard{}, false},
+		{"1/0", Shard{}, false},
+		{"2", Shard{}, false},
+		{"a/b", Shard{}, false},
+		{"1/2/3", Shard{}, false},
+	}
+
+	for _, test := range tests {
+
+		shard, err := parseShard(test.value)
+
+		if (err == nil) != test.isValid || shard != test.shard {
+			t.Errorf("parseShard(%s) = %+v, %v", test.value, shard, err)
+		}
+
+	}
+
+}
+
+// check that every document belongs to exactly one shard, independent of the
+// directory the documents were copied to
+func TestShardContains(t *testing.T) {
+
+	for document := 0; document < 50; document++ {
+
+		path := filepath.Join("sops", fmt.Sprintf("document-%d.docx", document))
+		shards := 0
+
+		for index := 1; index <= 3; index++ {
+
+			shard := Shard{Index: index, Count: 3}
+
+			if shard.contains("/first", filepath.Join("/first", path)) != shard.contains("/second", filepath.Join("/second", path)) {
+				t.Errorf("%s is assigned differently in another directory", path)
+			}
+
+			if shard.contains("/first", filepath.Join("/first", path)) {
+				shards++
+			}
+
+		}
+
+		if shards != 1 {
+			t.Errorf("%s belongs to %d shards", path, shards)
+		}
+
+	}
+
+	if (Shard{}).contains("/first", "/first/document.docx") == false {
+		t.Errorf("a run without shards does not check all documents")
+	}
+
+}
+
+// check that the reports of all shards merge into the report of a single run
+func TestMergeShards(t *testing.T) {
+
+	directory := writePipelineDocuments(t, 10, 3)
+
+	first := &countingChecker{calls: map[string]int{}, failing: "document-3/"}
+	usePipelineCheckers(t, first, nil)
+
+	shards := []Report{}
+
+	for index := 1; index <= 3; index++ {
+		options.Shard = Shard{Index: index, Count: 3}
+		shards = append(shards, validateDirectories([]string{directory}))
+	}
+
+	options.Shard = Shard{}
+	single := validateDirectories([]string{directory})
+
+	merged := mergeReports(shards)
+
+	documents := map[string]int{}
+	for _, document := range merged.Documents {
+		documents[document.Path]++
+	}
+
+	if len(merged.Documents) != 10 || len(documents) != 10 {
+		t.Errorf("the merged report has %d documents (%d distinct) instead of 10", len(merged.Documents), len(documents))
+	}
+
+	if len(merged.Directories) != 1 || len(merged.Statistics) != 1 {
+		t.Fatalf("the directories of the shards were not combined: %v %+v", merged.Directories, merged.Statistics)
+	}
+
+	if merged.Statistics[0] != single.Statistics[0] {
+		t.Errorf("merged statistics %+v, expected %+v", merged.Statistics[0], single.Statistics[0])
+	}
+
+	if merged.ResultOfValidation != single.ResultOfValidation || merged.ResultOfValidation {
+		t.Errorf("the broken links of a shard do not fail the merged report")
+	}
+
+	if len(merged.Domains) != len(single.Domains) || merged.Domains[0].Links != single.Domains[0].Links {
+		t.Errorf("merged domains %+v, expected %+v", merged.Domains, single.Domains)
+	}
+
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// the algorithm the results are signed with
+const signatureAlgorithm = "ed25519"
+
+// the private key the json results are signed with (nil if they are not signed)
+var signingKey ed25519.PrivateKey
+
+// define a custom structure for the detached signature of the json results,
+// which is written next to them and embedded in the html report
+type ReportSignature struct {
+	Algorithm   string
+	Fingerprint string
+	Signature   string
+	Results     string
+}
+
+// load the private key given on the command line (an ed25519 key in pkcs8 pem
+// format, i.e. created with openssl genpkey -algorithm ed25519)
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("could not read the signing key " + path)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the signing key " + path + " is not in pem format")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("could not parse the signing key " + path + ": " + err.Error())
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if ok == false {
+		return nil, errors.New("the signing key " + path + " is not an ed25519 key")
+	}
+
+	return privateKey, nil
+
+}
+
+// load the public key the signature is verified with, the private key can be
+// given too (the public key is derived from it)
+func loadVerificationKey(path string) (ed25519.PublicKey, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.New("could not read the key " + path)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the key " + path + " is not in pem format")
+	}
+
+	if block.Type == "PRIVATE KEY" {
+
+		privateKey, err := loadSigningKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return privateKey.Public().(ed25519.PublicKey), nil
+
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("could not parse the key " + path + ": " + err.Error())
+	}
+
+	publicKey, ok := key.(ed25519.PublicKey)
+	if ok == false {
+		return nil, errors.New("the key " + path + " is not an ed25519 key")
+	}
+
+	return publicKey, nil
+
+}
+
+// get the fingerprint of the given public key (the sha256 digest of its der
+// encoding, as shown in the report to identify the key)
+func keyFingerprint(publicKey ed25519.PublicKey) string {
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return ""
+	}
+
+	digest := sha256.Sum256(der)
+
+	return "SHA256:" + hex.EncodeToString(digest[:])
+
+}
+
+// get the path of the signature of the given json results
+func signaturePath(path string) string {
+	return path + ".sig"
+}
+
+// sign the given data of the json results and write the signature next to them
+func signResults(path string, data []byte) (*ReportSignature, error) {
+
+	digest := sha256.Sum256(data)
+
+	signature := &ReportSignature{
+		Algorithm:   signatureAlgorithm,
+		Fingerprint: keyFingerprint(signingKey.Public().(ed25519.PublicKey)),
+		Signature:   base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, data)),
+		Results:     "SHA256:" + hex.EncodeToString(digest[:]),
+	}
+
+	if writeJsonFile(signaturePath(path), signature) == false {
+		return nil, errors.New("could not write the signature of " + path)
+	}
+
+	return signature, nil
+
+}
+
+// verify the signature of the given data with the public key
+func verifyResults(data []byte, signature ReportSignature, publicKey ed25519.PublicKey) error {
+
+	if signature.Algorithm != signatureAlgorithm {
+		return errors.New("unsupported signature algorithm " + signature.Algorithm)
+	}
+
+	if signature.Fingerprint != keyFingerprint(publicKey) {
+		return errors.New("the results were signed with another key (" + signature.Fingerprint + ")")
+	}
+
+	value, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return errors.New("the signature is not valid base64")
+	}
+
+	if ed25519.Verify(publicKey, data, value) == false {
+		return errors.New("the signature does not match, the results were modified")
+	}
+
+	return nil
+
+}
+
+// verify that json results were signed with the given key and not modified since
+func runVerify(arguments []string) {
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyPath := flags.String("key", "", "public key (pem) the results were signed with")
+	signatureFile := flags.String("signature", "", "signature of the results (defaults to the results file with the extension .sig)")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links verify -key <public key> [-signature <file>] results.json")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	if *keyPath == "" || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	path := flags.Arg(0)
+
+	if *signatureFile == "" {
+		*signatureFile = signaturePath(path)
+	}
+
+	publicKey, err := loadVerificationKey(*keyPath)
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Println("ERROR: could not read the results", path)
+		os.Exit(2)
+	}
+
+	signatureData, err := ioutil.ReadFile(*signatureFile)
+	if err != nil {
+		fmt.Println("ERROR: could not read the signature", *signatureFile)
+		os.Exit(2)
+	}
+
+	var signature ReportSignature
+
+	err = json.Unmarshal(signatureData, &signature)
+	if err != nil {
+		fmt.Println("ERROR: invalid signature file", *signatureFile)
+		os.Exit(2)
+	}
+
+	err = verifyResults(data, signature, publicKey)
+	if err != nil {
+		fmt.Println("INVALID:", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("OK: %s was signed with the key %s\n", path, strings.TrimPrefix(signature.Fingerprint, "SHA256:"))
+
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// write the given private key in pkcs8 pem format (as created by openssl)
+func writeSigningKey(t *testing.T, privateKey ed25519.PrivateKey) string {
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "signing.pem")
+
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+
+}
+
+// check that signed results can be verified, but neither after a modification nor
+// with another key
+func TestSignResults(t *testing.T) {
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previousKey := signingKey
+	defer func() { signingKey = previousKey }()
+
+	signingKey, err = loadSigningKey(writeSigningKey(t, privateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	data := []byte(`{"ResultOfValidation": true}`)
+
+	signature, err := signResults(path, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signature written next to the results is the one returned
+	written, err := os.ReadFile(signaturePath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var writtenSignature ReportSignature
+
+	err = json.Unmarshal(written, &writtenSignature)
+	if err != nil || writtenSignature != *signature {
+		t.Fatalf("the signature file differs: %s", written)
+	}
+
+	err = verifyResults(data, *signature, publicKey)
+	if err != nil {
+		t.Errorf("the signed results could not be verified: %v", err)
+	}
+
+	// the public key can be derived from the private key as well
+	verificationKey, err := loadVerificationKey(writeSigningKey(t, privateKey))
+	if err != nil || verificationKey.Equal(publicKey) == false {
+		t.Errorf("the public key was not derived from the private key: %v", err)
+	}
+
+	modified := append([]byte{}, data...)
+	modified[len(modified)-2] = 'X'
+
+	if verifyResults(modified, *signature, publicKey) == nil {
+		t.Errorf("the modified results were verified")
+	}
+
+	if verifyResults(data, *signature, otherKey) == nil {
+		t.Errorf("the results were verified with another key")
+	}
+
+	// a signature claiming to be of the other key does not match either
+	forged := *signature
+	forged.Fingerprint = keyFingerprint(otherKey)
+
+	if verifyResults(data, forged, otherKey) == nil {
+		t.Errorf("the results were verified with another key and its fingerprint")
+	}
+
+}
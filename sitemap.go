@@ -0,0 +1,245 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/franela/goreq"
+)
+
+// sitemap indexes may list further sitemaps, which are read up to this depth
+const maxSitemapDepth = 3
+
+// define the warning for links to pages missing in the sitemap
+const warningNotInSitemap = "not in the sitemap (possibly a retired page showing a generic error page)"
+
+// the pages of the sitemap and the hosts they belong to (only the links to these
+// hosts are compared with the sitemap)
+var (
+	sitemapPages = map[string]bool{}
+	sitemapHosts = map[string]bool{}
+)
+
+// load the pages of the sitemap (a file or url, optionally gzip compressed,
+// and including the sitemaps of a sitemap index)
+func loadSitemap(location string) error {
+	return readSitemap(location, 0)
+}
+
+// read the sitemap at the given location and the sitemaps listed in it
+func readSitemap(location string, depth int) error {
+
+	if depth > maxSitemapDepth {
+		return errors.New("the sitemaps are nested too deeply at " + location)
+	}
+
+	reader, err := openSitemap(location)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	pages, sitemaps, err := decodeSitemap(reader)
+	if err != nil {
+		return errors.New("invalid sitemap " + location + ": " + err.Error())
+	}
+
+	for _, page := range pages {
+		sitemapPages[sitemapKey(page)] = true
+		sitemapHosts[sitemapHost(page)] = true
+	}
+
+	for _, sitemap := range sitemaps {
+
+		err = readSitemap(sitemap, depth+1)
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+// open the sitemap from the file system or our web server
+func openSitemap(location string) (io.ReadCloser, error) {
+
+	var reader io.ReadCloser
+
+	if isHttpScheme(linkScheme(location)) {
+
+		response, err := goreq.Request{
+			Uri:     location,
+			Timeout: 30000 * time.Millisecond,
+		}.Do()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode >= 400 {
+			response.Body.Close()
+			return nil, errors.New("the sitemap " + location + " answered with status " + strconv.Itoa(response.StatusCode))
+		}
+
+		reader = response.Body
+
+	} else {
+
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = file
+
+	}
+
+	if strings.HasSuffix(strings.ToLower(location), ".gz") == false {
+		return reader, nil
+	}
+
+	decompressed, err := gzip.NewReader(reader)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return &gzipSitemap{Reader: decompressed, file: reader}, nil
+
+}
+
+// define a custom structure closing both the decompression and the file of a
+// compressed sitemap
+type gzipSitemap struct {
+	*gzip.Reader
+	file io.Closer
+}
+
+func (sitemap *gzipSitemap) Close() error {
+	sitemap.Reader.Close()
+	return sitemap.file.Close()
+}
+
+// decode the locations of the pages (<url><loc>) and of the sitemaps listed in
+// a sitemap index (<sitemap><loc>)
+func decodeSitemap(reader io.Reader) ([]string, []string, error) {
+
+	pages := []string{}
+	sitemaps := []string{}
+
+	// the element containing the location currently read
+	parent := ""
+	isLocation := false
+	var location strings.Builder
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return pages, sitemaps, nil
+		}
+		if err != nil {
+			return pages, sitemaps, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+
+			switch element.Name.Local {
+			case "url", "sitemap":
+				parent = element.Name.Local
+			case "loc":
+				isLocation = true
+				location.Reset()
+			}
+
+		case xml.CharData:
+			if isLocation {
+				location.Write(element)
+			}
+
+		case xml.EndElement:
+
+			if element.Name.Local != "loc" {
+				continue
+			}
+
+			isLocation = false
+			target := strings.TrimSpace(location.String())
+
+			switch {
+			case target == "":
+			case parent == "sitemap":
+				sitemaps = append(sitemaps, target)
+			default:
+				pages = append(pages, target)
+			}
+
+		}
+
+	}
+
+}
+
+// get the key of the page in the sitemap, http and https as well as trailing
+// slashes are not distinguished
+func sitemapKey(page string) string {
+
+	address, err := url.Parse(normalizeUrl(encodeUrl(page)))
+	if err != nil {
+		return page
+	}
+
+	key := address.Host + strings.TrimSuffix(address.EscapedPath(), "/")
+
+	if address.RawQuery != "" {
+		key += "?" + address.RawQuery
+	}
+
+	return key
+
+}
+
+// get the host of the page (with its port, if it is not the default port)
+func sitemapHost(page string) string {
+
+	address, err := url.Parse(normalizeUrl(page))
+	if err != nil {
+		return ""
+	}
+
+	return address.Host
+
+}
+
+// warn about links to hosts of the sitemap whose page is not part of it (the
+// page the link redirects to may be part of it as well)
+func (link *Hyperlink) checkSitemap(response *CheckResponse) {
+
+	if sitemapHosts[sitemapHost(link.RequestUrl)] == false {
+		return
+	}
+
+	if sitemapPages[sitemapKey(link.RequestUrl)] {
+		return
+	}
+
+	if response != nil && response.FinalUrl != "" && sitemapPages[sitemapKey(response.FinalUrl)] {
+		return
+	}
+
+	link.Warnings = append(link.Warnings, warningNotInSitemap)
+
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"archive/zip"
+	"flag"
+	"fmt"
+	"html"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// the number of hyperlinks on each slide of a generated presentation
+const linksPerSlide = 20
+
+// define a custom structure with the settings of a generated corpus
+type corpusSettings struct {
+	directory string
+	documents int
+	links     int
+	types     []string
+	local     float64
+	broken    float64
+	baseUrl   string
+	random    *rand.Rand
+}
+
+// generate a corpus of synthetic documents with hyperlinks, so performance work
+// has reproducible workloads (i.e. together with -cpuprofile)
+func runGenerateTestdata(arguments []string) {
+
+	flags := flag.NewFlagSet("gen-testdata", flag.ExitOnError)
+	directory := flags.String("output", "testdata", "directory the documents are written to")
+	documents := flags.Int("documents", 100, "number of documents to generate")
+	links := flags.Int("links", 50, "number of hyperlinks per document")
+	types := flags.String("types", "docx,pptx", "types of the documents to generate (alternating)")
+	local := flags.Float64("local", 0.1, "fraction of the hyperlinks linking to other generated documents")
+	broken := flags.Float64("broken", 0.05, "fraction of the hyperlinks linking to documents that do not exist")
+	baseUrl := flags.String("base-url", "http://127.0.0.1:8080/", "url the external hyperlinks point to (i.e. a local test server)")
+	seed := flags.Int64("seed", 1, "seed of the random generator, the same seed generates the same corpus")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links gen-testdata [options]")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	settings := corpusSettings{
+		directory: *directory,
+		documents: *documents,
+		links:     *links,
+		types:     strings.Split(*types, ","),
+		local:     *local,
+		broken:    *broken,
+		baseUrl:   strings.TrimSuffix(*baseUrl, "/") + "/",
+		random:    rand.New(rand.NewSource(*seed)),
+	}
+
+	for _, documentType := range settings.types {
+		if documentType != "docx" && documentType != "pptx" {
+			log.Fatalln("ERROR: documents of type " + documentType + " cannot be generated (use docx or pptx)")
+		}
+	}
+
+	err := settings.generate()
+	if err != nil {
+		log.Fatalln("ERROR: could not generate the documents:", err)
+	}
+
+	fmt.Printf("Generated %d documents with %d hyperlinks each in %s\n", settings.documents, settings.links, settings.directory)
+
+}
+
+// write all documents of the corpus to its directory
+func (settings *corpusSettings) generate() error {
+
+	err := os.MkdirAll(settings.directory, 0755)
+	if err != nil {
+		return err
+	}
+
+	for index := 0; index < settings.documents; index++ {
+
+		path := filepath.Join(settings.directory, settings.documentName(index))
+		targets := settings.generateTargets()
+
+		if strings.HasSuffix(path, ".pptx") {
+			err = writePresentation(path, targets)
+		} else {
+			err = writeWordDocument(path, targets)
+		}
+
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+// get the file name of the generated document with the given index
+func (settings *corpusSettings) documentName(index int) string {
+	return fmt.Sprintf("document-%05d.%s", index, settings.types[index%len(settings.types)])
+}
+
+// get the targets of the hyperlinks of a document
+func (settings *corpusSettings) generateTargets() []string {
+
+	targets := make([]string, settings.links)
+
+	for index := range targets {
+
+		chance := settings.random.Float64()
+
+		switch {
+		case chance < settings.broken:
+			targets[index] = fmt.Sprintf("missing-%05d.docx", settings.random.Intn(100000))
+		case chance < settings.broken+settings.local:
+			targets[index] = settings.documentName(settings.random.Intn(settings.documents))
+		default:
+			targets[index] = fmt.Sprintf("%spage/%d", settings.baseUrl, settings.random.Intn(10*settings.documents*settings.links+1))
+		}
+
+	}
+
+	return targets
+
+}
+
+// write the given parts (by name) to a new office package
+func writePackage(path string, parts map[string]string) error {
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	// the parts are always written in the same order, so the same seed
+	// generates identical files
+	names := []string{}
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		content := parts[name]
+
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+
+		_, err = writer.Write([]byte(content))
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return archive.Close()
+
+}
+
+// get the relationships of the given hyperlink targets
+func hyperlinkRelationships(targets []string) string {
+
+	relationships := strings.Builder{}
+	relationships.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for index, target := range targets {
+		fmt.Fprintf(&relationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="%s" TargetMode="External"/>`, index+1, html.EscapeString(target))
+	}
+
+	relationships.WriteString(`</Relationships>`)
+
+	return relationships.String()
+
+}
+
+// write a word document with a paragraph for each hyperlink
+func writeWordDocument(path string, targets []string) error {
+
+	body := strings.Builder{}
+
+	for index := range targets {
+		fmt.Fprintf(&body, `<w:p><w:hyperlink r:id="rId%d"><w:r><w:t>Link %d</w:t></w:r></w:hyperlink></w:p>`, index+1, index+1)
+	}
+
+	return writePackage(path, map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/></Types>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/></Relationships>`,
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>` + body.String() + `</w:body></w:document>`,
+		"word/_rels/document.xml.rels": hyperlinkRelationships(targets),
+	})
+
+}
+
+// write a presentation with a slide for every few hyperlinks
+func writePresentation(path string, targets []string) error {
+
+	parts := map[string]string{}
+	overrides := strings.Builder{}
+	slides := strings.Builder{}
+	slideRelationships := strings.Builder{}
+
+	for slide := 0; slide*linksPerSlide < len(targets) || slide == 0; slide++ {
+
+		end := (slide + 1) * linksPerSlide
+		if end > len(targets) {
+			end = len(targets)
+		}
+
+		slideTargets := targets[slide*linksPerSlide : end]
+		shapes := strings.Builder{}
+
+		for index := range slideTargets {
+			fmt.Fprintf(&shapes, `<p:sp><p:nvSpPr><p:cNvPr id="%d" name="Link %d"/><p:cNvSpPr/><p:nvPr/></p:nvSpPr><p:spPr/><p:txBody><a:bodyPr/><a:p><a:r>`+
+				`<a:rPr lang="en-US"><a:hlinkClick r:id="rId%d"/></a:rPr><a:t>Link %d</a:t></a:r></a:p></p:txBody></p:sp>`, index+2, index+1, index+1, index+1)
+		}
+
+		name := fmt.Sprintf("ppt/slides/slide%d.xml", slide+1)
+
+		parts[name] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">` +
+			`<p:cSld><p:spTree><p:nvGrpSpPr><p:cNvPr id="1" name=""/><p:cNvGrpSpPr/><p:nvPr/></p:nvGrpSpPr><p:grpSpPr/>` + shapes.String() + `</p:spTree></p:cSld></p:sld>`
+		parts[fmt.Sprintf("ppt/slides/_rels/slide%d.xml.rels", slide+1)] = hyperlinkRelationships(slideTargets)
+
+		fmt.Fprintf(&overrides, `<Override PartName="/%s" ContentType="application/vnd.openxmlformats-officedocument.presentationml.slide+xml"/>`, name)
+		fmt.Fprintf(&slides, `<p:sldId id="%d" r:id="rId%d"/>`, 256+slide, slide+1)
+		fmt.Fprintf(&slideRelationships, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide%d.xml"/>`, slide+1, slide+1)
+
+	}
+
+	parts["[Content_Types].xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/ppt/presentation.xml" ContentType="application/vnd.openxmlformats-officedocument.presentationml.presentation.main+xml"/>` + overrides.String() + `</Types>`
+	parts["_rels/.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="ppt/presentation.xml"/></Relationships>`
+	parts["ppt/presentation.xml"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><p:presentation xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:sldIdLst>` + slides.String() + `</p:sldIdLst></p:presentation>`
+	parts["ppt/_rels/presentation.xml.rels"] = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		slideRelationships.String() + `</Relationships>`
+
+	return writePackage(path, parts)
+
+}
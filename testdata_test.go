@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// generate a corpus with the same settings as gen-testdata (with its default
+// seed), the external hyperlinks point to the fake server
+func generateBenchmarkCorpus(b *testing.B, documents int, links int) string {
+
+	settings := corpusSettings{
+		directory: b.TempDir(),
+		documents: documents,
+		links:     links,
+		types:     []string{"docx", "pptx"},
+		local:     0.1,
+		broken:    0.05,
+		baseUrl:   "http://" + fakeHost + "/",
+		random:    rand.New(rand.NewSource(1)),
+	}
+
+	err := settings.generate()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return settings.directory
+
+}
+
+// measure the extraction of the hyperlinks of the generated documents
+func BenchmarkExtraction(b *testing.B) {
+
+	directory := generateBenchmarkCorpus(b, 20, 200)
+
+	files := make(chan Document)
+	go walkDirectory(directory, files)
+
+	documents := []Document{}
+	for document := range files {
+		documents = append(documents, document)
+	}
+
+	b.ResetTimer()
+
+	for iteration := 0; iteration < b.N; iteration++ {
+		for index := range documents {
+			document := documents[index]
+			extractHyperlinksFromDocument(&document)
+		}
+	}
+
+}
+
+// measure the whole pipeline (extraction, checks against the fake server and
+// aggregation) for the generated documents
+func BenchmarkPipeline(b *testing.B) {
+
+	directory := generateBenchmarkCorpus(b, 20, 50)
+
+	b.ResetTimer()
+
+	for iteration := 0; iteration < b.N; iteration++ {
+		getAndCheckFilesInDirectory(directory)
+	}
+
+}
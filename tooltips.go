@@ -0,0 +1,179 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"log"
+	"path"
+	"strings"
+)
+
+// links whose tooltip contains this annotation are not validated (like the inline
+// suppressions of code linters)
+const ignoreAnnotation = "link-check:ignore"
+
+// check if the author asked to skip the validation of the link
+func (link *Hyperlink) isIgnored() bool {
+	return strings.Contains(strings.ToLower(link.Tooltip), ignoreAnnotation)
+}
+
+// define a custom structure for the information stored with the element
+// referencing a relationship (which is not part of the relationship itself)
+type LinkReference struct {
+	Tooltip string
+	Text    string
+}
+
+// get the name of the part the given .rels file belongs to, i.e. the relationships
+// of word/document.xml are stored in word/_rels/document.xml.rels
+func sourcePartName(relationshipFile string) string {
+
+	directory := path.Dir(path.Dir(relationshipFile))
+	name := strings.TrimSuffix(path.Base(relationshipFile), ".rels")
+
+	if directory == "." {
+		return name
+	}
+
+	return directory + "/" + name
+
+}
+
+// read the tooltips and display texts of all elements referencing a relationship
+// in the given part (returned by the id of the relationship) and whether the part
+// could be read completely
+func readLinkReferences(file *zip.File) (map[string]LinkReference, bool) {
+
+	// open the file for reading
+	fileContentReader, err := openPart(file)
+	if err != nil {
+		log.Println("ERROR: could not read the link references of " + file.Name)
+		return map[string]LinkReference{}, false
+	}
+	defer fileContentReader.Close()
+
+	references, err := decodeLinkReferences(fileContentReader)
+	if err != nil {
+		log.Println("ERROR: could not read the link references of " + file.Name)
+		return references, false
+	}
+
+	return references, true
+
+}
+
+// decode the tooltips and texts of all hyperlink elements (every relationship
+// referenced by any element of the part is contained in the result), i.e. <w:hyperlink
+// r:id="rId5" w:tooltip="..."> in word, <a:hlinkClick r:id="rId2" tooltip="...">
+// within the properties of a text run in powerpoint and <hyperlink r:id="rId1"
+// tooltip="..."> in excel (where the text is stored in the cell)
+func decodeLinkReferences(reader io.Reader) (map[string]LinkReference, error) {
+
+	references := make(map[string]LinkReference)
+
+	// the id of the relationship the text currently read belongs to and the
+	// element ending it (the hyperlink in word, the text run in powerpoint)
+	currentId := ""
+	currentEnd := ""
+	var currentText strings.Builder
+
+	isText := false
+
+	decoder := newTolerantDecoder(reader)
+
+	for {
+
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return references, nil
+		}
+		if err != nil {
+			return references, err
+		}
+
+		switch element := token.(type) {
+
+		case xml.StartElement:
+
+			if element.Name.Local == "t" {
+				isText = true
+			}
+
+			// relationships are also referenced by other attributes (i.e. r:embed of images)
+			for _, attribute := range element.Attr {
+				if isRelationshipAttribute(attribute) {
+					references[attribute.Value] = references[attribute.Value]
+				}
+			}
+
+			id := relationshipIdAttribute(element)
+			if id == "" {
+				continue
+			}
+
+			reference := references[id]
+			if tooltip := attributeValue(element, "tooltip"); tooltip != "" {
+				reference.Tooltip = tooltip
+			}
+			references[id] = reference
+
+			switch element.Name.Local {
+			case "hyperlink":
+				currentId, currentEnd = id, "hyperlink"
+				currentText.Reset()
+			case "hlinkClick":
+				currentId, currentEnd = id, "r"
+				currentText.Reset()
+			}
+
+		case xml.EndElement:
+
+			if element.Name.Local == "t" {
+				isText = false
+			}
+
+			if currentId != "" && element.Name.Local == currentEnd {
+
+				// the same relationship may be referenced from several places
+				reference := references[currentId]
+				if reference.Text == "" {
+					reference.Text = strings.TrimSpace(currentText.String())
+				}
+				references[currentId] = reference
+
+				currentId = ""
+
+			}
+
+		case xml.CharData:
+
+			if isText && currentId != "" && currentText.Len() < maxTextLength {
+				currentText.Write(element)
+			}
+
+		}
+
+	}
+
+}
+
+// get the id of the relationship referenced by the element (the only id attribute
+// in the relationships namespace)
+func relationshipIdAttribute(element xml.StartElement) string {
+
+	for _, attribute := range element.Attr {
+		if attribute.Name.Local == "id" && isRelationshipAttribute(attribute) {
+			return attribute.Value
+		}
+	}
+
+	return ""
+
+}
+
+// check if the attribute belongs to the relationships namespace (of transitional
+// or strict documents)
+func isRelationshipAttribute(attribute xml.Attr) bool {
+	return strings.HasSuffix(attribute.Name.Space, "/relationships")
+}
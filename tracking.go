@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// the query parameters added by marketing and analytics tools, which do not
+// change the page a link points to
+var trackingParameterMatcher = regexp.MustCompile(`^(utm_[a-z_]+|fbclid|gclid|gclsrc|dclid|msclkid|yclid|twclid|igshid|mc_cid|mc_eid|_hsenc|_hsmi|mkt_tok|_ga|_gl)$`)
+
+// the additional tracking parameters given in the configuration
+var customTrackingMatchers []*regexp.Regexp
+
+// compile the additional tracking parameters given in the configuration
+func prepareTrackingParameters(patterns []string) error {
+
+	customTrackingMatchers = nil
+
+	for _, pattern := range patterns {
+
+		matcher, err := regexp.Compile(pattern)
+		if err != nil {
+			return errors.New("invalid tracking parameter " + pattern)
+		}
+
+		customTrackingMatchers = append(customTrackingMatchers, matcher)
+
+	}
+
+	return nil
+
+}
+
+// check if the given query parameter is only used for tracking
+func isTrackingParameter(name string) bool {
+
+	if trackingParameterMatcher.MatchString(strings.ToLower(name)) {
+		return true
+	}
+
+	for _, matcher := range customTrackingMatchers {
+		if matcher.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+
+}
+
+// remove the tracking parameters from the given url, the order and the encoding
+// of the other parameters are kept (returns false if there were none)
+func stripTrackingParameters(link string) (string, bool) {
+
+	if strings.Contains(link, "?") == false {
+		return link, false
+	}
+
+	address, err := url.Parse(link)
+	if err != nil || address.RawQuery == "" {
+		return link, false
+	}
+
+	parameters := []string{}
+	stripped := false
+
+	for _, parameter := range strings.Split(address.RawQuery, "&") {
+
+		name := strings.SplitN(parameter, "=", 2)[0]
+
+		decodedName, err := url.QueryUnescape(name)
+		if err != nil {
+			decodedName = name
+		}
+
+		if isTrackingParameter(decodedName) {
+			stripped = true
+			continue
+		}
+
+		parameters = append(parameters, parameter)
+
+	}
+
+	if stripped == false {
+		return link, false
+	}
+
+	address.RawQuery = strings.Join(parameters, "&")
+
+	// a link consisting of tracking parameters only has no query left at all
+	if address.RawQuery == "" {
+		address.ForceQuery = false
+	}
+
+	return address.String(), true
+
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// convert the url into a form that can be sent in a http request, i.e. convert
+// international domain names to punycode and percent-encode the path and query
+func encodeUrl(rawUrl string) string {
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		// we leave urls we cannot parse as they are
+		return rawUrl
+	}
+
+	// convert the host to its ascii representation
+	host := encodeHostname(parsedUrl.Hostname())
+
+	// ipv6 literals are only valid within brackets (with and without port)
+	if port := parsedUrl.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	parsedUrl.Host = host
+
+	// the path is encoded by the url package, the query however is left untouched
+	parsedUrl.RawQuery = escapeNonAscii(parsedUrl.RawQuery)
+
+	return parsedUrl.String()
+
+}
+
+// check if the target points to a local file and resolve it relative to the
+// directory of the document (returns the absolute path of the file)
+func resolveLocalTarget(target string, documentPath string) (string, bool) {
+
+	// anchors within the same document are not local files
+	if target == "" || strings.HasPrefix(target, "#") {
+		return "", false
+	}
+
+	var path string
+
+	parsedUrl, err := url.Parse(target)
+
+	switch {
+
+	case isWindowsDrivePath(target) || strings.HasPrefix(target, `\\`):
+		// absolute windows paths and unc paths
+		path = target
+
+	case err != nil:
+		// targets that are no valid urls are treated as plain file paths
+		path = target
+
+	case parsedUrl.Scheme == "file":
+		path = parsedUrl.Path
+
+		// file:///C:/... contains the drive letter after the leading slash
+		if isWindowsDrivePath(strings.TrimPrefix(path, "/")) {
+			path = strings.TrimPrefix(path, "/")
+		}
+
+		// file://server/share/... refers to a network share
+		if parsedUrl.Host != "" && parsedUrl.Host != "localhost" {
+			path = `\\` + parsedUrl.Host + filepath.FromSlash(path)
+		}
+
+	case parsedUrl.Scheme == "":
+		// relative paths are stored percent-encoded
+		path, err = url.PathUnescape(parsedUrl.Path)
+		if err != nil {
+			path = parsedUrl.Path
+		}
+
+	default:
+		return "", false
+	}
+
+	if path == "" {
+		return "", false
+	}
+
+	path = filepath.FromSlash(path)
+
+	if filepath.IsAbs(path) == false && isWindowsDrivePath(path) == false && strings.HasPrefix(path, `\\`) == false {
+		path = filepath.Join(filepath.Dir(documentPath), path)
+	}
+
+	return getAbsoluteFilePath(path), true
+
+}
+
+// check if the given path starts with a windows drive letter (i.e. C:\ or C:/)
+func isWindowsDrivePath(path string) bool {
+
+	if len(path) < 3 || path[1] != ':' || (path[2] != '\\' && path[2] != '/') {
+		return false
+	}
+
+	letter := path[0] | 0x20
+	return letter >= 'a' && letter <= 'z'
+
+}
+
+// check if the given file exists
+func fileExists(path string) bool {
+	_, err := fileSystem.Stat(path)
+	return err == nil
+}
+
+// characters that are not allowed in urls but frequently found in document targets
+var unsafeUrlCharacters = map[rune]string{
+	' ':  "%20",
+	'"':  "%22",
+	'<':  "%3C",
+	'>':  "%3E",
+	'\\': "%5C",
+	'^':  "%5E",
+	'`':  "%60",
+	'{':  "%7B",
+	'|':  "%7C",
+	'}':  "%7D",
+}
+
+// try to repair malformed targets (surrounding whitespace, quotes or angle brackets
+// and unescaped characters), returning the repaired url and whether it was changed
+func repairUrl(rawUrl string) (string, bool) {
+
+	repaired := strings.TrimSpace(rawUrl)
+
+	// remove any quotes or angle brackets around the url
+	for len(repaired) >= 2 {
+
+		first := repaired[0]
+		last := repaired[len(repaired)-1]
+
+		if (first == '"' && last == '"') || (first == '\'' && last == '\'') || (first == '<' && last == '>') {
+			repaired = strings.TrimSpace(repaired[1 : len(repaired)-1])
+		} else {
+			break
+		}
+
+	}
+
+	// escape all characters that must not occur unescaped
+	var builder strings.Builder
+
+	for _, character := range repaired {
+
+		if escaped, isUnsafe := unsafeUrlCharacters[character]; isUnsafe {
+			builder.WriteString(escaped)
+		} else {
+			builder.WriteRune(character)
+		}
+
+	}
+
+	repaired = builder.String()
+
+	return repaired, repaired != rawUrl
+
+}
+
+// get the domain of the given url (empty for urls without host)
+func urlDomain(rawUrl string) string {
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(parsedUrl.Hostname())
+
+}
+
+// convert all labels of the hostname containing non-ascii characters to punycode
+func encodeHostname(hostname string) string {
+
+	labels := strings.Split(hostname, ".")
+
+	for index, label := range labels {
+
+		if isAscii(label) {
+			continue
+		}
+
+		labels[index] = "xn--" + punycodeEncode(strings.ToLower(label))
+
+	}
+
+	return strings.Join(labels, ".")
+
+}
+
+// check if the given string does only contain ascii characters
+func isAscii(text string) bool {
+
+	for index := 0; index < len(text); index++ {
+		if text[index] >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+
+}
+
+// percent-encode all non-ascii bytes of the given string
+func escapeNonAscii(text string) string {
+
+	if isAscii(text) {
+		return text
+	}
+
+	var builder strings.Builder
+
+	for index := 0; index < len(text); index++ {
+
+		if text[index] >= utf8.RuneSelf {
+			fmt.Fprintf(&builder, "%%%02X", text[index])
+		} else {
+			builder.WriteByte(text[index])
+		}
+
+	}
+
+	return builder.String()
+
+}
+
+// define the parameters of the punycode algorithm (see rfc 3492)
+const (
+	punycodeBase        = 36
+	punycodeTmin        = 1
+	punycodeTmax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+
+	// the largest value of the variables of the algorithm, larger values are
+	// overflows of malformed labels
+	punycodeMaxInt = math.MaxInt32
+)
+
+// encode the given label with the punycode algorithm (without the xn-- prefix)
+func punycodeEncode(label string) string {
+
+	codePoints := []rune(label)
+
+	// all basic code points are copied to the output as they are
+	output := []byte{}
+
+	for _, codePoint := range codePoints {
+		if codePoint < utf8.RuneSelf {
+			output = append(output, byte(codePoint))
+		}
+	}
+
+	basicCount := len(output)
+	handledCount := basicCount
+
+	if basicCount > 0 {
+		output = append(output, '-')
+	}
+
+	n := punycodeInitialN
+	delta := 0
+	bias := punycodeInitialBias
+
+	for handledCount < len(codePoints) {
+
+		// find the smallest code point not handled yet
+		next := int(utf8.MaxRune)
+
+		for _, codePoint := range codePoints {
+			if int(codePoint) >= n && int(codePoint) < next {
+				next = int(codePoint)
+			}
+		}
+
+		delta += (next - n) * (handledCount + 1)
+		n = next
+
+		for _, codePoint := range codePoints {
+
+			if int(codePoint) < n {
+				delta++
+			}
+
+			if int(codePoint) != n {
+				continue
+			}
+
+			// encode the delta as variable-length integer
+			q := delta
+
+			for k := punycodeBase; ; k += punycodeBase {
+
+				t := k - bias
+				if t < punycodeTmin {
+					t = punycodeTmin
+				} else if t > punycodeTmax {
+					t = punycodeTmax
+				}
+
+				if q < t {
+					break
+				}
+
+				output = append(output, punycodeDigit(t+(q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+
+			}
+
+			output = append(output, punycodeDigit(q))
+
+			bias = punycodeAdapt(delta, handledCount+1, handledCount == basicCount)
+			delta = 0
+			handledCount++
+
+		}
+
+		delta++
+		n++
+
+	}
+
+	return string(output)
+
+}
+
+// decode the given label with the punycode algorithm (without the xn-- prefix)
+func punycodeDecode(label string) (string, error) {
+
+	if label == "" {
+		return "", errors.New("empty punycode label")
+	}
+
+	output := []rune{}
+
+	// the basic code points are written before the last delimiter
+	if delimiter := strings.LastIndex(label, "-"); delimiter >= 0 {
+
+		if isAscii(label[:delimiter]) == false {
+			return "", errors.New("invalid punycode label")
+		}
+
+		output = []rune(label[:delimiter])
+		label = label[delimiter+1:]
+
+	}
+
+	n := punycodeInitialN
+	i := 0
+	bias := punycodeInitialBias
+
+	for position := 0; position < len(label); {
+
+		previous := i
+		weight := 1
+
+		for k := punycodeBase; ; k += punycodeBase {
+
+			if position >= len(label) {
+				return "", errors.New("invalid punycode label")
+			}
+
+			digit := punycodeValue(label[position])
+			position++
+
+			if digit < 0 {
+				return "", errors.New("invalid punycode label")
+			}
+
+			// malformed labels overflow the variables (see rfc 3492 section 6.2)
+			if digit > (punycodeMaxInt-i)/weight {
+				return "", errors.New("invalid punycode label (overflow)")
+			}
+
+			i += digit * weight
+
+			t := k - bias
+			if t < punycodeTmin {
+				t = punycodeTmin
+			} else if t > punycodeTmax {
+				t = punycodeTmax
+			}
+
+			if digit < t {
+				break
+			}
+
+			if weight > punycodeMaxInt/(punycodeBase-t) {
+				return "", errors.New("invalid punycode label (overflow)")
+			}
+
+			weight *= punycodeBase - t
+
+		}
+
+		bias = punycodeAdapt(i-previous, len(output)+1, previous == 0)
+
+		if i/(len(output)+1) > punycodeMaxInt-n {
+			return "", errors.New("invalid punycode label (overflow)")
+		}
+
+		n += i / (len(output) + 1)
+		i = i % (len(output) + 1)
+
+		if n > utf8.MaxRune || utf8.ValidRune(rune(n)) == false {
+			return "", errors.New("invalid punycode label")
+		}
+
+		if i < 0 || i > len(output) {
+			return "", errors.New("invalid punycode label")
+		}
+
+		// insert the decoded code point at its position
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = rune(n)
+		i++
+
+	}
+
+	return string(output), nil
+
+}
+
+// convert all punycode labels of the hostname back to unicode (labels that
+// cannot be decoded are kept as they are)
+func decodeHostname(hostname string) string {
+
+	labels := strings.Split(hostname, ".")
+
+	for index, label := range labels {
+
+		if strings.HasPrefix(strings.ToLower(label), "xn--") == false {
+			continue
+		}
+
+		if decoded, err := punycodeDecode(strings.ToLower(label[4:])); err == nil {
+			labels[index] = decoded
+		}
+
+	}
+
+	return strings.Join(labels, ".")
+
+}
+
+// adapt the bias of the punycode algorithm
+func punycodeAdapt(delta int, numberOfPoints int, isFirstTime bool) int {
+
+	if isFirstTime {
+		delta = delta / punycodeDamp
+	} else {
+		delta = delta / 2
+	}
+
+	delta += delta / numberOfPoints
+
+	k := 0
+
+	for delta > ((punycodeBase-punycodeTmin)*punycodeTmax)/2 {
+		delta = delta / (punycodeBase - punycodeTmin)
+		k += punycodeBase
+	}
+
+	return k + (punycodeBase-punycodeTmin+1)*delta/(delta+punycodeSkew)
+
+}
+
+// return the value of the given punycode digit (-1 if it is not a digit)
+func punycodeValue(character byte) int {
+
+	switch {
+	case character >= 'a' && character <= 'z':
+		return int(character - 'a')
+	case character >= 'A' && character <= 'Z':
+		return int(character - 'A')
+	case character >= '0' && character <= '9':
+		return int(character-'0') + 26
+	}
+
+	return -1
+
+}
+
+// return the character representing the given punycode digit
+func punycodeDigit(digit int) byte {
+
+	if digit < 26 {
+		return byte('a' + digit)
+	}
+
+	return byte('0' + digit - 26)
+
+}
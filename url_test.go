@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+)
+
+// check the decoding of valid and malformed punycode labels (see rfc 3492)
+func TestPunycodeDecode(t *testing.T) {
+
+	tests := []struct {
+		label    string
+		expected string
+		isValid  bool
+	}{
+		{"mnchen-3ya", "münchen", true},
+		{"bcher-kva", "bücher", true},
+		{"e1afmkfd", "пример", true},
+		{"egbpdaj6bu4bxfgehfvwxn", "ليهمابتكلموشعربي؟", true},
+		{"ihqwcrb4cv8a8dqg056pqjye", "他们为什么不说中文", true},
+		// digits overflowing the variables of the algorithm
+		{"a000000000000000000z", "", false},
+		{"99999999999999999999", "", false},
+		{"zzzzzzzzzzzzzzzzzzzzzzzzz", "", false},
+		{"", "", false},
+		// code points beyond the unicode range or surrogates
+		{"a-5555555", "", false},
+		{"7z1", "", false},
+		// labels ending within a code point or with invalid digits
+		{"mnchen-3y", "", false},
+		{"mnchen-3y!", "", false},
+		{"ü-3ya", "", false},
+	}
+
+	for _, test := range tests {
+
+		decoded, err := punycodeDecode(test.label)
+
+		if test.isValid && (err != nil || decoded != test.expected) {
+			t.Errorf("punycodeDecode(%q) = %q, %v, expected %q", test.label, decoded, err, test.expected)
+		}
+
+		if test.isValid == false && err == nil {
+			t.Errorf("punycodeDecode(%q) = %q, expected an error", test.label, decoded)
+		}
+
+	}
+
+}
+
+// check that encoded labels are decoded to the same label again
+func TestPunycodeRoundTrip(t *testing.T) {
+
+	for _, label := range []string{"münchen", "uѕb", "bücher", "ελληνικά", "日本語"} {
+
+		decoded, err := punycodeDecode(punycodeEncode(label))
+		if err != nil || decoded != label {
+			t.Errorf("punycodeDecode(punycodeEncode(%q)) = %q, %v", label, decoded, err)
+		}
+
+	}
+
+}
+
+// check that malformed labels of hostnames are kept as they are instead of
+// stopping the run
+func TestDecodeHostname(t *testing.T) {
+
+	tests := []struct {
+		hostname string
+		expected string
+	}{
+		{"www.xn--mnchen-3ya.de", "www.münchen.de"},
+		{"XN--MNCHEN-3YA.de", "münchen.de"},
+		{"xn--a000000000000000000z.com", "xn--a000000000000000000z.com"},
+		{"xn--99999999999999999999.xn--mnchen-3ya.de", "xn--99999999999999999999.münchen.de"},
+		{"xn--.com", "xn--.com"},
+		{"example.com", "example.com"},
+	}
+
+	for _, test := range tests {
+		if decoded := decodeHostname(test.hostname); decoded != test.expected {
+			t.Errorf("decodeHostname(%q) = %q, expected %q", test.hostname, decoded, test.expected)
+		}
+	}
+
+}
+
+// check that links to malformed international domain names do not stop the run
+func TestCheckHomoglyphsMalformedLabel(t *testing.T) {
+
+	link := Hyperlink{Url: "http://xn--a000000000000000000z.com/", RequestUrl: "http://xn--a000000000000000000z.com/"}
+	link.checkHomoglyphs()
+
+	if len(link.Warnings) > 0 {
+		t.Errorf("unexpected warnings for an undecodable label: %v", link.Warnings)
+	}
+
+}
+
+// check that the urls are encoded for the request without breaking their host
+func TestEncodeUrl(t *testing.T) {
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"http://[::1]:8080/a", "http://[::1]:8080/a"},
+		{"http://[::1]/a", "http://[::1]/a"},
+		{"https://[2001:db8::7]/wiki?q=1", "https://[2001:db8::7]/wiki?q=1"},
+		{"https://[2001:db8::7]:8443/", "https://[2001:db8::7]:8443/"},
+		{"http://127.0.0.1:8080/a", "http://127.0.0.1:8080/a"},
+		{"https://münchen.de:8443/straße", "https://xn--mnchen-3ya.de:8443/stra%C3%9Fe"},
+		{"https://example.com/?q=zürich", "https://example.com/?q=z%C3%BCrich"},
+	}
+
+	for _, test := range tests {
+		if encoded := encodeUrl(test.url); encoded != test.expected {
+			t.Errorf("encodeUrl(%q) = %q, expected %q", test.url, encoded, test.expected)
+		}
+	}
+
+}
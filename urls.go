@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// the format of the report listing the unique urls found in the documents
+const formatUrls = "urls"
+
+// define a custom structure for a unique url with the documents linking to it
+type UniqueUrl struct {
+	Url       string
+	Count     int
+	Documents []string
+}
+
+// normalize the url, so the different notations of the same target are listed
+// once (lowercase scheme and host, without default port and fragment)
+func normalizeUrl(link string) string {
+
+	address, err := url.Parse(link)
+	if err != nil || address.Scheme == "" {
+		return link
+	}
+
+	address.Scheme = strings.ToLower(address.Scheme)
+	address.Host = strings.ToLower(address.Host)
+	address.Fragment = ""
+	address.RawFragment = ""
+
+	if (address.Scheme == "http" && strings.HasSuffix(address.Host, ":80")) || (address.Scheme == "https" && strings.HasSuffix(address.Host, ":443")) {
+		address.Host = address.Host[:strings.LastIndex(address.Host, ":")]
+	}
+
+	if isHttpScheme(address.Scheme) && address.Path == "" {
+		address.Path = "/"
+	}
+
+	return address.String()
+
+}
+
+// collect the unique urls of all links of the report (links to local files and
+// internal targets are not listed)
+func (report *Report) uniqueUrls() []UniqueUrl {
+
+	urls := map[string]*UniqueUrl{}
+
+	for _, document := range report.Documents {
+		for _, link := range document.Hyperlinks {
+
+			// links checked by specialized validators (i.e. mailto) are not encoded
+			target := link.RequestUrl
+			if target == "" {
+				target = link.Url
+			}
+
+			if link.ResolvedPath != "" || linkScheme(target) == "file" {
+				continue
+			}
+
+			normalized := normalizeUrl(target)
+
+			unique, exists := urls[normalized]
+			if exists == false {
+				unique = &UniqueUrl{Url: normalized}
+				urls[normalized] = unique
+			}
+
+			unique.Count++
+
+			if len(unique.Documents) == 0 || unique.Documents[len(unique.Documents)-1] != document.Path {
+				unique.Documents = append(unique.Documents, document.Path)
+			}
+
+		}
+	}
+
+	list := []UniqueUrl{}
+	for _, unique := range urls {
+		list = append(list, *unique)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Url < list[j].Url
+	})
+
+	return list
+
+}
+
+// write the unique urls one per line, with the number of links and the
+// documents linking to them separated by tabs (if requested)
+func (report *Report) renderUrls(writer io.Writer) error {
+
+	for _, unique := range report.uniqueUrls() {
+
+		var err error
+
+		if options.UrlSources {
+			_, err = fmt.Fprintf(writer, "%s\t%d\t%s\n", unique.Url, unique.Count, strings.Join(unique.Documents, "\t"))
+		} else {
+			_, err = fmt.Fprintln(writer, unique.Url)
+		}
+
+		if err != nil {
+			return err
+		}
+
+	}
+
+	return nil
+
+}
+
+// write the unique urls of the report to the given file
+func (report *Report) writeUrls(path string) bool {
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Println("Could not create " + path)
+		return false
+	}
+	defer file.Close()
+
+	err = report.renderUrls(file)
+	if err != nil {
+		log.Println("Could not write " + path)
+		return false
+	}
+
+	return true
+
+}
@@ -5,23 +5,15 @@ import (
 	"io"
 	"os"
 
-	"sync"
-
 	"path/filepath"
 
-	"archive/zip"
-	"bytes"
-
 	"regexp"
 
-	"time"
+	"strings"
 
-	"github.com/franela/goreq"
+	"time"
 
-	"html/template"
 	"log"
-
-	"github.com/skratchdot/open-golang/open"
 )
 
 func main() {
@@ -29,587 +21,734 @@ func main() {
 	// measure execution time
 	start := time.Now()
 
-	fmt.Println("Checking documents. Please wait ..")
-
-	// initialize our regular expressions
-	initializeMatchers()
+	// run a subcommand instead of the validation if one is given
+	if len(os.Args) > 1 {
+		if command, exists := commands[os.Args[1]]; exists {
+			command(os.Args[2:])
+			return
+		}
+	}
 
-	// we are only interested in the current directory
-	directories := []string{"."}
+	// parse the command line options
+	parseOptions(os.Args[1:])
 
-	// get current date and time
-	currentTime := time.Now().String()
-	currentTime = currentTime[:19]
+	// profile the run to diagnose performance problems (if requested)
+	startProfiling()
+	defer stopProfiling()
 
-	// get a list of all files in the directories specified
-	documents := getAndCheckFilesInDirectory(".")
+	// initialize our regular expressions
+	initializeMatchers()
 
-	var resultOfValidation bool = true
+	// load the configuration file (if given)
+	if options.ConfigFile != "" {
+		err := loadConfig(options.ConfigFile)
+		if err != nil {
+			log.Fatalln("ERROR: could not load the configuration:", err)
+		}
+	}
 
-	for index, _ := range documents {
+	// initialize the specialized validators
+	initializeValidators()
 
-		// initialize document validity with true
-		documents[index].IsValid = true
+	// share the session cookies of portal logins between the requests
+	initializeSessions()
 
-		for _, link := range documents[index].Hyperlinks {
+	// initialize the chain of filters excluding hyperlinks from the validation
+	err := initializeFilters()
+	if err != nil {
+		log.Fatalln("ERROR: could not initialize the filters:", err)
+	}
 
-			if link.IsWorking == false {
-				documents[index].IsValid = false
-				resultOfValidation = false
-			}
+	// use the status of the urls reported by other systems (if given)
+	if options.StatusOverrides != "" {
+		statusOverrides, err = loadStatusOverrides(options.StatusOverrides)
+		if err != nil {
+			log.Fatalln("ERROR: could not load the status overrides:", err)
 		}
 	}
 
-	if resultOfValidation != false {
-		resultOfValidation = true
+	// compare the links to our intranet with its sitemap (if given)
+	if options.Sitemap != "" {
+		err = loadSitemap(options.Sitemap)
+		if err != nil {
+			log.Fatalln("ERROR: could not load the sitemap:", err)
+		}
 	}
 
-	// initialize our report structure
-	report := Report{
-		ResultOfValidation: resultOfValidation,
-		Directories:        directories,
-		Documents:          documents,
-		Date:               currentTime,
+	// make conditional requests for the links checked in previous runs (if requested)
+	if options.CacheFile != "" {
+		linkCache = loadLinkCache(options.CacheFile)
+		defer linkCache.save()
 	}
 
-	// create an html report with our data
-	report.create()
+	// initialize the machine-readable progress stream (if requested)
+	initializeProgress()
+	defer progress.close()
 
-	// open the report
-	report.open()
+	// serve the interactive web interface instead of a single run
+	if options.Gui {
+		serveGui()
+		return
+	}
 
-	// measure the time of computing
-	elapsed := time.Since(start)
+	// only print the hyperlinks without performing any network requests
+	if options.ListLinks {
+		listHyperlinks(configuredRoots(), os.Stdout)
+		return
+	}
 
-	// inform user that process is finished
-	log.Printf("Finished! (it took %s\n", elapsed)
+	fmt.Fprintln(console, "Checking documents. Please wait ..")
 
-}
+	roots := configuredRoots()
 
-// define a custom document structure
-type Document struct {
-	Path       string
-	Type       string
-	IsValid    bool
-	Hyperlinks []Hyperlink
-}
+	if options.Stdout && len(roots) > 1 {
+		log.Fatalln("ERROR: -stdout can only be used with a single directory")
+	}
 
-// define a custom hyperlink structure
-type Hyperlink struct {
-	Url       string
-	IsWorking bool
-}
+	// limit the requests and the duration of the run (if requested)
+	budget.start()
+	network.start()
 
-func (link *Hyperlink) validate(wg *sync.WaitGroup) {
+	// write the report of the links checked so far if the run is stopped
+	handleSignals()
 
-	// issue a GET request to the specified url and wait for response
-	// set a timeout of 10 seconds if there is no response
-	_, err := goreq.Request{
-		Uri:     link.Url,
-		Timeout: 15000 * time.Millisecond,
-	}.Do()
+	var reports []Report
 
-	if err != nil {
-		// link was not found
-		link.IsWorking = false
+	// several roots are validated in parallel and linked from an index report
+	if len(roots) > 1 {
+		reports = reportRoots(roots)
 	} else {
-		// link was found
-		link.IsWorking = true
+		reports = []Report{reportRoot(roots[0])}
 	}
 
-	wg.Done()
+	// export the dependencies of all documents (if requested)
+	if options.GraphFile != "" {
+		writeGraph(reports, options.GraphFile)
+	}
 
-}
+	// show the issues in the merge request widget of gitlab (if requested)
+	if options.CodeQualityFile != "" {
+		writeJsonFile(options.CodeQualityFile, codeQualityIssues(reports))
+	}
 
-// define some custom regular expressions
-var matchers map[string]*regexp.Regexp
+	// measure the time of computing
+	elapsed := time.Since(start)
 
-// initialize our regular expresssions
-func initializeMatchers() {
+	// inform user that process is finished
+	log.Printf("Finished! (it took %s\n", elapsed)
 
-	// initialize our map of matchers
-	matchers = make(map[string]*regexp.Regexp)
+	// fail the run according to the severity given (i.e. in continuous integration)
+	if status := exitStatus(reports); status != 0 {
+		progress.close()
+		linkCache.save()
+		stopProfiling()
+		os.Exit(status)
+	}
 
-	// add our matching expressions
-	matchers[".docx"] = regexp.MustCompile(`word/_rels/document.xml.rels`)
-	matchers[".pptx"] = regexp.MustCompile(`ppt/slides/_rels/.*.xml.rels`)
-	matchers["hyperlink"] = regexp.MustCompile(`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="(?P<url>.+?)"`)
-	matchers["microsoft"] = regexp.MustCompile(`http://office.microsoft.com`)
-	matchers["mailto"] = regexp.MustCompile(`mailto:.*`)
 }
 
-func getAndCheckFilesInDirectory(rootDirectory string) []Document {
-
-	// initialize a new slice of documents
-	documents := []Document{}
+// validate a single root and create its report
+func reportRoot(root RootConfig) Report {
 
-	var fileChannel chan Document = make(chan Document)
+	report := validateDirectories([]string{root.Path})
 
-	var wg sync.WaitGroup
-
-	// we have to wait until all file walking is done
-	wg.Add(1)
+	// remember the results of this run and show the trend of previous runs
+	if options.HistoryFile != "" {
+		report.updateHistory(options.HistoryFile, options.HistoryRuns)
+	}
 
-	// walk recursively through our directory in a separate thread
-	// send each matching file into our file channel
-	go walkDirectory(rootDirectory, fileChannel, &wg)
+	// create an html report with our data
+	report.create()
 
-	// for each file we find, we get all links and validate them
-	for file := range fileChannel {
+	// hand each owner a report with only their documents (if requested)
+	if options.OwnerReports {
+		report.createOwnerReports()
+	}
 
-		// remember to wait until the document is fully checked
-		var checkingHyperlinks sync.WaitGroup
+	// open the report
+	report.open()
 
-		checkingHyperlinks.Add(1)
+	return report
 
-		// check hyperlinks of the document
-		go extractAndCheckHyperlinks(&file, &checkingHyperlinks)
+}
 
-		// wait until all hyperlinks are checked
-		checkingHyperlinks.Wait()
+// validate several roots in parallel, create a report for each of them and an
+// index report linking them
+func reportRoots(roots []RootConfig) []Report {
 
-		documents = append(documents, file)
+	reports := validateRoots(roots)
 
-	}
+	for index := range reports {
 
-	// we are finished with finding and checking all elements
-	wg.Wait()
+		// the history file is shared, therefore it is only updated by one routine
+		if options.HistoryFile != "" {
+			reports[index].updateHistory(options.HistoryFile, options.HistoryRuns)
+		}
 
-	fmt.Println("we are done with these files")
+		reports[index].create()
 
-	return documents
+		if options.OwnerReports {
+			reports[index].createOwnerReports()
+		}
 
-}
+	}
 
-func walkDirectory(directory string, fileChannel chan Document, wg *sync.WaitGroup) {
+	index := newReportIndex(reports)
+	index.create()
 
-	// walk recursively through the directory
-	filepath.Walk(directory, func(path string, fileInfo os.FileInfo, err error) error {
+	// open the index report
+	openReport(index.path())
 
-		var fileName string = fileInfo.Name()
+	return reports
 
-		var extension string = filepath.Ext(fileName)
+}
 
-		if extension == ".docx" || extension == ".pptx" {
+// check all documents in the given directories and return the report
+func validateDirectories(directories []string) Report {
 
-			// create a pointer to new document with the corresponding type and path
-			file := Document{Path: path, Type: filepath.Ext(fileName)}
+	progress.runStarted()
 
-			// send the file to the channel
-			fileChannel <- file
+	// get current date and time
+	started := clock()
 
-		}
+	// make sure the links can be reached at all before checking them
+	connectivity := Connectivity{Before: checkCanaries()}
 
-		// we are not expecting any errors (or not handling them at least)
-		return nil
-
-	})
+	if connectivity.FailedBefore() > 0 && config.CanaryFailure == canaryFailureAbort {
+		log.Fatalln("ERROR: the canary urls could not be reached, the run is aborted")
+	}
 
-	// close our fileChannel (no longer needed)
-	close(fileChannel)
+	// get a list of all files in the directories specified
+	documents := []Document{}
+	excludedDocuments := []ExcludedDocument{}
+	statistics := []DirectoryStatistics{}
+
+	for _, directory := range directories {
+		directoryDocuments, directoryExcluded := getAndCheckFilesInDirectory(directory)
+		documents = append(documents, directoryDocuments...)
+		excludedDocuments = append(excludedDocuments, directoryExcluded...)
+		statistics = append(statistics, summarizeDirectory(directory, directoryDocuments))
+	}
 
-	// we are done walking the filepath
-	wg.Done()
+	// the links failing while the connectivity was bad cannot be trusted either
+	connectivity.After = checkCanaries()
 
-}
+	if connectivity.IsBad() && network.unavailable() == false {
+		markFailedRequests(documents, reasonConnectivityBad)
+	}
 
-func extractAndCheckHyperlinks(file *Document, wg *sync.WaitGroup) {
+	// the links failing before the network was found to be unavailable are not broken
+	if network.unavailable() {
+		markFailedRequests(documents, reasonNetworkUnavailable)
+	}
 
-	// get all hyperlinks from the document
-	file.Hyperlinks = extractHyperlinksFromDocument(*file)
+	// links between documents might require the linked document to be valid
+	dependencies := checkLinkedDocuments(documents)
 
-	// check all hyperlinks in a separate routine
-	for index, _ := range file.Hyperlinks {
+	// warn about hosts whose certificate or domain registration is about to expire
+	hostExpiries := []HostExpiry{}
+	if options.CheckHostExpiry {
+		hostExpiries = checkHostExpiries(documents)
+	}
 
-		wg.Add(1)
+	// sensitive query parameters are removed once everything is checked
+	redactDocuments(documents)
 
-		fmt.Println("-- checking link: " + file.Hyperlinks[index].Url)
+	var resultOfValidation bool = true
 
-		go file.Hyperlinks[index].validate(wg)
+	for _, document := range documents {
 
+		if document.IsValid == false {
+			resultOfValidation = false
+		}
 	}
 
-	wg.Done()
+	domains := summarizeDomains(documents)
 
-}
+	// initialize our report structure
+	report := Report{
+		ResultOfValidation: resultOfValidation,
+		Directories:        directories,
+		Documents:          documents,
+		Domains:            domains,
+		BrokenDomains:      rankBrokenDomains(domains),
+		Owners:             summarizeOwners(documents),
+		Dependencies:       dependencies,
+		HostExpiries:       hostExpiries,
+		Statistics:         statistics,
+		ExcludedDocuments:  excludedDocuments,
+		IsTruncated:        budget.exhausted(),
+		NetworkUnavailable: network.unavailable(),
+		Connectivity:       connectivity,
+		Date:               formatTimestamp(started),
+		Metadata:           newReportMetadata(started),
+	}
 
-func extractHyperlinksFromDocument(document Document) []Hyperlink {
+	// inform any listeners that we are done
+	progress.runFinished(&report)
 
-	// get the content of the file containing the links
-	content := getLinkFileContent(document)
+	return report
 
-	// find all hyperlinks in the document
-	matches := extractHyperlinksFromContent(content)
+}
 
-	// store the hyperlinks in a the document reference
-	return matches
+// define a custom document structure
+type Document struct {
+	Path               string
+	Type               string
+	Owner              string
+	Severity           string
+	IsTemplate         bool
+	Modified           time.Time
+	Protection         string
+	IsValid            bool
+	BrokenImages       int
+	BrokenObjects      int
+	RetractedCitations int
+	RepeatedLinks      []RepeatedLink
+	ConflictingLinks   []ConflictingLink
+	OrphanedLinks      []Hyperlink
+	IgnoredLinks       []IgnoredLink
+	LinkTextIssues     []LinkTextIssue
+	QuotaWarnings      []string
+	Copies             []string
+	Hyperlinks         []Hyperlink
+}
+
+// define the states of password protected documents
+const (
+	protectionDecrypted   = "decrypted"
+	protectionLocked      = "locked"
+	protectionUnsupported = "unsupported"
+)
 
+// check if the document is password protected and could not be opened
+func (document *Document) IsLocked() bool {
+	return document.Protection == protectionLocked || document.Protection == protectionUnsupported
 }
 
-func getLinkFileContent(document Document) string {
+// set the validity of the document according to its hyperlinks
+func (document *Document) updateValidity() {
 
-	// open the docx file with our zip module (as it is basically a container)
-	documentContainer, err := zip.OpenReader(document.Path)
-	if err != nil {
-		log.Println("ERROR: could not open the file")
-	}
-	defer documentContainer.Close()
+	// initialize document validity with true
+	document.IsValid = true
+	document.BrokenImages = 0
+	document.BrokenObjects = 0
+	document.RetractedCitations = 0
 
-	// initialize a new buffer to read the file contents
-	buffer := bytes.NewBuffer(nil)
+	for _, link := range document.Hyperlinks {
 
-	// go through all content files
-	for _, file := range documentContainer.File {
+		// links that were not checked are neither working nor broken
+		if link.NotChecked {
+			continue
+		}
 
-		// links are stored in a special file (but without the name of the link)
-		if matchers[document.Type].MatchString(file.Name) {
+		// citing retracted papers is a compliance problem even if the link works
+		if link.IsRetracted {
+			document.RetractedCitations++
+		}
 
-			// open the file for reading
-			fileContentReader, err := file.Open()
+		if link.IsWorking == false {
+			document.IsValid = false
 
-			if err != nil {
-				log.Println("ERROR: coult not read file content")
+			// linked figures will be shown as red cross when their source is gone
+			if link.Category == categoryImage {
+				document.BrokenImages++
 			}
-			defer fileContentReader.Close()
-
-			// copy content of the file to our content buffer
-			io.Copy(buffer, fileContentReader)
 
-			if err != nil {
-				log.Println("could not write file contents to console")
+			// linked objects will silently keep showing their last known data
+			if link.Category == categoryOleObject {
+				document.BrokenObjects++
 			}
-
 		}
 	}
 
-	// return content as string
-	return string(buffer.Bytes())
-
 }
 
-func extractHyperlinksFromContent(fileContent string) []Hyperlink {
+// define a custom hyperlink structure
+type Hyperlink struct {
+	Url              string
+	Category         string
+	IsExternal       bool
+	RequestUrl       string
+	ResolvedPath     string
+	IsWorking        bool
+	NotChecked       bool
+	HasUnknownScheme bool
+	EvidencePath     string
+	ScreenshotPath   string
+	IsRetracted      bool
+	Reason           string
+	Duration         time.Duration
+	Warnings         []string
+	ArchiveUrl       string
+	CleanUrl         string
+	Replacements     []string
+	IsOverridden     bool
+	IsBlocked        bool
+	Zones            []ZoneResult
+
+	// the tooltip (screen tip) often identifies the link, i.e. with citation info
+	Tooltip string
+	Text    string
+
+	// internal targets are checked against the parts of the document package
+	isPartPresent bool
+
+	// the request failed and can be repeated with the retry timeout
+	requestFailed bool
+	isSecondPass  bool
+}
+
+// define the warning categories of hyperlinks
+const (
+	warningMalformedUrl      = "malformed URL (auto-repaired)"
+	warningSecondaryLocation = "not accessible from the secondary location (possibly geo-blocked)"
+)
 
-	// find all matching links (the url of the hyperlink is matched by a capture group)
-	matches := matchers["hyperlink"].FindAllStringSubmatch(fileContent, -1)
+func (link *Hyperlink) validate(documentPath string) {
 
-	// initialize a new slice of strings of the same length as our matches
-	var links []Hyperlink = make([]Hyperlink, len(matches))
+	// links might still resolve but reference retired resources
+	link.checkExpiration()
 
-	// we are only interested in the second element in our list as
-	// the Submatch function returns the full match as first element and our capture group as second
-	for index, match := range matches {
-		links[index] = Hyperlink{Url: match[1], IsWorking: false}
+	// internal targets are parts of the document itself
+	if link.IsExternal == false {
+		link.IsWorking = link.isPartPresent
+		if link.Category == categoryAnchor && link.IsWorking == false {
+			link.Reason = reasonMissingAnchor
+		}
+		return
 	}
 
-	// no filter out all microsoft links
-	links = filterHyperlinks(links)
-
-	return links
+	target := link.Url
 
-}
+	// linked objects refer to a range or item within the source file
+	if link.Category == categoryOleObject {
+		target = stripOleItem(target)
+	}
 
-func filterHyperlinks(hyperlinks []Hyperlink) []Hyperlink {
+	// the schemes to validate can be restricted in the configuration
+	scheme := linkScheme(target)
 
-	// initialize an empty slice of strings
-	var filteredLinks = []Hyperlink{}
+	if isSchemeEnabled(scheme) == false {
+		link.NotChecked = true
+		link.Reason = reasonSchemeDisabled
+		return
+	}
 
-	// check all links
-	for _, link := range hyperlinks {
+	// links to local files are checked on the file system
+	if resolvedPath, isLocal := resolveLocalTarget(target, documentPath); isLocal {
+		link.ResolvedPath = resolvedPath
+		link.IsWorking = fileExists(resolvedPath)
+		return
+	}
 
-		// exclude any microsoft links
-		isMicrosoft := matchers["microsoft"].MatchString(link.Url)
-		isEmpty := (link.Url == "")
-		isMail := matchers["mailto"].MatchString(link.Url)
+	// word sometimes stores targets with spaces, quotes or brackets
+	requestUrl, wasRepaired := repairUrl(link.Url)
+	if wasRepaired {
+		link.Warnings = append(link.Warnings, warningMalformedUrl)
+	}
 
-		// include only non microsoft links
-		if isMicrosoft == false && isEmpty == false && isMail == false {
-			filteredLinks = append(filteredLinks, link)
+	// tracking parameters do not change the page, the clean url is checked and
+	// suggested as replacement (if requested)
+	if options.StripTracking {
+		if cleanUrl, wasStripped := stripTrackingParameters(requestUrl); wasStripped {
+			requestUrl = cleanUrl
+			link.CleanUrl = cleanUrl
 		}
+	}
 
+	// international domain names and special characters must be encoded
+	link.RequestUrl = encodeUrl(requestUrl)
+
+	// international domain names might impersonate other hosts (i.e. in phishing)
+	if options.CheckHomoglyphs {
+		link.checkHomoglyphs()
 	}
 
-	return filteredLinks
+	// the status of urls we cannot reach may be reported by other systems
+	if link.applyStatusOverride() {
+		return
+	}
 
-}
+	// a request to a link with an unknown scheme is bound to fail
+	if isKnownScheme(scheme) == false && findValidationCommand(link.Url) == nil {
+		link.NotChecked = true
+		link.HasUnknownScheme = true
+		link.Reason = reasonUnknownScheme + " " + scheme
+		return
+	}
 
-func getAbsoluteFilePath(path string) string {
+	// without network, the remaining links are not reported as broken
+	if network.unavailable() {
+		link.NotChecked = true
+		link.Reason = reasonNetworkUnavailable
+		return
+	}
 
-	// check if the path is already absolute
-	if filepath.IsAbs(path) {
-		// if so, return the path
-		return path
+	// once the budget of the run is exhausted, the remaining links are not checked
+	if budget.take() == false {
+		link.NotChecked = true
+		link.Reason = reasonNotChecked
+		return
 	}
 
-	// try to find the absolute path
-	absolutePath, err := filepath.Abs(path)
+	// spread the requests over time to avoid triggering rate limits (if requested)
+	waitForJitter()
 
-	// we return the original path, if there was an error
-	// otherwise we return the absolute path
-	if err != nil {
-		return path
-	} else {
-		return absolutePath
+	requestStart := time.Now()
+
+	// some links are checked with specialized validators (i.e. identifier resolvers)
+	if validator := findValidator(link); validator != nil {
+		validator.Validate(link)
+		link.Duration = time.Since(requestStart)
+		return
 	}
 
-}
+	// links to servers not speaking http cannot be checked with a request
+	if isHttpScheme(scheme) == false {
+		link.NotChecked = true
+		link.Reason = reasonSchemeUnsupported
+		return
+	}
 
-// define a custom report structre
-type Report struct {
-	ResultOfValidation bool
-	Directories        []string
-	Documents          []Document
-	InvalidHyperlinks  []Hyperlink
-	Date               string
-}
+	// the links failing in the first pass are checked again with a longer timeout
+	// (the policy of the directory may give its own timeout for the first pass)
+	activeChecker := checker
+	if policyChecker := documentPolicy(documentPath).checker; policyChecker != nil {
+		activeChecker = policyChecker
+	}
+	if link.isSecondPass {
+		activeChecker = retryChecker
+	}
 
-// create a custom html report
-func (report *Report) create() bool {
+	// some links are only reachable from other network zones
+	if worker := findRemoteWorker(link.RequestUrl); worker != nil {
+		activeChecker = worker
+	}
 
-	// open a new file to write our report to
-	file, _ := os.Create(reportName + ".html")
-	defer file.Close()
+	// issue a request to the specified url (from all of its network zones if
+	// it is tagged with them) and wait for response
+	var response *CheckResponse
+	var err error
 
-	functionMap := template.FuncMap{
-		"absolutePath": getAbsoluteFilePath,
+	if policy := findZonePolicy(link.RequestUrl); policy != nil {
+		response, err = link.checkZones(policy, zoneChecker)
+		activeChecker = &zonesChecker{policy: policy}
+	} else {
+		response, err = activeChecker.Check(link.RequestUrl)
 	}
 
-	// load our template from the templat file
-	tmpl, err := template.New("report").Funcs(functionMap).Parse(reportTemplate)
+	link.Duration = time.Since(requestStart)
+
+	network.record(err)
+
+	// bot protections answer with a challenge instead of the content, which
+	// tells nothing about the link (the status codes configured for a domain
+	// are blocked responses even if they would mean a missing page otherwise)
+	isBlocked, blockedResult := blockedResponse(link.RequestUrl, response)
 
 	if err != nil {
-		log.Println("Could not load template")
-		return false
+		// link was not found
+		link.IsWorking = false
 
+		// redirect loops are reported as such (and not as unreachable target)
+		if redirectErr, ok := err.(*RedirectError); ok {
+			link.Reason = redirectErr.Error()
+		} else if remoteErr, ok := err.(*RemoteError); ok {
+			link.Reason = remoteErr.Error()
+			link.requestFailed = true
+		} else {
+			link.requestFailed = true
+		}
+	} else if isBlocked {
+		link.applyBlockedResponse(blockedResult)
+	} else if isPageGone(response) {
+		// the server answered, but the page of the link does not exist (anymore)
+		link.IsWorking = false
+		link.Reason = fmt.Sprintf("%s (status %d)", reasonPageNotFound, response.StatusCode)
 	} else {
+		// link was found
+		link.IsWorking = true
+	}
 
-		// fill our template with content and write it to the file
-		err = tmpl.ExecuteTemplate(file, "report", report)
+	// some resources are blocked outside of certain regions
+	if link.IsWorking && options.SecondaryProxy != "" {
+		secondaryResponse, secondaryErr := secondaryChecker.Check(link.RequestUrl)
+		if issue := secondaryLocationIssue(response, secondaryResponse, secondaryErr); issue != "" {
+			link.Warnings = append(link.Warnings, warningSecondaryLocation+" ("+issue+")")
+		}
+	}
 
-		if err != nil {
-			log.Println("Could not fill the template with report data")
-			return false
+	// parked domains answer successfully but the linked content is gone
+	if link.IsWorking && options.DetectParking {
+		if isParked, explanation := isParkedDomain(link, response); isParked {
+			link.IsWorking = false
+			link.Reason = reasonParkedDomain + " (" + explanation + ")"
 		}
 	}
 
-	return true
+	// pages missing in the sitemap of our intranet are likely retired, even if
+	// a generic error page is shown with status 200 (if a sitemap is given)
+	if link.IsWorking && options.Sitemap != "" {
+		link.checkSitemap(response)
+	}
 
-}
+	// suggest pages replacing the page that is gone (if requested)
+	if options.SuggestReplacements && isBlocked == false && isPageGone(response) {
+		link.suggestReplacements(activeChecker)
+	}
+
+	// keep proof of what we saw for broken links (if requested)
+	if link.IsWorking == false && options.EvidenceDirectory != "" {
+		link.captureEvidence(documentPath, response, err)
+	}
 
-// open the report in the standard browser
-func (report *Report) open() {
+}
 
-	// open the report in the default browser
-	err := open.Start(reportName + ".html")
+// compare the response from the secondary location with the response of the
+// link, geo-blocks usually answer with an error status (i.e. 403 or 451) or
+// redirect to a page telling that the content is not available in the region
+func secondaryLocationIssue(primary *CheckResponse, secondary *CheckResponse, err error) string {
 
 	if err != nil {
-		log.Println("Could not open report")
+		return err.Error()
 	}
 
-}
+	if primary == nil || secondary == nil {
+		return ""
+	}
 
-// we define the name of our report
-var reportName string = "report"
+	if secondary.StatusCode >= 400 && primary.StatusCode < 400 {
+		return fmt.Sprintf("status %d instead of %d", secondary.StatusCode, primary.StatusCode)
+	}
 
-const reportTemplate = `<html>
-<head>
-<title>Check hyperlinks in docx and pptx files</title>
-<meta charset="utf-8">
-<meta name="author" content="Dr. med. Ramon Saccilotto, DKF, University Hospital Basel, Switzerland">
+	if primary.FinalUrl != "" && secondary.FinalUrl != "" && normalizeUrl(primary.FinalUrl) != normalizeUrl(secondary.FinalUrl) {
+		return "redirected to " + secondary.FinalUrl
+	}
 
-<style type="text/css">
+	return ""
 
-* {
-font-family: "Helvetica Neue", "Helvetica", "Calibri", "Arial", sans-serif;
-font-weight: normal;
-font-size: 14px;
 }
 
-a {
-text-decoration: none;
-color: inherit;
-font-size: inherit;
-}
+// define some custom regular expressions
+var matchers map[string]*regexp.Regexp
 
-body {
-background-color: #eaeaea;
-}
+// initialize our regular expresssions
+func initializeMatchers() {
 
-.container {
-min-width: 600px;
-margin: 40px 20px 20px 20px;
-padding: 20px;
-padding-bottom: 40px;
-border: 1px solid #ccc;
-background-color: #fff;
-box-shadow: 0px 1px 1px rgba(74, 69, 69, 0.6), 0px -1px 1px rgba(50, 50, 50, 0.05);
-}
+	// initialize our map of matchers
+	matchers = make(map[string]*regexp.Regexp)
 
-.info {
-margin: 20px;
+	// add our matching expressions
+	matchers[".docx"] = regexp.MustCompile(`word/_rels/document.xml.rels`)
+	matchers[".pptx"] = regexp.MustCompile(`ppt/slides/_rels/.*.xml.rels`)
+	matchers[".xlsx"] = regexp.MustCompile(`xl/worksheets/_rels/.*.xml.rels`)
+	matchers["worksheet"] = regexp.MustCompile(`^xl/worksheets/[^/]*\.xml$`)
+	matchers["hyperlinkFormula"] = regexp.MustCompile(`(?i)HYPERLINK\(\s*"((?:[^"]|"")*)"\s*[,)]`)
+	matchers["scheme"] = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]+):`)
+	matchers["pmid"] = regexp.MustCompile(`^/(?:pubmed/)?([0-9]+)/?$`)
+	matchers["nctId"] = regexp.MustCompile(`(?i)NCT[0-9]{8}`)
+	matchers["reportName"] = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+	matchers["microsoft"] = regexp.MustCompile(`http://office.microsoft.com`)
+	matchers["mailto"] = regexp.MustCompile(`mailto:.*`)
+	matchers["plainTextUrl"] = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+	matchers["cellReference"] = regexp.MustCompile(`^\$?[A-Za-z]{1,3}\$?[0-9]+(:\$?[A-Za-z]{1,3}\$?[0-9]+)?$`)
 }
 
-.info p {
-font-size: 12px;
-opacity: 0.2;
-}
+// print all hyperlinks found in the documents of the given roots without checking them
+func listHyperlinks(roots []RootConfig, output io.Writer) {
+
+	for _, root := range roots {
+		listHyperlinksInDirectory(root.Path, output)
+	}
 
-.info:hover p{
-opacity: 1;
-transition: opacity 500ms;
 }
 
-h1 {
-margin: 0px;
-padding: 0px;
-margin-bottom: 15px;
-font-size: 20px;
-border-bottom: 1px solid #ccc;
-font-weight: bold;
-padding-bottom: 10px;
+// print all hyperlinks found in the documents of the directory without checking them
+func listHyperlinksInDirectory(rootDirectory string, output io.Writer) {
 
-}
+	var fileChannel chan Document = make(chan Document)
 
-ul {
-margin: 0px;
-padding: 0px;
-list-style-type: none;
-padding-left: 5px;
-}
+	go walkDirectory(rootDirectory, fileChannel)
 
-ul li {
-position: relative;
-padding-left: 28px;
-}
+	for file := range fileChannel {
 
-ul.directories > li + li{
-margin-top: 20px;
-}
+		// print one line per hyperlink (tab separated, to be easily processed by other tools)
+		links, _ := extractHyperlinksFromDocument(&file)
 
-ul li:before {
-content: "";
-background-position: top left;
-background-repeat: no-repeat;
-display: block;
-position: absolute;
-left: 0px;
-top: 0px;
-width: 20px;
-height: 20px;
-}
+		for _, link := range links {
+			fmt.Fprintf(output, "%s\t%s\t%s\n", file.Path, redactUrl(link.Url), link.Tooltip)
+		}
 
-ul.directories {
-margin-bottom: 40px;
-}
+	}
 
-ul.directories li:before {
-background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAAj0lEQVQ4T2NkYGBgBGJ2IIaBv0DGbyQ+XiZI81Ig/gLE/6EqFYB0PRCfJMYQkAELgDgBSTEHkD2DCM0gvaewGUCEXriSdSADzgPxLSQvEGsASK8aiLgPxIrE6kJTdx9kwB0gViHTgDtUMeAo0HZrMl1wlNJYWDBqAAM4DNAzE7ERAtLLAyLQszOxBoDU/QQAylQgG9KLVSEAAAAASUVORK5CYII=);
 }
 
-ul.documents > li:before {
-background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABQAAAAUCAYAAACNiR0NAAABqElEQVQ4T52VPSiFURjH3VDyMRhEDK6Uj0wG+VgskhSDDJLMmCUD8p2S7RbJxKZMLGKgJBsGJKQsZCeUj9//dl699ziXk1O/3vs873P+5/8+57zvjaQkjhHCbCvnCs9JrrpuRKzkNXGfh+AKNUswZ9faghcUVHoInlHzAhswG64PBNNM8pBrgyX4Sfxu5STYCmuwA1PBfQnWmZVukzirIp/rEGwk9wRbcAyDqgkEe/i9D5dwB1lGoJqrNkqLhoccqt+vkAMtMA4TgWA3QTnMQA08wxWUQa9DUK4zrEXWiUsDwS4C9enIONrl+gGjsGcE1dtmS0ThpKmNb2hYUI/bDidQDxKdNovokaNQ4RDcJqeN+yGo5sagFh6NwH1IUJvQ5hAcSuZQ1uVAR0cTN81ktUEOi6DEIXhgcgkOl42Qoz7ehkJogk5HwYDtMJOEdlNjEfqtSerPKeQZl7ameq7x7TBcELx6+SQXXHYdOR0rnYhfBdMpKPYU1AH/02EBFfqa+IwOH4eppmc+gg8+Dn2E7BpnD2+oGv6PGnPmIWp/YH3/AlxrvpEc+wLSwV8VusxZtAAAAABJRU5ErkJggg==);
-top: -2px;
+// define the document type of all file extensions we validate
+var documentTypes = map[string]string{
+	".docx": ".docx",
+	".dotx": ".docx",
+	".pptx": ".pptx",
+	".potx": ".pptx",
+	".xlsx": ".xlsx",
+	".xltx": ".xlsx",
 }
 
-ul.documents > li + li {
-margin-top: 25px;
-}
+// walk recursively through the directory and send all documents found to the
+// file channel (which is closed when the walk is done)
+func walkDirectory(directory string, fileChannel chan Document) {
 
-div.result {
-border-width: 1px;
-border-style: solid;
-border-radius: 2px;
-padding: 10px;
-margin-bottom: 25px;
-}
+	// walk recursively through the directory
+	fileSystem.Walk(directory, func(path string, fileInfo os.FileInfo, err error) error {
 
-div.result.valid {
-border-color: #20d420;
-background-color: #dcffe7;
-}
+		// skip all files and directories we cannot access
+		if err != nil {
+			log.Println("ERROR: could not access " + path)
+			return nil
+		}
 
-div.result.invalid {
-border-color: #db2d2d;
-background-color: #fff5f5;
-}
+		var fileName string = fileInfo.Name()
 
-h2 {
-font-weight: bold;
-}
+		var extension string = strings.ToLower(filepath.Ext(fileName))
 
-ul.links li{
-font-size: 12px;
-}
+		// templates are read like the documents created from them
+		if documentType, isDocument := documentTypes[extension]; isDocument {
 
-ul.links > li + li {
-margin-top: 15px;
-}
+			// create a pointer to new document with the corresponding type and path
+			file := Document{Path: path, Type: documentType, IsTemplate: extension != documentType, Modified: fileInfo.ModTime()}
 
-ul.links li.invalid:before {
-background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABAAAAAQCAYAAAAf8/9hAAAA/UlEQVQ4T2NkoBAwUqifAa8B/xkYjIAW/AcqOo/LIpwGADVLAjVdBxkAxFpAhc+xGYLPgHVADYFQTeuACoOJNgBopT9Q8QY0Df5AQzahG4LhAqBmLqCiy0CsBMTToBqygPQ9INYBaviObAg2A3qACoqhihqhdD2U7gFqKMVpANB2Q6DkKSBmwWHAH6C4GXKswF0A1AxiHwJiGyQbJkPZuUhiR4BsO6BiUOwg0gGQB/LnVGwhjUUsC2jAdLgBQM0iQM5NIBZCU7wCakk4mvg7IF8daMgbsBeABiQAqflE2g5TFg/UvAhmAD9QFGQIB5GG/ABZCNT8ibaZiRjXAABQjy8Rw0RFZAAAAABJRU5ErkJggg==);
-top: -1px;
-}
+			// send the file to the channel
+			fileChannel <- file
 
-ul.links li.valid:before {
-background-image: url(data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAABQAAAAUCAYAAACNiR0NAAAA6UlEQVQ4T2NkoDJgpLJ5DKMGEhei/Ve1DRj/M/T///01sNDwwQdkXSSHIdSw/YyMDAL//zMcKNC56ki2gWiGffjL8NuhWOfWRbIMJMYwkMEYXoZo/J9foHMtEWZz/2UNB0ZG5vVQb2J1GUwthoETrmidZ2RkNPj3769joe6NA0ALEpgYGOaDNADDDK9hWF3Ye0VNn4WR9cJ/hv8P/v//P5GJkamfWMOwGggSnHhZM5+BiWkCzBvEuAynl2ESQK/PB3o9gRTDcLoQJNFxRomfg4NzAzBpFKAnDXzJn+SETSgvjRpIKIQIywMAWmd1FTm7YC8AAAAASUVORK5CYII=);
-top: -3px;
-}
+		}
 
-.valid {
-color: #20d420;
-}
+		// we are not expecting any errors (or not handling them at least)
+		return nil
+
+	})
+
+	// close our fileChannel (no longer needed)
+	close(fileChannel)
 
-.invalid {
-color: #db2d2d;
 }
 
+func getAbsoluteFilePath(path string) string {
+
+	// check if the path is already absolute
+	if filepath.IsAbs(path) {
+		// if so, return the path
+		return path
+	}
+
+	// try to find the absolute path
+	absolutePath, err := filepath.Abs(path)
 
+	// we return the original path, if there was an error
+	// otherwise we return the absolute path
+	if err != nil {
+		return path
+	} else {
+		return absolutePath
+	}
 
-</style>
-</head>
-<body>
-<div class="container">
-
-<h1>Directory searched</h1>
-
-<ul class="directories">
-{{range .Directories}}
-<li><a href="file:///{{absolutePath .}}">{{absolutePath .}}</a></li>
-{{end}}
-</ul>
-
-<h1>Result of link validation</h1>
-
-
-{{if .ResultOfValidation}}
-<div class="result valid">
-All files contain valid links
-</div>
-{{else}}
-<div class="result invalid">
-There are some files with invalid links
-</div>
-{{end}}
-
-<ul class="documents">
-{{range .Documents}}
-<li class="result">
-<h2 class="{{if .IsValid}}valid{{else}}invalid{{end}}"><a href="file:///{{absolutePath .Path}}">{{.Path}}</a></h2>
-
-<ul class="links">
-{{range .Hyperlinks}}
-<li class="result {{if .IsWorking}}valid{{else}}invalid{{end}}"><a href="{{.Url}}">{{.Url}}</a></li>
-{{end}}
-</ul>
-</li>
-{{end}}
-</ul>
-
-</div>
-
-<div class="info">
-<p class="time">Link validation conducted on {{.Date}}</p>
-<p class="author">&copy;&nbsp;2014, Department of Clinical Research, University Hospital Basel</p>
-</div>
-</body>
-</html>
-`
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// check the comparison of the responses from the primary and the secondary location
+func TestSecondaryLocationIssue(t *testing.T) {
+
+	page := &CheckResponse{StatusCode: 200, FinalUrl: "https://journal.example.com/article/1"}
+
+	tests := []struct {
+		name      string
+		primary   *CheckResponse
+		secondary *CheckResponse
+		err       error
+		isIssue   bool
+	}{
+		{"same response", page, &CheckResponse{StatusCode: 200, FinalUrl: "https://JOURNAL.example.com:443/article/1"}, nil, false},
+		{"unreachable", page, nil, errors.New("connection refused"), true},
+		{"unavailable for legal reasons", page, &CheckResponse{StatusCode: 451, FinalUrl: page.FinalUrl}, nil, true},
+		{"forbidden", page, &CheckResponse{StatusCode: 403, FinalUrl: page.FinalUrl}, nil, true},
+		{"redirected to a region page", page, &CheckResponse{StatusCode: 200, FinalUrl: "https://journal.example.com/not-available-in-your-region"}, nil, true},
+		{"failing everywhere", &CheckResponse{StatusCode: 404}, &CheckResponse{StatusCode: 404}, nil, false},
+	}
+
+	for _, test := range tests {
+		if issue := secondaryLocationIssue(test.primary, test.secondary, test.err); (issue != "") != test.isIssue {
+			t.Errorf("%s: secondaryLocationIssue = %q", test.name, issue)
+		}
+	}
+
+}
+
+// check that the hyperlinks of all roots are listed (and not only the ones of the
+// working directory)
+func TestListHyperlinks(t *testing.T) {
+
+	roots := []RootConfig{}
+
+	for _, name := range []string{"handbook", "sops"} {
+
+		directory := t.TempDir()
+
+		err := writeWordDocument(filepath.Join(directory, name+".docx"), []string{"https://example.com/" + name})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		roots = append(roots, RootConfig{Path: directory})
+
+	}
+
+	output := bytes.Buffer{}
+	listHyperlinks(roots, &output)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+
+	if len(lines) != 2 || strings.Contains(lines[0], "https://example.com/handbook") == false || strings.Contains(lines[1], "https://example.com/sops") == false {
+		t.Errorf("unexpected hyperlinks listed:\n%s", output.String())
+	}
+
+}
+
+// check that the status codes of the blocking rules are blocked responses even if
+// they would mean a missing page otherwise
+func TestBlockedStatusRules(t *testing.T) {
+
+	previousConfig := config
+	defer func() { config = previousConfig }()
+
+	tests := []struct {
+		name       string
+		rules      []BlockingRule
+		path       string
+		isWorking  bool
+		notChecked bool
+		reason     string
+	}{
+		{"without rule", nil, "/missing", false, false, reasonPageNotFound + " (status 404)"},
+		{"not found is unverifiable", []BlockingRule{{Domain: fakeHost, Status: []int{404}}}, "/missing", false, true, reasonBlockedByTarget},
+		{"not found is working", []BlockingRule{{Domain: fakeHost, Status: []int{404}, Result: blockedOk}}, "/missing", true, false, reasonBlockedOk},
+		{"gone is broken", []BlockingRule{{Domain: fakeHost, Status: []int{410}, Result: blockedBroken}}, "/gone", false, false, reasonBlockedBroken},
+		{"other status of the domain", []BlockingRule{{Domain: fakeHost, Status: []int{410}, Result: blockedOk}}, "/missing", false, false, reasonPageNotFound + " (status 404)"},
+		{"forbidden rule for a working link", []BlockingRule{{Domain: fakeHost, Status: []int{403}, Result: blockedBroken}}, "/ok", true, false, ""},
+	}
+
+	for _, test := range tests {
+
+		config.BlockedResponses = test.rules
+
+		for index := range config.BlockedResponses {
+			err := config.BlockedResponses[index].prepare()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		link := &Hyperlink{Url: "http://" + fakeHost + test.path, IsExternal: true}
+		link.validate("")
+
+		if link.IsWorking != test.isWorking || link.NotChecked != test.notChecked || link.Reason != test.reason {
+			t.Errorf("%s: working %t, not checked %t (%s)", test.name, link.IsWorking, link.NotChecked, link.Reason)
+		}
+
+	}
+
+}
@@ -0,0 +1,44 @@
+package main
+
+// define the interface of validators for special kinds of links, which replace
+// the plain http check for the links they recognize (i.e. identifier resolvers)
+type Validator interface {
+	Matches(link *Hyperlink) bool
+	Validate(link *Hyperlink)
+}
+
+// the validators that are asked (in order) before a link is checked with a plain request
+var validators []Validator
+
+// initialize the validators according to the options
+func initializeValidators() {
+
+	// the validation commands of the configuration take precedence over all others
+	validators = []Validator{
+		&commandValidator{},
+		&connectionValidator{scheme: "ftp", defaultPort: "21"},
+		&connectionValidator{scheme: "ldap", defaultPort: "389"},
+		&connectionValidator{scheme: "ldaps", defaultPort: "636"},
+		&phoneValidator{},
+		&mailValidator{},
+		&customSchemeValidator{},
+	}
+
+	if options.CheckIdentifiers {
+		validators = append(validators, &doiValidator{}, &pubmedValidator{}, &clinicalTrialsValidator{})
+	}
+
+}
+
+// find the validator responsible for the given link (nil if there is none)
+func findValidator(link *Hyperlink) Validator {
+
+	for _, validator := range validators {
+		if validator.Matches(link) {
+			return validator
+		}
+	}
+
+	return nil
+
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// define a custom structure for a remote worker the links matching the pattern are
+// checked by (i.e. a worker inside the dmz for links only reachable from there)
+type RemoteWorker struct {
+	Name    string `json:"name"`
+	Url     string `json:"url"`
+	Pattern string `json:"pattern"`
+	// the token the worker expects (may reference environment variables)
+	Token string `json:"token"`
+	// the network zone the worker checks the links from (i.e. dmz)
+	Zone string `json:"zone"`
+
+	matcher *regexp.Regexp
+}
+
+// the path of the checks of a worker, the version of the wire format (json over
+// http) is part of it, so a coordinator and a worker with incompatible formats
+// fail with 404 instead of misreading each other
+const workerCheckPath = "/v1/check"
+
+// define the request sent to a worker
+type WorkerRequest struct {
+	Url string
+}
+
+// define the result of the check of a worker
+type WorkerResult struct {
+	StatusCode int
+	Header     http.Header
+	FinalUrl   string
+	Error      string
+}
+
+// define a custom error for links that could not be checked by a worker
+type RemoteError struct {
+	Worker  string
+	Message string
+}
+
+// describe the error together with the worker reporting it
+func (err *RemoteError) Error() string {
+	return "checked by worker " + err.Worker + ": " + err.Message
+}
+
+// check the worker given in the configuration
+func (worker *RemoteWorker) prepare() error {
+
+	matcher, err := regexp.Compile(worker.Pattern)
+	if err != nil {
+		return errors.New("invalid worker pattern " + worker.Pattern)
+	}
+
+	if worker.Url == "" {
+		return errors.New("the worker " + worker.Name + " needs an url")
+	}
+
+	if worker.Name == "" {
+		worker.Name = worker.Url
+	}
+
+	worker.matcher = matcher
+
+	return nil
+
+}
+
+// find the worker responsible for the given url (nil if it is checked locally)
+func findRemoteWorker(url string) *RemoteWorker {
+
+	for index := range config.Workers {
+		if config.Workers[index].matcher.MatchString(url) {
+			return &config.Workers[index]
+		}
+	}
+
+	return nil
+
+}
+
+// ask the worker to check the given url
+func (worker *RemoteWorker) Check(url string) (*CheckResponse, error) {
+
+	data, err := json.Marshal(WorkerRequest{Url: url})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest("POST", strings.TrimSuffix(worker.Url, "/")+workerCheckPath, bytes.NewReader(data))
+	if err != nil {
+		return nil, &RemoteError{Worker: worker.Name, Message: "invalid worker url"}
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if worker.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+os.ExpandEnv(worker.Token))
+	}
+
+	// the worker needs some time in addition to the timeout of its own request
+	client := &http.Client{Timeout: options.Timeout + 10*time.Second}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, &RemoteError{Worker: worker.Name, Message: "worker unreachable"}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, &RemoteError{Worker: worker.Name, Message: "worker does not support the protocol " + workerCheckPath + " (different version)"}
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, &RemoteError{Worker: worker.Name, Message: "worker responded with " + response.Status}
+	}
+
+	var result WorkerResult
+
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, &RemoteError{Worker: worker.Name, Message: "invalid response of the worker"}
+	}
+
+	if result.Error != "" {
+		return nil, &RemoteError{Worker: worker.Name, Message: result.Error}
+	}
+
+	return &CheckResponse{StatusCode: result.StatusCode, Header: result.Header, FinalUrl: result.FinalUrl}, nil
+
+}
+
+// serve the checks of a coordinator (a regular run with workers in its
+// configuration), i.e. from a network zone the coordinator cannot reach
+func runWorker(arguments []string) {
+
+	flags := flag.NewFlagSet("worker", flag.ExitOnError)
+	address := flags.String("address", "127.0.0.1:8070", "address the worker listens on (a token is required for other than loopback addresses)")
+	token := flags.String("token", os.Getenv("VALIDATE_LINKS_WORKER_TOKEN"), "token the coordinator has to present (defaults to VALIDATE_LINKS_WORKER_TOKEN)")
+	configFile := flags.String("config", "", "configuration file (json) with the logins and client certificates of the worker")
+	flags.DurationVar(&options.Timeout, "timeout", 15*time.Second, "time to wait for the response of a hyperlink")
+	flags.StringVar(&options.Proxy, "proxy", "", "proxy all hyperlinks are checked through")
+	flags.BoolVar(&options.Head, "head", false, "check hyperlinks with head requests (falling back to get if a server does not support them)")
+	flags.IntVar(&options.MaxRedirects, "max-redirects", 10, "maximum number of redirects followed before a link is reported as broken")
+
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: validate-links worker [-address host:port] [-token token] [-config file] [options]")
+		flags.PrintDefaults()
+	}
+
+	flags.Parse(arguments)
+
+	// the worker checks the links with the logins and client certificates of the
+	// configuration, it must not be usable by anyone reaching it over the network
+	if *token == "" && isLoopbackAddress(*address) == false {
+		log.Fatalln("ERROR: the worker needs a token (-token or VALIDATE_LINKS_WORKER_TOKEN) to listen on " + *address)
+	}
+
+	initializeMatchers()
+
+	if *configFile != "" {
+		err := loadConfig(*configFile)
+		if err != nil {
+			log.Fatalln("ERROR: could not load the configuration:", err)
+		}
+	}
+
+	initializeSessions()
+
+	checker = &httpChecker{Timeout: options.Timeout, Proxy: options.Proxy}
+
+	if *token == "" {
+		log.Println("WARNING: the worker does not require a token, anyone on this machine can use it")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(workerCheckPath, func(w http.ResponseWriter, r *http.Request) {
+		handleWorkerCheck(w, r, *token)
+	})
+
+	log.Println("Worker is listening on " + *address)
+
+	err := http.ListenAndServe(*address, mux)
+	if err != nil {
+		log.Fatalln("ERROR: worker stopped:", err)
+	}
+
+}
+
+// check if the worker would only be reachable from the same machine when listening
+// on the given address (an empty host listens on all interfaces)
+func isLoopbackAddress(address string) bool {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+
+}
+
+// check the url requested by the coordinator
+func handleWorkerCheck(w http.ResponseWriter, r *http.Request, token string) {
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var request WorkerRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	// the worker only issues http requests
+	if isHttpScheme(linkScheme(request.Url)) == false {
+		http.Error(w, "Only http and https urls are checked", http.StatusBadRequest)
+		return
+	}
+
+	result := WorkerResult{}
+
+	response, err := checker.Check(request.Url)
+
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.StatusCode = response.StatusCode
+		result.Header = response.Header
+		result.FinalUrl = response.FinalUrl
+	}
+
+	writeJson(w, result)
+
+}
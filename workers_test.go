@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// check which addresses of the worker are only reachable from the same machine
+func TestIsLoopbackAddress(t *testing.T) {
+
+	tests := []struct {
+		address    string
+		isLoopback bool
+	}{
+		{"127.0.0.1:8070", true},
+		{"localhost:8070", true},
+		{"[::1]:8070", true},
+		{":8070", false},
+		{"0.0.0.0:8070", false},
+		{"10.0.0.5:8070", false},
+		{"dmz-worker:8070", false},
+		{"8070", false},
+	}
+
+	for _, test := range tests {
+		if isLoopback := isLoopbackAddress(test.address); isLoopback != test.isLoopback {
+			t.Errorf("isLoopbackAddress(%q) = %t", test.address, isLoopback)
+		}
+	}
+
+}
+
+// check that the worker only answers the coordinator presenting the token
+func TestWorkerToken(t *testing.T) {
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"without token", "", http.StatusUnauthorized},
+		{"other token", "Bearer other", http.StatusUnauthorized},
+		{"prefix of the token", "Bearer sec", http.StatusUnauthorized},
+		// the worker only checks http urls, so the request fails after the token
+		{"token", "Bearer secret", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+
+		request := httptest.NewRequest("POST", workerCheckPath, strings.NewReader(`{"Url": "file:///etc/passwd"}`))
+		if test.authorization != "" {
+			request.Header.Set("Authorization", test.authorization)
+		}
+
+		recorder := httptest.NewRecorder()
+		handleWorkerCheck(recorder, request, "secret")
+
+		if recorder.Code != test.status {
+			t.Errorf("%s: status %d instead of %d", test.name, recorder.Code, test.status)
+		}
+
+	}
+
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// define a custom structure tagging the links matching the pattern with the
+// network zones they should be checked from (i.e. intranet and public)
+type ZonePolicy struct {
+	Pattern string   `json:"pattern"`
+	Zones   []string `json:"zones"`
+
+	matcher *regexp.Regexp
+}
+
+// define a custom structure for the result of a link checked from a zone
+type ZoneResult struct {
+	Zone      string
+	IsChecked bool
+	IsWorking bool
+	Reason    string
+}
+
+// define a custom structure for a link checked from several zones (as listed in
+// the report)
+type ZoneLink struct {
+	Document string
+	Link     Hyperlink
+}
+
+// check the zone policy given in the configuration
+func (policy *ZonePolicy) prepare() error {
+
+	matcher, err := regexp.Compile(policy.Pattern)
+	if err != nil {
+		return errors.New("invalid zone pattern " + policy.Pattern)
+	}
+
+	if len(policy.Zones) == 0 {
+		return errors.New("the zone pattern " + policy.Pattern + " needs at least one zone")
+	}
+
+	policy.matcher = matcher
+
+	return nil
+
+}
+
+// find the zone policy of the given url (nil if it is only checked once)
+func findZonePolicy(url string) *ZonePolicy {
+
+	for index := range config.Zones {
+		if config.Zones[index].matcher.MatchString(url) {
+			return &config.Zones[index]
+		}
+	}
+
+	return nil
+
+}
+
+// find the checker of the given zone, the run itself checks the links of its own
+// zone and the workers those of theirs (nil if no one can check from the zone)
+func zoneChecker(zone string) Checker {
+
+	if zone == options.Zone {
+		return checker
+	}
+
+	for index := range config.Workers {
+		if config.Workers[index].Zone == zone {
+			return &config.Workers[index]
+		}
+	}
+
+	return nil
+
+}
+
+// check the link from all zones of the policy (with the checkers of the zones),
+// the link works if it can be reached from any of them (the response returned is
+// the first working one, or the first one if the page is gone in all zones)
+func (link *Hyperlink) checkZones(policy *ZonePolicy, checkerOf func(zone string) Checker) (*CheckResponse, error) {
+
+	var response *CheckResponse
+	var failedResponse *CheckResponse
+	var lastErr error = errors.New("no zone could check the link")
+
+	// a second pass replaces the results of the first one
+	link.Zones = []ZoneResult{}
+
+	for _, zone := range policy.Zones {
+
+		zoneChecker := checkerOf(zone)
+
+		if zoneChecker == nil {
+			link.Zones = append(link.Zones, ZoneResult{Zone: zone, Reason: "no worker in this zone"})
+			continue
+		}
+
+		zoneResponse, err := zoneChecker.Check(link.RequestUrl)
+
+		// the zone is judged like a link checked from a single zone
+		result := ZoneResult{Zone: zone, IsChecked: true}
+
+		switch {
+		case err != nil:
+			result.Reason = err.Error()
+			lastErr = err
+		case isPageGone(zoneResponse):
+			result.Reason = fmt.Sprintf("status %d", zoneResponse.StatusCode)
+			if failedResponse == nil {
+				failedResponse = zoneResponse
+			}
+		default:
+			result.IsWorking = true
+			if response == nil {
+				response = zoneResponse
+			}
+		}
+
+		link.Zones = append(link.Zones, result)
+
+	}
+
+	if response != nil {
+		return response, nil
+	}
+
+	// the page is reported as gone if no zone could reach it
+	if failedResponse != nil {
+		return failedResponse, nil
+	}
+
+	return nil, lastErr
+
+}
+
+// define a custom checker requesting the urls from all zones of a policy (i.e.
+// the replacements suggested for a link tagged with zones)
+type zonesChecker struct {
+	policy *ZonePolicy
+}
+
+// check the url from all zones, it works if any of them can reach it
+func (checker *zonesChecker) Check(url string) (*CheckResponse, error) {
+
+	link := &Hyperlink{RequestUrl: url}
+
+	return link.checkZones(checker.policy, zoneChecker)
+
+}
+
+// get the status of the link from the given zone (as shown in the report)
+func (link *Hyperlink) ZoneStatus(zone string) string {
+
+	for _, result := range link.Zones {
+
+		if result.Zone != zone {
+			continue
+		}
+
+		if result.IsChecked == false {
+			return "not checked (" + result.Reason + ")"
+		}
+
+		if result.IsWorking {
+			return "reachable"
+		}
+
+		return "unreachable (" + result.Reason + ")"
+
+	}
+
+	return ""
+
+}
+
+// describe from which zones the link can be reached (zones without a worker
+// are not taken into account)
+func (link *Hyperlink) Reachability() string {
+
+	checked := 0
+	reachable := []string{}
+
+	for _, result := range link.Zones {
+
+		if result.IsChecked {
+			checked++
+		}
+
+		if result.IsWorking {
+			reachable = append(reachable, result.Zone)
+		}
+
+	}
+
+	switch {
+	case len(reachable) == 0:
+		return "unreachable"
+	case len(reachable) == checked:
+		return "reachable from all zones"
+	}
+
+	return "reachable from " + strings.Join(reachable, " and ") + " only"
+
+}
+
+// get the names of all zones the links of the report were checked from
+func (report *Report) ZoneNames() []string {
+
+	zones := map[string]bool{}
+	names := []string{}
+
+	for _, zoneLink := range report.ZoneLinks() {
+		for _, result := range zoneLink.Link.Zones {
+			if zones[result.Zone] == false {
+				zones[result.Zone] = true
+				names = append(names, result.Zone)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+
+}
+
+// get all links of the report checked from several zones
+func (report *Report) ZoneLinks() []ZoneLink {
+
+	links := []ZoneLink{}
+
+	for _, document := range report.Documents {
+		for _, link := range document.Hyperlinks {
+			if len(link.Zones) > 0 {
+				links = append(links, ZoneLink{Document: document.Path, Link: link})
+			}
+		}
+	}
+
+	return links
+
+}
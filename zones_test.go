@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// define a checker answering every url with the same response (or error)
+type fixedChecker struct {
+	response *CheckResponse
+	err      error
+}
+
+func (checker *fixedChecker) Check(url string) (*CheckResponse, error) {
+	return checker.response, checker.err
+}
+
+// check the results of a link checked from the intranet and the public zone
+func TestCheckZones(t *testing.T) {
+
+	working := &fixedChecker{response: &CheckResponse{StatusCode: 200}}
+	missing := &fixedChecker{response: &CheckResponse{StatusCode: 404}}
+	unreachable := &fixedChecker{err: errors.New("connection refused")}
+
+	policy := &ZonePolicy{Pattern: ".", Zones: []string{"intranet", "public"}}
+
+	tests := []struct {
+		name         string
+		intranet     Checker
+		public       Checker
+		status       int
+		isError      bool
+		reachability string
+	}{
+		{"working everywhere", working, working, 200, false, "reachable from all zones"},
+		{"missing in the intranet", missing, working, 200, false, "reachable from public only"},
+		{"unreachable from the intranet", unreachable, working, 200, false, "reachable from public only"},
+		{"public page missing", working, missing, 200, false, "reachable from intranet only"},
+		{"missing everywhere", missing, missing, 404, false, "unreachable"},
+		{"missing and unreachable", unreachable, missing, 404, false, "unreachable"},
+		{"unreachable everywhere", unreachable, unreachable, 0, true, "unreachable"},
+		{"without public worker", working, nil, 200, false, "reachable from all zones"},
+	}
+
+	for _, test := range tests {
+
+		checkers := map[string]Checker{"intranet": test.intranet}
+		if test.public != nil {
+			checkers["public"] = test.public
+		}
+
+		link := Hyperlink{Url: "https://intranet.example.com/sop", RequestUrl: "https://intranet.example.com/sop"}
+		response, err := link.checkZones(policy, func(zone string) Checker { return checkers[zone] })
+
+		if (err != nil) != test.isError || (response != nil && response.StatusCode != test.status) {
+			t.Errorf("%s: checkZones = %+v, %v", test.name, response, err)
+		}
+
+		if reachability := link.Reachability(); reachability != test.reachability {
+			t.Errorf("%s: %q instead of %q", test.name, reachability, test.reachability)
+		}
+
+	}
+
+}